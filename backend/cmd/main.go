@@ -1,23 +1,41 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	_ "time/tzdata" // shop time zones must resolve on hosts without zoneinfo
 
 	"coffee-shop-platform/internal/config"
 	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/handlers"
 	"coffee-shop-platform/internal/routes"
+	"coffee-shop-platform/internal/storage"
+	"coffee-shop-platform/internal/validation"
 	"coffee-shop-platform/scripts"
 
 	"github.com/labstack/echo/v4"
 )
 
 func main() {
-	migratePtr := flag.Bool("migrate", false, "Run database migrations and exit")
+	var migrateMode migrateFlag
+	flag.Var(&migrateMode, "migrate", "Run database migrations and exit: up (default), down, status or to=N")
 	seedPtr := flag.Bool("seed", false, "Seed database with sample data and exit")
+	cleanupPtr := flag.Bool("cleanup-uploads", false, "Delete uploaded files nothing refers to and exit")
+	regeneratePtr := flag.Bool("regenerate-images", false, "Recreate the resized variants of uploaded images and exit")
 	flag.Parse()
 
+	// Also accept the mode as a separate argument: -migrate status
+	if migrateMode == "true" && flag.NArg() > 0 {
+		migrateMode = migrateFlag(flag.Arg(0))
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
@@ -28,12 +46,14 @@ func main() {
 	}
 	defer database.Close()
 
-	if *migratePtr {
-		fmt.Println("Running database migrations...")
-		if err := database.Migrate(); err != nil {
+	if err := storage.Init(&cfg.Storage); err != nil {
+		log.Fatalf("Failed to set up storage: %v", err)
+	}
+
+	if migrateMode != "" {
+		if err := runMigrations(string(migrateMode)); err != nil {
 			log.Fatalf("Migration failed: %v", err)
 		}
-		fmt.Println("Migrations completed successfully!")
 		return
 	}
 
@@ -49,10 +69,34 @@ func main() {
 		return
 	}
 
+	if *cleanupPtr {
+		// Leave a day for uploads that are about to be saved on a menu item
+		deleted, err := handlers.CleanupOrphanedUploads(context.Background(), 24*time.Hour)
+		if err != nil {
+			log.Fatalf("Cleanup failed: %v", err)
+		}
+		fmt.Printf("Deleted %d orphaned uploads\n", deleted)
+		return
+	}
+
+	if *regeneratePtr {
+		regenerated, err := handlers.RegenerateImageVariants(context.Background())
+		if err != nil {
+			log.Fatalf("Regenerating images failed: %v", err)
+		}
+		fmt.Printf("Regenerated variants of %d images\n", regenerated)
+		return
+	}
+
 	e := echo.New()
 
-	// Store config in context
-	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+	// Every c.Bind also checks the request's validate tags
+	validator := validation.New()
+	e.Validator = validator
+	e.Binder = validation.NewBinder(validator)
+
+	// Store config in context, before routing so Pre middleware can read it too
+	e.Pre(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			c.Set("config", cfg)
 			c.Set("db", database.DB)
@@ -62,6 +106,66 @@ func main() {
 
 	routes.SetupRoutes(e)
 
+	// Serve uploads from disk when they are not in a bucket
+	if local, ok := storage.Files.(*storage.Local); ok && strings.HasPrefix(cfg.Storage.PublicURL, "/") {
+		e.Static(cfg.Storage.PublicURL, local.Dir())
+	}
+
+	// Apply scheduled price changes in the background
+	go handlers.RunPriceScheduler(context.Background(), time.Minute)
+
 	log.Printf("Server starting on %s:%s", cfg.Server.Host, cfg.Server.Port)
 	e.Logger.Fatal(e.Start(fmt.Sprintf(":%s", cfg.Server.Port)))
 }
+
+// migrateFlag is a string flag that may also be given without a value (-migrate)
+type migrateFlag string
+
+func (f *migrateFlag) String() string     { return string(*f) }
+func (f *migrateFlag) Set(v string) error { *f = migrateFlag(v); return nil }
+func (f *migrateFlag) IsBoolFlag() bool   { return true }
+
+func runMigrations(mode string) error {
+	switch {
+	case mode == "true" || mode == "up":
+		fmt.Println("Running database migrations...")
+		if err := database.MigrateUp(); err != nil {
+			return err
+		}
+		fmt.Println("Migrations completed successfully!")
+	case mode == "down":
+		fmt.Println("Rolling back the last migration...")
+		if err := database.MigrateDown(); err != nil {
+			return err
+		}
+		fmt.Println("Rollback completed successfully!")
+	case mode == "status":
+		statuses, err := database.Status()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	case strings.HasPrefix(mode, "to="):
+		version, err := strconv.Atoi(strings.TrimPrefix(mode, "to="))
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid target version %q", mode)
+		}
+		fmt.Printf("Migrating to version %d...\n", version)
+		if err := database.MigrateTo(version); err != nil {
+			return err
+		}
+		fmt.Println("Migrations completed successfully!")
+	default:
+		return fmt.Errorf("unknown migrate mode %q, expected up, down, status or to=N", mode)
+	}
+	return nil
+}
@@ -3,12 +3,15 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
 	Server   ServerConfig   `json:"server"`
 	Database DatabaseConfig `json:"database"`
 	JWT      JWTConfig      `json:"jwt"`
+	Tenant   TenantConfig   `json:"tenant"`
+	Storage  StorageConfig  `json:"storage"`
 }
 
 type ServerConfig struct {
@@ -25,8 +28,44 @@ type DatabaseConfig struct {
 }
 
 type JWTConfig struct {
-	Secret      string `json:"secret"`
-	ExpireHours int    `json:"expire_hours"`
+	Secret              string `json:"secret"`
+	AccessExpireMinutes int    `json:"access_expire_minutes"`
+	RefreshExpireDays   int    `json:"refresh_expire_days"`
+}
+
+type TenantConfig struct {
+	// BaseDomain limits subdomain resolution to hosts under it; empty accepts any host
+	BaseDomain string `json:"base_domain"`
+	// Strict rejects requests to tenant routes with 404 when no tenant matches
+	Strict bool `json:"strict"`
+	// TrustedProxies are the CIDRs allowed to choose the tenant with the X-Tenant header
+	TrustedProxies []string `json:"trusted_proxies"`
+	// SharedHosts are hosts serving all tenants, such as staging, where a /t/:subdomain
+	// path prefix chooses the tenant. The base domain itself is always shared.
+	SharedHosts []string `json:"shared_hosts"`
+}
+
+type StorageConfig struct {
+	// Driver is local or s3
+	Driver string `json:"driver"`
+	// LocalDir is where the local driver keeps files; they are served under PublicURL
+	LocalDir  string   `json:"local_dir"`
+	PublicURL string   `json:"public_url"`
+	S3        S3Config `json:"s3"`
+	// MaxUploadBytes limits the size of uploaded files
+	MaxUploadBytes int64 `json:"max_upload_bytes"`
+}
+
+type S3Config struct {
+	Endpoint  string `json:"endpoint"`
+	Region    string `json:"region"`
+	Bucket    string `json:"bucket"`
+	AccessKey string `json:"-"`
+	SecretKey string `json:"-"`
+	// PathStyle puts the bucket in the path instead of the host, as MinIO expects
+	PathStyle bool `json:"path_style"`
+	// PublicURL is where objects are served from, e.g. a CDN; defaults to the bucket URL
+	PublicURL string `json:"public_url"`
 }
 
 func Load() (*Config, error) {
@@ -43,8 +82,30 @@ func Load() (*Config, error) {
 			Name:     getEnv("DB_NAME", "coffee_shop"),
 		},
 		JWT: JWTConfig{
-			Secret:      getEnv("JWT_SECRET", "your-secret-key"),
-			ExpireHours: getEnvAsInt("JWT_EXPIRE_HOURS", 24),
+			Secret:              getEnv("JWT_SECRET", "your-secret-key"),
+			AccessExpireMinutes: getEnvAsInt("JWT_ACCESS_EXPIRE_MINUTES", 15),
+			RefreshExpireDays:   getEnvAsInt("JWT_REFRESH_EXPIRE_DAYS", 30),
+		},
+		Tenant: TenantConfig{
+			BaseDomain:     strings.ToLower(getEnv("TENANT_BASE_DOMAIN", "")),
+			Strict:         getEnvAsBool("TENANT_STRICT", false),
+			TrustedProxies: getEnvAsList("TENANT_TRUSTED_PROXIES"),
+			SharedHosts:    getEnvAsList("TENANT_SHARED_HOSTS"),
+		},
+		Storage: StorageConfig{
+			Driver:    getEnv("STORAGE_DRIVER", "local"),
+			LocalDir:  getEnv("STORAGE_LOCAL_DIR", "./uploads"),
+			PublicURL: getEnv("STORAGE_PUBLIC_URL", "/uploads"),
+			S3: S3Config{
+				Endpoint:  getEnv("S3_ENDPOINT", ""),
+				Region:    getEnv("S3_REGION", "us-east-1"),
+				Bucket:    getEnv("S3_BUCKET", ""),
+				AccessKey: getEnv("S3_ACCESS_KEY", ""),
+				SecretKey: getEnv("S3_SECRET_KEY", ""),
+				PathStyle: getEnvAsBool("S3_PATH_STYLE", true),
+				PublicURL: getEnv("S3_PUBLIC_URL", ""),
+			},
+			MaxUploadBytes: int64(getEnvAsInt("UPLOAD_MAX_BYTES", 5<<20)),
 		},
 	}, nil
 }
@@ -64,3 +125,23 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsList splits a comma-separated variable, skipping empty entries
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,13 @@
+package database
+
+// goMigrations are the Go-coded migrations, for data changes SQL can't express. SQL
+// migrations live in migrations/ as <version>_<name>.up.sql and
+// <version>_<name>.down.sql; both share one version sequence. Never edit a migration
+// once it has shipped, add a new one instead. Migrations only use SQL and their own
+// types, never the models, so later model changes can't change what they do.
+var goMigrations []Migration
+
+// Migrate applies all pending migrations
+func Migrate() error {
+	return MigrateUp()
+}
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS order_line_options CASCADE;
+DROP TABLE IF EXISTS order_lines CASCADE;
+DROP TABLE IF EXISTS orders CASCADE;
+DROP TABLE IF EXISTS menu_options CASCADE;
+DROP TABLE IF EXISTS menu_option_groups CASCADE;
+DROP TABLE IF EXISTS menu_item_price_tiers CASCADE;
+DROP TABLE IF EXISTS menu_items CASCADE;
+DROP TABLE IF EXISTS category_overrides CASCADE;
+DROP TABLE IF EXISTS categories CASCADE;
+DROP TABLE IF EXISTS shop_admins CASCADE;
+DROP TABLE IF EXISTS coffee_shops CASCADE;
+DROP TABLE IF EXISTS tenants CASCADE;
+DROP TABLE IF EXISTS main_admins CASCADE;
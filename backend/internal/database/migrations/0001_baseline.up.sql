@@ -0,0 +1,211 @@
+-- Schema as AutoMigrate created it before versioned migrations. Databases that
+-- already have it only get what they are missing.
+CREATE TABLE IF NOT EXISTS main_admins (
+    id bigserial,
+    username text NOT NULL,
+    password_hash text NOT NULL,
+    is_active boolean DEFAULT true,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_main_admins_username ON main_admins (username);
+CREATE INDEX IF NOT EXISTS idx_main_admins_deleted_at ON main_admins (deleted_at);
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id bigserial,
+    subdomain text NOT NULL,
+    name text NOT NULL,
+    is_active boolean DEFAULT true,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_tenants_deleted_at ON tenants (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_subdomain ON tenants (subdomain);
+
+CREATE TABLE IF NOT EXISTS coffee_shops (
+    id bigserial,
+    tenant_id bigint NOT NULL,
+    name text NOT NULL,
+    location text,
+    phone text,
+    instagram_url text,
+    logo_url text,
+    hero_image_url text,
+    description text,
+    is_active boolean DEFAULT true,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_tenants_coffee_shops FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+CREATE INDEX IF NOT EXISTS idx_coffee_shops_deleted_at ON coffee_shops (deleted_at);
+
+CREATE TABLE IF NOT EXISTS shop_admins (
+    id bigserial,
+    coffee_shop_id bigint NOT NULL,
+    username text NOT NULL,
+    password_hash text NOT NULL,
+    is_active boolean DEFAULT true,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_coffee_shops_admins FOREIGN KEY (coffee_shop_id) REFERENCES coffee_shops (id)
+);
+CREATE INDEX IF NOT EXISTS idx_shop_admins_deleted_at ON shop_admins (deleted_at);
+
+CREATE TABLE IF NOT EXISTS categories (
+    id bigserial,
+    coffee_shop_id bigint,
+    name text NOT NULL,
+    display_name text NOT NULL,
+    emoji text,
+    color text,
+    order_index bigint DEFAULT 0,
+    is_active boolean DEFAULT true,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at);
+-- Categories created before shop categories existed lack coffee_shop_id
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS coffee_shop_id bigint;
+CREATE INDEX IF NOT EXISTS idx_categories_coffee_shop_id ON categories (coffee_shop_id);
+
+CREATE TABLE IF NOT EXISTS category_overrides (
+    id bigserial,
+    coffee_shop_id bigint NOT NULL,
+    category_id bigint NOT NULL,
+    display_name text,
+    emoji text,
+    color text,
+    order_index bigint,
+    is_active boolean,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_category_overrides_shop_category ON category_overrides (coffee_shop_id, category_id);
+
+CREATE TABLE IF NOT EXISTS menu_items (
+    id bigserial,
+    coffee_shop_id bigint NOT NULL,
+    category_id bigint NOT NULL,
+    name text NOT NULL,
+    price bigint NOT NULL,
+    price_premium bigint,
+    has_dual_pricing boolean DEFAULT false,
+    image_url text,
+    order_index bigint DEFAULT 0,
+    is_available boolean DEFAULT true,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_coffee_shops_menu_items FOREIGN KEY (coffee_shop_id) REFERENCES coffee_shops (id),
+    CONSTRAINT fk_categories_menu_items FOREIGN KEY (category_id) REFERENCES categories (id)
+);
+CREATE INDEX IF NOT EXISTS idx_menu_items_deleted_at ON menu_items (deleted_at);
+
+CREATE TABLE IF NOT EXISTS menu_item_price_tiers (
+    id bigserial,
+    menu_item_id bigint NOT NULL,
+    label text NOT NULL,
+    price bigint NOT NULL,
+    order_index bigint DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_menu_items_price_tiers FOREIGN KEY (menu_item_id) REFERENCES menu_items (id)
+);
+CREATE INDEX IF NOT EXISTS idx_menu_item_price_tiers_deleted_at ON menu_item_price_tiers (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_menu_item_price_tiers_menu_item_id ON menu_item_price_tiers (menu_item_id);
+
+CREATE TABLE IF NOT EXISTS menu_option_groups (
+    id bigserial,
+    menu_item_id bigint NOT NULL,
+    name text NOT NULL,
+    min_select bigint DEFAULT 0,
+    max_select bigint DEFAULT 1,
+    order_index bigint DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_menu_items_option_groups FOREIGN KEY (menu_item_id) REFERENCES menu_items (id)
+);
+CREATE INDEX IF NOT EXISTS idx_menu_option_groups_deleted_at ON menu_option_groups (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_menu_option_groups_menu_item_id ON menu_option_groups (menu_item_id);
+
+CREATE TABLE IF NOT EXISTS menu_options (
+    id bigserial,
+    option_group_id bigint NOT NULL,
+    name text NOT NULL,
+    price_delta bigint DEFAULT 0,
+    is_default boolean DEFAULT false,
+    is_available boolean DEFAULT true,
+    order_index bigint DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_menu_option_groups_options FOREIGN KEY (option_group_id) REFERENCES menu_option_groups (id)
+);
+CREATE INDEX IF NOT EXISTS idx_menu_options_deleted_at ON menu_options (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_menu_options_option_group_id ON menu_options (option_group_id);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id bigserial,
+    coffee_shop_id bigint NOT NULL,
+    status text NOT NULL DEFAULT 'received',
+    customer_name text,
+    table_number text,
+    note text,
+    total bigint NOT NULL,
+    status_changed_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_orders_coffee_shop FOREIGN KEY (coffee_shop_id) REFERENCES coffee_shops (id)
+);
+CREATE INDEX IF NOT EXISTS idx_orders_deleted_at ON orders (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_orders_status ON orders (status);
+CREATE INDEX IF NOT EXISTS idx_orders_coffee_shop_id ON orders (coffee_shop_id);
+
+CREATE TABLE IF NOT EXISTS order_lines (
+    id bigserial,
+    order_id bigint NOT NULL,
+    menu_item_id bigint NOT NULL,
+    price_tier_id bigint,
+    name text NOT NULL,
+    tier_label text,
+    unit_price bigint NOT NULL,
+    quantity bigint NOT NULL,
+    line_total bigint NOT NULL,
+    note text,
+    created_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_order_lines_menu_item FOREIGN KEY (menu_item_id) REFERENCES menu_items (id),
+    CONSTRAINT fk_orders_lines FOREIGN KEY (order_id) REFERENCES orders (id)
+);
+CREATE INDEX IF NOT EXISTS idx_order_lines_order_id ON order_lines (order_id);
+
+CREATE TABLE IF NOT EXISTS order_line_options (
+    id bigserial,
+    order_line_id bigint NOT NULL,
+    menu_option_id bigint NOT NULL,
+    group_name text,
+    name text NOT NULL,
+    price_delta bigint,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_order_lines_options FOREIGN KEY (order_line_id) REFERENCES order_lines (id)
+);
+CREATE INDEX IF NOT EXISTS idx_order_line_options_order_line_id ON order_line_options (order_line_id);
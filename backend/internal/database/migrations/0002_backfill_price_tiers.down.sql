@@ -0,0 +1,3 @@
+-- The backfilled tiers repeat each item's price and price_premium, which version 1
+-- reads the same way, so they are kept. Applying version 2 again skips them.
+SELECT 1;
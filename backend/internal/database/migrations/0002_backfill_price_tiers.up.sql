@@ -0,0 +1,16 @@
+-- Turn the price/price_premium pair of menu items without price tiers into a
+-- Standard tier and, for dual-priced items, a Premium tier
+WITH untiered AS (
+    SELECT m.id, m.price, m.price_premium, m.has_dual_pricing
+    FROM menu_items AS m
+    WHERE m.deleted_at IS NULL
+      AND NOT EXISTS (
+        SELECT 1 FROM menu_item_price_tiers AS t
+        WHERE t.menu_item_id = m.id AND t.deleted_at IS NULL
+      )
+)
+INSERT INTO menu_item_price_tiers (menu_item_id, label, price, order_index, created_at, updated_at)
+SELECT id, 'Standard', price, 0, now(), now() FROM untiered
+UNION ALL
+SELECT id, 'Premium', price_premium, 1, now(), now() FROM untiered
+WHERE has_dual_pricing AND price_premium IS NOT NULL;
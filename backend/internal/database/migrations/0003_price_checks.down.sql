@@ -0,0 +1,5 @@
+ALTER TABLE menu_item_price_tiers
+    DROP CONSTRAINT IF EXISTS chk_menu_item_price_tiers_price_non_negative;
+
+ALTER TABLE menu_items
+    DROP CONSTRAINT IF EXISTS chk_menu_items_price_non_negative;
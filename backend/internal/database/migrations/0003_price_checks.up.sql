@@ -0,0 +1,6 @@
+-- Prices can never be negative
+ALTER TABLE menu_items
+    ADD CONSTRAINT chk_menu_items_price_non_negative CHECK (price >= 0);
+
+ALTER TABLE menu_item_price_tiers
+    ADD CONSTRAINT chk_menu_item_price_tiers_price_non_negative CHECK (price >= 0);
@@ -0,0 +1,23 @@
+CREATE TABLE token_families (
+    id varchar(32),
+    user_id bigint NOT NULL,
+    user_type text NOT NULL,
+    revoked_at timestamptz,
+    revoked_reason text,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_token_families_user ON token_families (user_id, user_type);
+
+CREATE TABLE refresh_tokens (
+    id bigserial,
+    family_id varchar(32) NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_refresh_tokens_family FOREIGN KEY (family_id) REFERENCES token_families (id)
+);
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE UNIQUE INDEX idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
@@ -0,0 +1,3 @@
+ALTER TABLE shop_admins DROP COLUMN role_id;
+DROP TABLE IF EXISTS role_permissions CASCADE;
+DROP TABLE IF EXISTS roles CASCADE;
@@ -0,0 +1,67 @@
+CREATE TABLE roles (
+    id bigserial,
+    coffee_shop_id bigint,
+    name text NOT NULL,
+    display_name text,
+    is_system boolean DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_roles_deleted_at ON roles (deleted_at);
+CREATE INDEX idx_roles_coffee_shop_id ON roles (coffee_shop_id);
+
+CREATE TABLE role_permissions (
+    id bigserial,
+    role_id bigint NOT NULL,
+    permission text NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_roles_permissions FOREIGN KEY (role_id) REFERENCES roles (id)
+);
+CREATE UNIQUE INDEX idx_role_permissions_role_permission ON role_permissions (role_id, permission);
+
+ALTER TABLE shop_admins ADD COLUMN role_id bigint;
+ALTER TABLE shop_admins
+    ADD CONSTRAINT fk_shop_admins_role FOREIGN KEY (role_id) REFERENCES roles (id);
+
+-- The built-in roles and their permissions
+INSERT INTO roles (name, display_name, is_system, created_at, updated_at) VALUES
+    ('owner', 'Owner', true, now(), now()),
+    ('manager', 'Manager', true, now(), now()),
+    ('barista', 'Barista', true, now(), now()),
+    ('viewer', 'Viewer', true, now(), now());
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles AS r
+JOIN (VALUES
+    ('owner', 'menu.view'),
+    ('owner', 'menu.availability'),
+    ('owner', 'menu.edit'),
+    ('owner', 'menu.prices'),
+    ('owner', 'categories.manage'),
+    ('owner', 'orders.view'),
+    ('owner', 'orders.manage'),
+    ('owner', 'shop.settings'),
+    ('owner', 'users.manage'),
+    ('manager', 'menu.view'),
+    ('manager', 'menu.availability'),
+    ('manager', 'menu.edit'),
+    ('manager', 'menu.prices'),
+    ('manager', 'categories.manage'),
+    ('manager', 'orders.view'),
+    ('manager', 'orders.manage'),
+    ('barista', 'menu.view'),
+    ('barista', 'menu.availability'),
+    ('barista', 'orders.view'),
+    ('barista', 'orders.manage'),
+    ('viewer', 'menu.view'),
+    ('viewer', 'orders.view')
+) AS p (role, permission) ON p.role = r.name
+WHERE r.is_system;
+
+-- Every existing shop admin becomes an owner
+UPDATE shop_admins
+SET role_id = (SELECT id FROM roles WHERE is_system AND name = 'owner')
+WHERE role_id IS NULL;
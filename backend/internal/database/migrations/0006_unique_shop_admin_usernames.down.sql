@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_shop_admins_username;
@@ -0,0 +1,15 @@
+-- Logins matched the first of several shop admins with the same username, so the
+-- later duplicates could never sign in. Rename them before enforcing uniqueness.
+UPDATE shop_admins AS a
+SET username = a.username || '-' || a.id
+WHERE a.deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1 FROM shop_admins AS b
+    WHERE b.username = a.username
+      AND b.deleted_at IS NULL
+      AND b.id < a.id
+  );
+
+-- Deleted admins free their username
+CREATE UNIQUE INDEX idx_shop_admins_username ON shop_admins (username)
+    WHERE deleted_at IS NULL;
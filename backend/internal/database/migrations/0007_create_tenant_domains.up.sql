@@ -0,0 +1,13 @@
+CREATE TABLE tenant_domains (
+    id bigserial,
+    tenant_id bigint NOT NULL,
+    domain text NOT NULL,
+    verification_token text NOT NULL,
+    verified_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_tenant_domains_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+CREATE UNIQUE INDEX idx_tenant_domains_domain ON tenant_domains (domain);
+CREATE INDEX idx_tenant_domains_tenant_id ON tenant_domains (tenant_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_coffee_shops_tenant_slug;
+ALTER TABLE coffee_shops DROP COLUMN IF EXISTS slug;
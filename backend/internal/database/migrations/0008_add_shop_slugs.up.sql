@@ -0,0 +1,30 @@
+-- Give every shop a slug derived from its name, unique within its tenant: lowercase
+-- ASCII letters and digits separated by single hyphens, at most 60 characters.
+-- Shops whose names have none of those get shop-<id>; repeated slugs get -2, -3, …
+ALTER TABLE coffee_shops ADD COLUMN IF NOT EXISTS slug varchar(60);
+
+DO $$
+DECLARE
+    shop record;
+    base text;
+    candidate text;
+    n integer;
+BEGIN
+    FOR shop IN SELECT id, tenant_id, name FROM coffee_shops ORDER BY id LOOP
+        base := rtrim(left(trim(BOTH '-' FROM regexp_replace(lower(shop.name), '[^a-z0-9]+', '-', 'g')), 60), '-');
+        IF base = '' THEN
+            base := 'shop-' || shop.id;
+        END IF;
+
+        candidate := base;
+        n := 2;
+        WHILE EXISTS (SELECT 1 FROM coffee_shops WHERE tenant_id = shop.tenant_id AND slug = candidate) LOOP
+            candidate := base || '-' || n;
+            n := n + 1;
+        END LOOP;
+
+        UPDATE coffee_shops SET slug = candidate WHERE id = shop.id;
+    END LOOP;
+END $$;
+
+CREATE UNIQUE INDEX idx_coffee_shops_tenant_slug ON coffee_shops (tenant_id, slug) WHERE deleted_at IS NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS opening_exception_intervals CASCADE;
+DROP TABLE IF EXISTS opening_exceptions CASCADE;
+DROP TABLE IF EXISTS opening_hours CASCADE;
+ALTER TABLE coffee_shops DROP COLUMN time_zone;
@@ -0,0 +1,33 @@
+ALTER TABLE coffee_shops ADD COLUMN time_zone text NOT NULL DEFAULT 'Asia/Tehran';
+
+CREATE TABLE opening_hours (
+    id bigserial,
+    coffee_shop_id bigint NOT NULL,
+    weekday bigint NOT NULL,
+    opens_at varchar(5) NOT NULL,
+    closes_at varchar(5) NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_opening_hours_coffee_shop_id ON opening_hours (coffee_shop_id);
+
+CREATE TABLE opening_exceptions (
+    id bigserial,
+    coffee_shop_id bigint NOT NULL,
+    "date" varchar(10) NOT NULL,
+    is_closed boolean,
+    note text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX idx_opening_exceptions_shop_date ON opening_exceptions (coffee_shop_id, "date");
+
+CREATE TABLE opening_exception_intervals (
+    id bigserial,
+    opening_exception_id bigint NOT NULL,
+    opens_at varchar(5) NOT NULL,
+    closes_at varchar(5) NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_opening_exceptions_intervals FOREIGN KEY (opening_exception_id) REFERENCES opening_exceptions (id)
+);
+CREATE INDEX idx_opening_exception_intervals_opening_exception_id ON opening_exception_intervals (opening_exception_id);
@@ -0,0 +1,14 @@
+CREATE TABLE availability_windows (
+    id bigserial,
+    coffee_shop_id bigint NOT NULL,
+    menu_item_id bigint,
+    category_id bigint,
+    weekday bigint NOT NULL,
+    starts_at varchar(5) NOT NULL,
+    ends_at varchar(5) NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_menu_items_availability FOREIGN KEY (menu_item_id) REFERENCES menu_items (id)
+);
+CREATE INDEX idx_availability_windows_category_id ON availability_windows (category_id);
+CREATE INDEX idx_availability_windows_menu_item_id ON availability_windows (menu_item_id);
+CREATE INDEX idx_availability_windows_coffee_shop_id ON availability_windows (coffee_shop_id);
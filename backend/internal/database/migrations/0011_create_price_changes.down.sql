@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS scheduled_price_changes CASCADE;
+DROP TABLE IF EXISTS price_change_batches CASCADE;
+DROP TABLE IF EXISTS price_changes CASCADE;
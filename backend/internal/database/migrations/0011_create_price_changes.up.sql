@@ -0,0 +1,62 @@
+CREATE TABLE price_changes (
+    id bigserial,
+    coffee_shop_id bigint NOT NULL,
+    menu_item_id bigint NOT NULL,
+    price_tier_id bigint NOT NULL,
+    tier_label text,
+    old_price bigint,
+    new_price bigint NOT NULL,
+    source text NOT NULL,
+    changed_by_id bigint,
+    changed_by_type text,
+    changed_by_name text,
+    scheduled_price_change_id bigint,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_price_changes_menu_item_id ON price_changes (menu_item_id);
+CREATE INDEX idx_price_changes_coffee_shop_id ON price_changes (coffee_shop_id);
+CREATE INDEX idx_price_changes_created_at ON price_changes (created_at);
+CREATE INDEX idx_price_changes_price_tier_id ON price_changes (price_tier_id);
+
+CREATE TABLE price_change_batches (
+    id bigserial,
+    coffee_shop_id bigint NOT NULL,
+    category_id bigint,
+    mode text NOT NULL,
+    "value" decimal NOT NULL,
+    round_to bigint,
+    effective_at timestamptz NOT NULL,
+    changed_by_id bigint,
+    changed_by_type text,
+    changed_by_name text,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_price_change_batches_coffee_shop_id ON price_change_batches (coffee_shop_id);
+
+CREATE TABLE scheduled_price_changes (
+    id bigserial,
+    coffee_shop_id bigint NOT NULL,
+    menu_item_id bigint NOT NULL,
+    price_tier_id bigint NOT NULL,
+    batch_id bigint,
+    new_price bigint NOT NULL,
+    effective_at timestamptz NOT NULL,
+    status text NOT NULL DEFAULT 'pending',
+    applied_at timestamptz,
+    changed_by_id bigint,
+    changed_by_type text,
+    changed_by_name text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_scheduled_price_changes_price_tier FOREIGN KEY (price_tier_id) REFERENCES menu_item_price_tiers (id),
+    CONSTRAINT fk_price_change_batches_changes FOREIGN KEY (batch_id) REFERENCES price_change_batches (id),
+    CONSTRAINT fk_scheduled_price_changes_menu_item FOREIGN KEY (menu_item_id) REFERENCES menu_items (id)
+);
+CREATE INDEX idx_scheduled_price_changes_status ON scheduled_price_changes (status);
+CREATE INDEX idx_scheduled_price_changes_effective_at ON scheduled_price_changes (effective_at);
+CREATE INDEX idx_scheduled_price_changes_batch_id ON scheduled_price_changes (batch_id);
+CREATE INDEX idx_scheduled_price_changes_menu_item_id ON scheduled_price_changes (menu_item_id);
+CREATE INDEX idx_scheduled_price_changes_coffee_shop_id ON scheduled_price_changes (coffee_shop_id);
@@ -0,0 +1,6 @@
+DELETE FROM role_permissions WHERE permission = 'inventory.manage';
+DROP TABLE IF EXISTS stock_adjustments CASCADE;
+DROP TABLE IF EXISTS recipe_lines CASCADE;
+DROP TABLE IF EXISTS ingredients CASCADE;
+ALTER TABLE menu_items DROP COLUMN stock_quantity;
+ALTER TABLE menu_items DROP COLUMN low_stock_threshold;
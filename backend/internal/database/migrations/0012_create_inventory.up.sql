@@ -0,0 +1,57 @@
+ALTER TABLE menu_items ADD COLUMN stock_quantity bigint;
+ALTER TABLE menu_items ADD COLUMN low_stock_threshold bigint;
+
+CREATE TABLE ingredients (
+    id bigserial,
+    coffee_shop_id bigint NOT NULL,
+    name text NOT NULL,
+    unit text NOT NULL,
+    stock decimal NOT NULL DEFAULT 0,
+    low_stock_threshold decimal,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_ingredients_deleted_at ON ingredients (deleted_at);
+CREATE INDEX idx_ingredients_coffee_shop_id ON ingredients (coffee_shop_id);
+
+CREATE TABLE recipe_lines (
+    id bigserial,
+    menu_item_id bigint NOT NULL,
+    ingredient_id bigint NOT NULL,
+    quantity decimal NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_recipe_lines_ingredient FOREIGN KEY (ingredient_id) REFERENCES ingredients (id),
+    CONSTRAINT fk_menu_items_recipe FOREIGN KEY (menu_item_id) REFERENCES menu_items (id)
+);
+CREATE UNIQUE INDEX idx_recipe_lines_item_ingredient ON recipe_lines (menu_item_id, ingredient_id);
+
+CREATE TABLE stock_adjustments (
+    id bigserial,
+    coffee_shop_id bigint NOT NULL,
+    ingredient_id bigint,
+    menu_item_id bigint,
+    order_id bigint,
+    delta decimal NOT NULL,
+    stock_after decimal NOT NULL,
+    reason text NOT NULL,
+    note text,
+    changed_by_id bigint,
+    changed_by_type text,
+    changed_by_name text,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_stock_adjustments_ingredient_id ON stock_adjustments (ingredient_id);
+CREATE INDEX idx_stock_adjustments_coffee_shop_id ON stock_adjustments (coffee_shop_id);
+CREATE INDEX idx_stock_adjustments_created_at ON stock_adjustments (created_at);
+CREATE INDEX idx_stock_adjustments_order_id ON stock_adjustments (order_id);
+CREATE INDEX idx_stock_adjustments_menu_item_id ON stock_adjustments (menu_item_id);
+
+-- Built-in roles that manage inventory
+INSERT INTO role_permissions (role_id, permission)
+SELECT id, 'inventory.manage'
+FROM roles
+WHERE is_system AND deleted_at IS NULL AND name IN ('owner', 'manager')
+ON CONFLICT (role_id, permission) DO NOTHING;
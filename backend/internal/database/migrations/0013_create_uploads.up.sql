@@ -0,0 +1,16 @@
+CREATE TABLE uploads (
+    id bigserial,
+    coffee_shop_id bigint NOT NULL,
+    "key" text NOT NULL,
+    url text NOT NULL,
+    content_type text NOT NULL,
+    size bigint NOT NULL,
+    changed_by_id bigint,
+    changed_by_type text,
+    changed_by_name text,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_uploads_coffee_shop_id ON uploads (coffee_shop_id);
+CREATE INDEX idx_uploads_url ON uploads (url);
+CREATE UNIQUE INDEX idx_uploads_key ON uploads ("key");
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS image_variants CASCADE;
+ALTER TABLE uploads DROP COLUMN width;
+ALTER TABLE uploads DROP COLUMN height;
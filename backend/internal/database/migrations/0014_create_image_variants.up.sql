@@ -0,0 +1,18 @@
+ALTER TABLE uploads ADD COLUMN width bigint;
+ALTER TABLE uploads ADD COLUMN height bigint;
+
+CREATE TABLE image_variants (
+    id bigserial,
+    upload_id bigint NOT NULL,
+    name text NOT NULL,
+    "key" text NOT NULL,
+    url text NOT NULL,
+    content_type text NOT NULL,
+    size bigint NOT NULL,
+    width bigint NOT NULL,
+    height bigint NOT NULL,
+    created_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_uploads_variants FOREIGN KEY (upload_id) REFERENCES uploads (id)
+);
+CREATE UNIQUE INDEX idx_image_variants_upload_name ON image_variants (upload_id, name);
@@ -0,0 +1,3 @@
+DELETE FROM role_permissions WHERE permission = 'menu.publish';
+DROP TABLE IF EXISTS menu_versions CASCADE;
+DROP TABLE IF EXISTS menu_drafts CASCADE;
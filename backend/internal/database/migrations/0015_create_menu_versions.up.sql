@@ -0,0 +1,34 @@
+CREATE TABLE menu_drafts (
+    id bigserial,
+    coffee_shop_id bigint NOT NULL,
+    changes jsonb NOT NULL,
+    changed_by_id bigint,
+    changed_by_type text,
+    changed_by_name text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX idx_menu_drafts_coffee_shop_id ON menu_drafts (coffee_shop_id);
+
+CREATE TABLE menu_versions (
+    id bigserial,
+    coffee_shop_id bigint NOT NULL,
+    "number" bigint NOT NULL,
+    content jsonb NOT NULL,
+    note text,
+    restored_from_id bigint,
+    changed_by_id bigint,
+    changed_by_type text,
+    changed_by_name text,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX idx_menu_versions_shop_number ON menu_versions (coffee_shop_id, "number");
+
+-- Built-in roles that publish menus
+INSERT INTO role_permissions (role_id, permission)
+SELECT id, 'menu.publish'
+FROM roles
+WHERE is_system AND deleted_at IS NULL AND name IN ('owner', 'manager')
+ON CONFLICT (role_id, permission) DO NOTHING;
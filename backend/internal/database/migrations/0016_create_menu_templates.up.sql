@@ -0,0 +1,12 @@
+CREATE TABLE menu_templates (
+    id bigserial,
+    name text NOT NULL,
+    description text,
+    source_shop_id bigint,
+    item_count bigint NOT NULL,
+    content jsonb NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX idx_menu_templates_name ON menu_templates (name);
@@ -0,0 +1,6 @@
+ALTER TABLE coffee_shops DROP COLUMN default_language;
+ALTER TABLE coffee_shops DROP COLUMN translations;
+ALTER TABLE menu_items DROP COLUMN description;
+ALTER TABLE menu_items DROP COLUMN translations;
+ALTER TABLE categories DROP COLUMN translations;
+ALTER TABLE category_overrides DROP COLUMN translations;
@@ -0,0 +1,6 @@
+ALTER TABLE coffee_shops ADD COLUMN default_language varchar(5) NOT NULL DEFAULT 'fa';
+ALTER TABLE coffee_shops ADD COLUMN translations jsonb;
+ALTER TABLE menu_items ADD COLUMN description text;
+ALTER TABLE menu_items ADD COLUMN translations jsonb;
+ALTER TABLE categories ADD COLUMN translations jsonb;
+ALTER TABLE category_overrides ADD COLUMN translations jsonb;
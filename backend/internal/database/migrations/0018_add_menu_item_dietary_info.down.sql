@@ -0,0 +1,6 @@
+ALTER TABLE menu_items DROP COLUMN ingredients;
+ALTER TABLE menu_items DROP COLUMN allergens;
+ALTER TABLE menu_items DROP COLUMN allergens_declared;
+ALTER TABLE menu_items DROP COLUMN dietary_tags;
+ALTER TABLE menu_items DROP COLUMN nutrition_calories;
+ALTER TABLE menu_items DROP COLUMN nutrition_caffeine_mg;
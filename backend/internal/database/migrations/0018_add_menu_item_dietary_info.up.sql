@@ -0,0 +1,6 @@
+ALTER TABLE menu_items ADD COLUMN ingredients jsonb;
+ALTER TABLE menu_items ADD COLUMN allergens jsonb;
+ALTER TABLE menu_items ADD COLUMN allergens_declared boolean NOT NULL DEFAULT false;
+ALTER TABLE menu_items ADD COLUMN dietary_tags jsonb;
+ALTER TABLE menu_items ADD COLUMN nutrition_calories bigint;
+ALTER TABLE menu_items ADD COLUMN nutrition_caffeine_mg bigint;
@@ -0,0 +1,268 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is one versioned schema or data change. Up and Down run inside a
+// transaction together with the schema_migrations bookkeeping.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	// Down is nil for irreversible migrations
+	Down func(tx *gorm.DB) error
+}
+
+// SchemaMigration records an applied migration
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// MigrationStatus describes a known migration and whether it has been applied
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// migrationLockID is the Postgres advisory lock key held while migrating
+const migrationLockID = 7_302_416_001
+
+//go:embed migrations/*.sql
+var sqlMigrationFiles embed.FS
+
+var sqlMigrationName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// ErrIrreversibleMigration is returned when rolling back a migration without Down
+var ErrIrreversibleMigration = errors.New("migration is irreversible")
+
+// loadMigrations merges the Go migrations with the embedded SQL scripts, ordered by version
+func loadMigrations() ([]Migration, error) {
+	byVersion := make(map[int]*Migration)
+	for i := range goMigrations {
+		m := goMigrations[i]
+		if _, exists := byVersion[m.Version]; exists {
+			return nil, fmt.Errorf("duplicate migration version %d", m.Version)
+		}
+		byVersion[m.Version] = &m
+	}
+
+	entries, err := fs.ReadDir(sqlMigrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	sqlVersions := make(map[int]bool)
+	for _, entry := range entries {
+		match := sqlMigrationName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %q", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := sqlMigrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, exists := byVersion[version]
+		if exists && !sqlVersions[version] {
+			return nil, fmt.Errorf("duplicate migration version %d", version)
+		}
+		if !exists {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+			sqlVersions[version] = true
+		}
+		if match[3] == "up" {
+			m.Up = execSQL(string(content))
+		} else {
+			m.Down = execSQL(string(content))
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == nil {
+			return nil, fmt.Errorf("migration %d has no up script", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func execSQL(script string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		return tx.Exec(script).Error
+	}
+}
+
+// appliedMigrations returns the applied versions
+func appliedMigrations(db *gorm.DB) (map[int]SchemaMigration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+
+	var rows []SchemaMigration
+	if err := db.Order("version ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]SchemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// withMigrationLock runs fn on a single connection holding an advisory lock, so
+// two processes never migrate at the same time
+func withMigrationLock(fn func(db *gorm.DB) error) error {
+	return DB.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockID)
+
+		return fn(conn)
+	})
+}
+
+// MigrateUp applies all pending migrations
+func MigrateUp() error {
+	return MigrateTo(-1)
+}
+
+// MigrateDown rolls back the most recently applied migration
+func MigrateDown() error {
+	return withMigrationLock(func(db *gorm.DB) error {
+		migrations, err := loadMigrations()
+		if err != nil {
+			return err
+		}
+		applied, err := appliedMigrations(db)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0; i-- {
+			if _, ok := applied[migrations[i].Version]; ok {
+				return rollback(db, migrations[i])
+			}
+		}
+		return nil
+	})
+}
+
+// MigrateTo applies or rolls back migrations until target is the latest applied
+// version. A negative target applies everything.
+func MigrateTo(target int) error {
+	return withMigrationLock(func(db *gorm.DB) error {
+		migrations, err := loadMigrations()
+		if err != nil {
+			return err
+		}
+		applied, err := appliedMigrations(db)
+		if err != nil {
+			return err
+		}
+
+		if target >= 0 {
+			known := target == 0
+			for _, m := range migrations {
+				known = known || m.Version == target
+			}
+			if !known {
+				return fmt.Errorf("unknown migration version %d", target)
+			}
+		}
+
+		// Roll back newest first
+		for i := len(migrations) - 1; i >= 0; i-- {
+			m := migrations[i]
+			if _, ok := applied[m.Version]; ok && target >= 0 && m.Version > target {
+				if err := rollback(db, m); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, m := range migrations {
+			if _, ok := applied[m.Version]; ok || (target >= 0 && m.Version > target) {
+				continue
+			}
+			if err := apply(db, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Status lists all known migrations with their applied time
+func Status() ([]MigrationStatus, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(DB)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if row, ok := applied[m.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func apply(db *gorm.DB, m Migration) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := m.Up(tx); err != nil {
+			return err
+		}
+		return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+	}
+	return nil
+}
+
+func rollback(db *gorm.DB, m Migration) error {
+	if m.Down == nil {
+		return fmt.Errorf("rolling back migration %d (%s): %w", m.Version, m.Name, ErrIrreversibleMigration)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := m.Down(tx); err != nil {
+			return err
+		}
+		return tx.Delete(&SchemaMigration{}, m.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("rolling back migration %d (%s) failed: %w", m.Version, m.Name, err)
+	}
+	return nil
+}
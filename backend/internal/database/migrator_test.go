@@ -0,0 +1,22 @@
+package database
+
+import "testing"
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations")
+	}
+
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %d (%s) follows version %d; versions must have no gaps", m.Version, m.Name, i)
+		}
+		if m.Down == nil {
+			t.Errorf("migration %d (%s) has no down script, so -migrate to=0 can't roll it back", m.Version, m.Name)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event types pushed to kitchen displays and public menus
+const (
+	MenuItemCreated             = "menu_item.created"
+	MenuItemUpdated             = "menu_item.updated"
+	MenuItemDeleted             = "menu_item.deleted"
+	MenuItemAvailabilityChanged = "menu_item.availability_changed"
+	MenuImported                = "menu.imported"
+	MenuPublished               = "menu.published"
+	MenuCopied                  = "menu.copied"
+	OrderCreated                = "order.created"
+	OrderStatusChanged          = "order.status_changed"
+	StockLow                    = "stock.low"
+)
+
+// subscriberBuffer is how many events a subscriber may lag behind before it is dropped
+const subscriberBuffer = 64
+
+// Event is a message published for a coffee shop
+type Event struct {
+	ID       uint64 `json:"id"`
+	Type     string `json:"type"`
+	ShopID   uint   `json:"shop_id"`
+	TenantID uint   `json:"tenant_id"`
+	// Public events are also delivered to the tenant's public menu subscribers
+	Public bool      `json:"-"`
+	Data   any       `json:"data,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// Subscriber receives events for one topic. Events is closed when the subscriber
+// is unsubscribed or falls too far behind.
+type Subscriber struct {
+	Events <-chan Event
+
+	topic  string
+	events chan Event
+	once   sync.Once
+}
+
+func (s *Subscriber) close() {
+	s.once.Do(func() { close(s.events) })
+}
+
+// Hub is an in-process pub/sub hub. Subscribers are grouped by topic so a publish
+// only touches the subscribers of the affected shop and tenant.
+type Hub struct {
+	mu     sync.RWMutex
+	topics map[string]map[*Subscriber]struct{}
+	lastID atomic.Uint64
+}
+
+func NewHub() *Hub {
+	return &Hub{topics: make(map[string]map[*Subscriber]struct{})}
+}
+
+// DefaultHub is the hub used by the HTTP handlers
+var DefaultHub = NewHub()
+
+// ShopTopic carries every event of a shop (admin streams)
+func ShopTopic(shopID uint) string {
+	return fmt.Sprintf("shop:%d", shopID)
+}
+
+// PublicTopic carries the public events of all shops of a tenant (public menus)
+func PublicTopic(tenantID uint) string {
+	return fmt.Sprintf("public:%d", tenantID)
+}
+
+// Subscribe registers a new subscriber on topic
+func (h *Hub) Subscribe(topic string) *Subscriber {
+	events := make(chan Event, subscriberBuffer)
+	sub := &Subscriber{Events: events, topic: topic, events: events}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.topics[topic] == nil {
+		h.topics[topic] = make(map[*Subscriber]struct{})
+	}
+	h.topics[topic][sub] = struct{}{}
+
+	return sub
+}
+
+// Unsubscribe removes a subscriber and closes its channel
+func (h *Hub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(sub)
+}
+
+func (h *Hub) remove(sub *Subscriber) {
+	if subs, ok := h.topics[sub.topic]; ok {
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(h.topics, sub.topic)
+		}
+	}
+	sub.close()
+}
+
+// Publish delivers an event to the shop's subscribers and, for public events, to
+// the tenant's public subscribers. It never blocks: subscribers whose buffer is full
+// are dropped and are expected to reconnect.
+func (h *Hub) Publish(event Event) {
+	event.ID = h.lastID.Add(1)
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	topics := []string{ShopTopic(event.ShopID)}
+	if event.Public && event.TenantID != 0 {
+		topics = append(topics, PublicTopic(event.TenantID))
+	}
+
+	var slow []*Subscriber
+	h.mu.RLock()
+	for _, topic := range topics {
+		for sub := range h.topics[topic] {
+			select {
+			case sub.events <- event:
+			default:
+				slow = append(slow, sub)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	if len(slow) > 0 {
+		h.mu.Lock()
+		for _, sub := range slow {
+			h.remove(sub)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// SubscriberCount returns the number of subscribers on topic
+func (h *Hub) SubscriberCount(topic string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.topics[topic])
+}
+
+// Publish publishes an event on the DefaultHub
+func Publish(event Event) {
+	DefaultHub.Publish(event)
+}
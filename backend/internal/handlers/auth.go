@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strings"
+	"time"
 
 	"coffee-shop-platform/internal/config"
 	"coffee-shop-platform/internal/database"
@@ -9,6 +12,8 @@ import (
 	"coffee-shop-platform/internal/utils"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AuthHandler struct{}
@@ -17,12 +22,45 @@ func NewAuthHandler() *AuthHandler {
 	return &AuthHandler{}
 }
 
+var errInvalidRefreshToken = errors.New("invalid refresh token")
+
+// sessionUser is the identity tokens are issued for, with the user returned to the client
+type sessionUser struct {
+	utils.TokenSubject
+	User any
+}
+
+func mainAdminSession(admin models.MainAdmin) sessionUser {
+	return sessionUser{
+		TokenSubject: utils.TokenSubject{
+			UserID:   admin.ID,
+			Username: admin.Username,
+			Type:     "main_admin",
+		},
+		User: admin,
+	}
+}
+
+// shopAdminSession requires Role.Permissions to be preloaded
+func shopAdminSession(admin models.ShopAdmin) sessionUser {
+	shopID := admin.CoffeeShopID
+	return sessionUser{
+		TokenSubject: utils.TokenSubject{
+			UserID:      admin.ID,
+			Username:    admin.Username,
+			Type:        "shop_admin",
+			ShopID:      &shopID,
+			Role:        admin.RoleName(),
+			Permissions: admin.EffectivePermissions(),
+		},
+		User: admin,
+	}
+}
+
 func (h *AuthHandler) MainAdminLogin(c echo.Context) error {
 	var req models.LoginRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "Invalid request body",
-		})
+		return invalidRequestBody(c, err)
 	}
 
 	var admin models.MainAdmin
@@ -38,30 +76,17 @@ func (h *AuthHandler) MainAdminLogin(c echo.Context) error {
 		})
 	}
 
-	cfg := c.Get("config").(*config.Config)
-	token, err := utils.GenerateJWT(admin.ID, admin.Username, "main_admin", nil, cfg)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: "Failed to generate token",
-		})
-	}
-
-	return c.JSON(http.StatusOK, models.LoginResponse{
-		Token: token,
-		User:  admin,
-	})
+	return h.startSession(c, mainAdminSession(admin))
 }
 
 func (h *AuthHandler) ShopAdminLogin(c echo.Context) error {
 	var req models.LoginRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "Invalid request body",
-		})
+		return invalidRequestBody(c, err)
 	}
 
 	var admin models.ShopAdmin
-	if err := database.DB.Preload("CoffeeShop").Where("username = ? AND is_active = ?", req.Username, true).First(&admin).Error; err != nil {
+	if err := database.DB.Preload("CoffeeShop").Preload("Role.Permissions").Where("username = ? AND is_active = ?", req.Username, true).First(&admin).Error; err != nil {
 		return c.JSON(http.StatusUnauthorized, models.ErrorResponse{
 			Error: "Invalid credentials",
 		})
@@ -73,16 +98,216 @@ func (h *AuthHandler) ShopAdminLogin(c echo.Context) error {
 		})
 	}
 
+	return h.startSession(c, shopAdminSession(admin))
+}
+
+// Refresh rotates a refresh token: the presented token is used up and a new access
+// and refresh token pair is issued in the same family. Presenting a token that was
+// already used means it leaked, so the whole family is revoked.
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	var req models.RefreshRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
 	cfg := c.Get("config").(*config.Config)
-	token, err := utils.GenerateJWT(admin.ID, admin.Username, "shop_admin", &admin.CoffeeShopID, cfg)
+
+	var response models.LoginResponse
+	reused := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var token models.RefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Family").
+			Where("token_hash = ?", utils.HashToken(req.RefreshToken)).First(&token).Error; err != nil {
+			return errInvalidRefreshToken
+		}
+
+		if token.Family.RevokedAt != nil {
+			return errInvalidRefreshToken
+		}
+		if token.UsedAt != nil {
+			reused = true
+			return nil
+		}
+		if time.Now().After(token.ExpiresAt) {
+			return errInvalidRefreshToken
+		}
+
+		user, err := loadSessionUser(tx, token.Family.UserID, token.Family.UserType)
+		if err != nil {
+			return errInvalidRefreshToken
+		}
+
+		now := time.Now()
+		if err := tx.Model(&token).Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		response, err = issueTokens(tx, cfg, user, token.FamilyID)
+		return err
+	})
+
+	if reused {
+		// Revoked outside the transaction above, which only read the token
+		revokeFamily(database.DB, req.RefreshToken, models.RevokedByReuse)
+		return c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Invalid refresh token",
+			Message: "refresh token reuse detected, session revoked",
+		})
+	}
+	if errors.Is(err, errInvalidRefreshToken) {
+		return c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error: "Invalid refresh token",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to refresh token",
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Logout revokes the session of the given refresh token, or of the bearer access token
+func (h *AuthHandler) Logout(c echo.Context) error {
+	var req models.LogoutRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if req.RefreshToken != "" {
+		if err := revokeFamily(database.DB, req.RefreshToken, models.RevokedByLogout); err != nil {
+			return c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error: "Invalid refresh token",
+			})
+		}
+	} else {
+		tokenString := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+		cfg := c.Get("config").(*config.Config)
+		claims, err := utils.ParseJWT(tokenString, cfg.JWT.Secret)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error: "Refresh token or valid bearer token required",
+			})
+		}
+		familyID, _ := (*claims)["fid"].(string)
+		if err := revokeFamilyByID(database.DB, familyID, models.RevokedByLogout); err != nil {
+			return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error: "Failed to log out",
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Logged out successfully",
+	})
+}
+
+// startSession creates a new token family for a fresh login and responds with its tokens
+func (h *AuthHandler) startSession(c echo.Context, user sessionUser) error {
+	cfg := c.Get("config").(*config.Config)
+
+	familyID, err := utils.GenerateTokenID()
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to generate token",
 		})
 	}
 
-	return c.JSON(http.StatusOK, models.LoginResponse{
-		Token: token,
-		User:  admin,
+	var response models.LoginResponse
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		family := models.TokenFamily{ID: familyID, UserID: user.UserID, UserType: user.Type}
+		if err := tx.Create(&family).Error; err != nil {
+			return err
+		}
+
+		response, err = issueTokens(tx, cfg, user, familyID)
+		return err
 	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to generate token",
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// issueTokens stores a new refresh token in the family and signs a matching access token
+func issueTokens(tx *gorm.DB, cfg *config.Config, user sessionUser, familyID string) (models.LoginResponse, error) {
+	refreshToken, hash, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	if err := tx.Create(&models.RefreshToken{
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(utils.RefreshTokenTTL(cfg)),
+	}).Error; err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	accessToken, err := utils.GenerateJWT(user.TokenSubject, familyID, cfg)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	return models.LoginResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(utils.AccessTokenTTL(cfg).Seconds()),
+		User:         user.User,
+	}, nil
+}
+
+// loadSessionUser reloads an active user when refreshing, so deactivated accounts
+// can't keep their sessions alive and role changes reach the new access token
+func loadSessionUser(tx *gorm.DB, userID uint, userType string) (sessionUser, error) {
+	switch userType {
+	case "main_admin":
+		var admin models.MainAdmin
+		if err := tx.Where("id = ? AND is_active = ?", userID, true).First(&admin).Error; err != nil {
+			return sessionUser{}, err
+		}
+		return mainAdminSession(admin), nil
+	case "shop_admin":
+		var admin models.ShopAdmin
+		if err := tx.Preload("CoffeeShop").Preload("Role.Permissions").Where("id = ? AND is_active = ?", userID, true).First(&admin).Error; err != nil {
+			return sessionUser{}, err
+		}
+		return shopAdminSession(admin), nil
+	}
+	return sessionUser{}, errors.New("unknown user type")
+}
+
+// revokeFamily revokes the family of a refresh token
+func revokeFamily(db *gorm.DB, refreshToken, reason string) error {
+	var token models.RefreshToken
+	if err := db.Where("token_hash = ?", utils.HashToken(refreshToken)).First(&token).Error; err != nil {
+		return err
+	}
+	return revokeFamilyByID(db, token.FamilyID, reason)
+}
+
+func revokeFamilyByID(db *gorm.DB, familyID, reason string) error {
+	return db.Model(&models.TokenFamily{}).
+		Where("id = ? AND revoked_at IS NULL", familyID).
+		Updates(map[string]any{"revoked_at": time.Now(), "revoked_reason": reason}).Error
+}
+
+// revokeUserSessions revokes every session of a user, e.g. after deactivation or a role change
+func revokeUserSessions(db *gorm.DB, userID uint, userType, reason string) error {
+	return db.Model(&models.TokenFamily{}).
+		Where("user_id = ? AND user_type = ? AND revoked_at IS NULL", userID, userType).
+		Updates(map[string]any{"revoked_at": time.Now(), "revoked_reason": reason}).Error
+}
+
+// revokeShopSessions revokes every session of a shop's admins, e.g. after the shop is
+// deactivated or deleted
+func revokeShopSessions(db *gorm.DB, shopID uint, reason string) error {
+	admins := db.Model(&models.ShopAdmin{}).Unscoped().Select("id").Where("coffee_shop_id = ?", shopID)
+	return db.Model(&models.TokenFamily{}).
+		Where("user_type = ? AND user_id IN (?) AND revoked_at IS NULL", "shop_admin", admins).
+		Updates(map[string]any{"revoked_at": time.Now(), "revoked_reason": reason}).Error
 }
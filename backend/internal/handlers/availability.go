@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/events"
+	"coffee-shop-platform/internal/models"
+	"coffee-shop-platform/internal/schedule"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// windowStatus evaluates availability windows at now. No windows means available all day.
+func windowStatus(windows []models.AvailabilityWindow, loc *time.Location, now time.Time) schedule.Status {
+	if len(windows) == 0 {
+		return schedule.Status{Open: true}
+	}
+	return schedule.At(now, loc, func(date schedule.Date) []schedule.Span {
+		var spans []schedule.Span
+		for _, window := range windows {
+			if window.Weekday != date.Weekday() {
+				continue
+			}
+			if span, err := schedule.ParseSpan(window.StartsAt, window.EndsAt); err == nil {
+				spans = append(spans, span)
+			}
+		}
+		return spans
+	})
+}
+
+// menuAvailability evaluates the effective availability of a shop's menu items at one moment
+type menuAvailability struct {
+	loc             *time.Location
+	now             time.Time
+	categoryWindows map[uint][]models.AvailabilityWindow
+	// soldOut holds the items a recipe ingredient is short for
+	soldOut map[uint]bool
+}
+
+// loadMenuAvailability loads the shop's category windows and the items sold out for
+// lack of an ingredient; item windows are preloaded with the items
+func loadMenuAvailability(db *gorm.DB, shopID uint) (*menuAvailability, error) {
+	var coffeeShop models.CoffeeShop
+	if err := db.Select("id", "time_zone").First(&coffeeShop, shopID).Error; err != nil {
+		return nil, err
+	}
+
+	var windows []models.AvailabilityWindow
+	if err := db.Where("coffee_shop_id = ? AND category_id IS NOT NULL", shopID).Find(&windows).Error; err != nil {
+		return nil, err
+	}
+
+	var soldOut []uint
+	if err := db.Model(&models.RecipeLine{}).
+		Joins("JOIN ingredients ON ingredients.id = recipe_lines.ingredient_id").
+		Where("ingredients.coffee_shop_id = ? AND ingredients.deleted_at IS NULL AND ingredients.stock < recipe_lines.quantity", shopID).
+		Distinct().Pluck("recipe_lines.menu_item_id", &soldOut).Error; err != nil {
+		return nil, err
+	}
+
+	availability := &menuAvailability{
+		loc:             shopLocation(&coffeeShop),
+		now:             time.Now(),
+		categoryWindows: make(map[uint][]models.AvailabilityWindow),
+		soldOut:         make(map[uint]bool, len(soldOut)),
+	}
+	for _, window := range windows {
+		availability.categoryWindows[*window.CategoryID] = append(availability.categoryWindows[*window.CategoryID], window)
+	}
+	for _, menuItemID := range soldOut {
+		availability.soldOut[menuItemID] = true
+	}
+	return availability, nil
+}
+
+// inStock reports whether the item's own count and every recipe ingredient suffice
+// for one serving
+func (a *menuAvailability) inStock(menuItem *models.MenuItem) bool {
+	return (menuItem.StockQuantity == nil || *menuItem.StockQuantity > 0) && !a.soldOut[menuItem.ID]
+}
+
+// available reports whether the item is switched on, in stock and inside both its own
+// and its category's windows
+func (a *menuAvailability) available(menuItem *models.MenuItem) bool {
+	return menuItem.IsAvailable &&
+		a.inStock(menuItem) &&
+		windowStatus(menuItem.Availability, a.loc, a.now).Open &&
+		windowStatus(a.categoryWindows[menuItem.CategoryID], a.loc, a.now).Open
+}
+
+// set sets AvailableNow and InStock on the item
+func (a *menuAvailability) set(menuItem *models.MenuItem) {
+	available := a.available(menuItem)
+	inStock := a.inStock(menuItem)
+	menuItem.AvailableNow = &available
+	menuItem.InStock = &inStock
+}
+
+// apply sets AvailableNow and InStock on the items
+func (a *menuAvailability) apply(menuItems []models.MenuItem) {
+	for i := range menuItems {
+		a.set(&menuItems[i])
+	}
+}
+
+// newAvailabilityWindows validates window requests and expands them to one window per weekday
+func newAvailabilityWindows(shopID uint, reqs []models.AvailabilityWindowRequest) ([]models.AvailabilityWindow, error) {
+	windows := make([]models.AvailabilityWindow, 0, len(reqs))
+	for _, req := range reqs {
+		if len(req.Weekdays) == 0 {
+			return nil, fmt.Errorf("window %s-%s has no weekdays", req.StartsAt, req.EndsAt)
+		}
+		if _, err := schedule.ParseSpan(req.StartsAt, req.EndsAt); err != nil {
+			return nil, err
+		}
+		for _, weekday := range req.Weekdays {
+			if weekday < time.Sunday || weekday > time.Saturday {
+				return nil, fmt.Errorf("weekday must be between 0 (Sunday) and 6 (Saturday)")
+			}
+			windows = append(windows, models.AvailabilityWindow{
+				CoffeeShopID: shopID,
+				Weekday:      weekday,
+				StartsAt:     req.StartsAt,
+				EndsAt:       req.EndsAt,
+			})
+		}
+	}
+	return windows, nil
+}
+
+// respondAvailability returns a schedule with its current status in the shop's time zone
+func respondAvailability(c echo.Context, shopID uint, windows []models.AvailabilityWindow) error {
+	availability, err := loadMenuAvailability(database.DB, shopID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve availability",
+		})
+	}
+
+	status := windowStatus(windows, availability.loc, availability.now)
+	return c.JSON(http.StatusOK, models.AvailabilityResponse{
+		Windows:      windows,
+		AvailableNow: status.Open,
+		NextChangeAt: status.NextChangeAt,
+	})
+}
+
+// GetMenuItemAvailability returns the availability windows of a menu item
+func (h *MenuHandler) GetMenuItemAvailability(c echo.Context) error {
+	menuItem, err := findShopMenuItem(c)
+	if menuItem == nil {
+		return err
+	}
+
+	var windows []models.AvailabilityWindow
+	if err := database.DB.Where("menu_item_id = ?", menuItem.ID).Order("weekday ASC, starts_at ASC").Find(&windows).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve availability",
+		})
+	}
+
+	return respondAvailability(c, menuItem.CoffeeShopID, windows)
+}
+
+// UpdateMenuItemAvailability replaces the availability windows of a menu item
+func (h *MenuHandler) UpdateMenuItemAvailability(c echo.Context) error {
+	menuItem, err := findShopMenuItem(c)
+	if menuItem == nil {
+		return err
+	}
+
+	var req models.AvailabilityUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	windows, err := newAvailabilityWindows(menuItem.CoffeeShopID, req.Windows)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid availability",
+			Message: err.Error(),
+		})
+	}
+	for i := range windows {
+		windows[i].MenuItemID = &menuItem.ID
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("menu_item_id = ?", menuItem.ID).Delete(&models.AvailabilityWindow{}).Error; err != nil {
+			return err
+		}
+		if len(windows) == 0 {
+			return nil
+		}
+		return tx.Create(&windows).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update availability",
+		})
+	}
+
+	publishShopEvent(events.MenuItemAvailabilityChanged, menuItem.CoffeeShopID, true, map[string]any{
+		"id":           menuItem.ID,
+		"availability": windows,
+	})
+
+	return respondAvailability(c, menuItem.CoffeeShopID, windows)
+}
+
+// GetCategoryAvailability returns the shop's availability windows of a category
+func (h *CategoryHandler) GetCategoryAvailability(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid category ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+
+	if !categoryAvailableToShop(uint(id), shopID) {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Category not found",
+		})
+	}
+
+	var windows []models.AvailabilityWindow
+	if err := database.DB.Where("coffee_shop_id = ? AND category_id = ?", shopID, uint(id)).Order("weekday ASC, starts_at ASC").Find(&windows).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve availability",
+		})
+	}
+
+	return respondAvailability(c, shopID, windows)
+}
+
+// UpdateCategoryAvailability replaces the shop's availability windows of a category.
+// Template categories get windows per shop.
+func (h *CategoryHandler) UpdateCategoryAvailability(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid category ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+	categoryID := uint(id)
+
+	if !categoryAvailableToShop(categoryID, shopID) {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Category not found",
+		})
+	}
+
+	var req models.AvailabilityUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	windows, err := newAvailabilityWindows(shopID, req.Windows)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid availability",
+			Message: err.Error(),
+		})
+	}
+	for i := range windows {
+		windows[i].CategoryID = &categoryID
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("coffee_shop_id = ? AND category_id = ?", shopID, categoryID).Delete(&models.AvailabilityWindow{}).Error; err != nil {
+			return err
+		}
+		if len(windows) == 0 {
+			return nil
+		}
+		return tx.Create(&windows).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update availability",
+		})
+	}
+
+	return respondAvailability(c, shopID, windows)
+}
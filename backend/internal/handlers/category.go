@@ -2,12 +2,15 @@ package handlers
 
 import (
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 
 	"coffee-shop-platform/internal/database"
 	"coffee-shop-platform/internal/models"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 type CategoryHandler struct{}
@@ -16,54 +19,138 @@ func NewCategoryHandler() *CategoryHandler {
 	return &CategoryHandler{}
 }
 
-// GetCategories retrieves all active categories
+// GetCategories retrieves the active categories of the shop given by :slug or the
+// tenant's default branch, or the active template categories when no tenant is resolved
 func (h *CategoryHandler) GetCategories(c echo.Context) error {
 	var categories []models.Category
-	if err := database.DB.Where("is_active = ?", true).Order("order_index ASC").Find(&categories).Error; err != nil {
+	var coffeeShop *models.CoffeeShop
+	var err error
+
+	if c.Get("tenant_id") != nil {
+		var shopErr error
+		if coffeeShop, shopErr = findPublicShop(c); coffeeShop == nil {
+			return shopErr
+		}
+		categories, err = effectiveCategories(database.DB, coffeeShop.ID, true)
+	} else {
+		err = database.DB.Where("coffee_shop_id IS NULL AND is_active = ?", true).Order("order_index ASC").Find(&categories).Error
+	}
+
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to retrieve categories",
 		})
 	}
 
+	newMenuLocalizer(c, coffeeShop).categories(categories)
+
 	return c.JSON(http.StatusOK, categories)
 }
 
-// GetAllCategories retrieves all categories (including inactive) for admin
+// ListCategories serves GET /api/admin/categories for both admin types: the main admin
+// gets all template categories, a shop admin the active categories of their shop
+func (h *CategoryHandler) ListCategories(c echo.Context) error {
+	if c.Get("user_type") == "main_admin" {
+		return h.GetAllCategories(c)
+	}
+
+	shopID, ok := c.Get("shop_id").(uint)
+	if !ok {
+		return c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error: "Shop admin access required",
+		})
+	}
+
+	// The shop's categories are merged in memory, in display order
+	params, err := parseListParams(c, map[string]string{"order_index": "order_index"}, "order_index")
+	if params == nil {
+		return err
+	}
+
+	categories, err := effectiveCategories(database.DB, shopID, true)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve categories",
+		})
+	}
+
+	if q := strings.ToLower(strings.TrimSpace(c.QueryParam("q"))); q != "" {
+		matching := make([]models.Category, 0, len(categories))
+		for _, category := range categories {
+			if strings.Contains(strings.ToLower(category.Name), q) || strings.Contains(strings.ToLower(category.DisplayName), q) {
+				matching = append(matching, category)
+			}
+		}
+		categories = matching
+	}
+
+	return c.JSON(http.StatusOK, listResponse(params, pageItems(params, categories), int64(len(categories))))
+}
+
+// categorySortKeys are the keys template category lists can be sorted by
+var categorySortKeys = map[string]string{
+	"order_index":  "order_index",
+	"name":         "name",
+	"display_name": "display_name",
+	"created_at":   "created_at",
+}
+
+// GetAllCategories retrieves template categories for admin, inactive ones included
+// unless filtered by ?is_active=
 func (h *CategoryHandler) GetAllCategories(c echo.Context) error {
+	params, err := parseListParams(c, categorySortKeys, "order_index")
+	if params == nil {
+		return err
+	}
+
+	query, err := filterBool(c, database.DB.Model(&models.Category{}).Where("coffee_shop_id IS NULL"), "is_active", "is_active")
+	if query == nil {
+		return err
+	}
+	query = filterName(c, query, "name", "display_name")
+
 	var categories []models.Category
-	if err := database.DB.Order("order_index ASC").Find(&categories).Error; err != nil {
+	total, err := params.find(query, &categories)
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to retrieve categories",
 		})
 	}
 
-	return c.JSON(http.StatusOK, categories)
+	return c.JSON(http.StatusOK, listResponse(params, categories, total))
 }
 
 // CreateCategory creates a new category
 func (h *CategoryHandler) CreateCategory(c echo.Context) error {
 	var req models.CategoryCreateRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "Invalid request body",
-		})
+		return invalidRequestBody(c, err)
 	}
 
-	// Check if category name already exists
+	// Check if template category name already exists
 	var existingCategory models.Category
-	if err := database.DB.Where("name = ?", req.Name).First(&existingCategory).Error; err == nil {
+	if err := database.DB.Where("name = ? AND coffee_shop_id IS NULL", req.Name).First(&existingCategory).Error; err == nil {
 		return c.JSON(http.StatusConflict, models.ErrorResponse{
 			Error: "Category name already exists",
 		})
 	}
 
+	translations, err := req.Translations.Normalize(models.FieldDisplayName)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid translations",
+			Message: err.Error(),
+		})
+	}
+
 	category := models.Category{
-		Name:        req.Name,
-		DisplayName: req.DisplayName,
-		Emoji:       req.Emoji,
-		Color:       req.Color,
-		OrderIndex:  req.OrderIndex,
-		IsActive:    true,
+		Name:         req.Name,
+		DisplayName:  req.DisplayName,
+		Translations: translations,
+		Emoji:        req.Emoji,
+		Color:        req.Color,
+		OrderIndex:   req.OrderIndex,
+		IsActive:     true,
 	}
 
 	if err := database.DB.Create(&category).Error; err != nil {
@@ -97,7 +184,7 @@ func (h *CategoryHandler) GetCategory(c echo.Context) error {
 	return c.JSON(http.StatusOK, category)
 }
 
-// UpdateCategory updates a category
+// UpdateCategory updates a template category
 func (h *CategoryHandler) UpdateCategory(c echo.Context) error {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -108,13 +195,12 @@ func (h *CategoryHandler) UpdateCategory(c echo.Context) error {
 
 	var req models.CategoryUpdateRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "Invalid request body",
-		})
+		return invalidRequestBody(c, err)
 	}
 
+	// Shop categories are managed by their shop
 	var category models.Category
-	if err := database.DB.First(&category, uint(id)).Error; err != nil {
+	if err := database.DB.Where("coffee_shop_id IS NULL").First(&category, uint(id)).Error; err != nil {
 		return c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Error: "Category not found",
 		})
@@ -123,7 +209,7 @@ func (h *CategoryHandler) UpdateCategory(c echo.Context) error {
 	// Check if new name conflicts with existing category
 	if req.Name != nil && *req.Name != category.Name {
 		var existingCategory models.Category
-		if err := database.DB.Where("name = ? AND id != ?", *req.Name, uint(id)).First(&existingCategory).Error; err == nil {
+		if err := database.DB.Scopes(sameCategoryOwner(&category)).Where("name = ? AND id != ?", *req.Name, uint(id)).First(&existingCategory).Error; err == nil {
 			return c.JSON(http.StatusConflict, models.ErrorResponse{
 				Error: "Category name already exists",
 			})
@@ -137,6 +223,16 @@ func (h *CategoryHandler) UpdateCategory(c echo.Context) error {
 	if req.DisplayName != nil {
 		category.DisplayName = *req.DisplayName
 	}
+	if req.Translations != nil {
+		translations, err := req.Translations.Normalize(models.FieldDisplayName)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid translations",
+				Message: err.Error(),
+			})
+		}
+		category.Translations = translations
+	}
 	if req.Emoji != nil {
 		category.Emoji = *req.Emoji
 	}
@@ -162,7 +258,7 @@ func (h *CategoryHandler) UpdateCategory(c echo.Context) error {
 	})
 }
 
-// DeleteCategory deletes a category (soft delete)
+// DeleteCategory deletes a template category (soft delete)
 func (h *CategoryHandler) DeleteCategory(c echo.Context) error {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -171,6 +267,13 @@ func (h *CategoryHandler) DeleteCategory(c echo.Context) error {
 		})
 	}
 
+	var category models.Category
+	if err := database.DB.Where("coffee_shop_id IS NULL").First(&category, uint(id)).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Category not found",
+		})
+	}
+
 	// Check if category has menu items
 	var count int64
 	database.DB.Model(&models.MenuItem{}).Where("category_id = ?", uint(id)).Count(&count)
@@ -180,7 +283,187 @@ func (h *CategoryHandler) DeleteCategory(c echo.Context) error {
 		})
 	}
 
-	if err := database.DB.Delete(&models.Category{}, uint(id)).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("category_id = ?", uint(id)).Delete(&models.CategoryOverride{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Category{}, uint(id)).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to delete category",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Category deleted successfully",
+	})
+}
+
+// GetShopCategories retrieves the shop's effective categories, including inactive
+// ones, for management by the shop admin
+func (h *CategoryHandler) GetShopCategories(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	categories, err := effectiveCategories(database.DB, shopID, false)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve categories",
+		})
+	}
+
+	return c.JSON(http.StatusOK, categories)
+}
+
+// CreateShopCategory creates a category owned by the admin's shop
+func (h *CategoryHandler) CreateShopCategory(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	var req models.CategoryCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	// Shop categories may not shadow a template or another category of the shop
+	var existingCategory models.Category
+	if err := database.DB.Where("name = ? AND (coffee_shop_id IS NULL OR coffee_shop_id = ?)", req.Name, shopID).First(&existingCategory).Error; err == nil {
+		return c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error: "Category name already exists",
+		})
+	}
+
+	translations, err := req.Translations.Normalize(models.FieldDisplayName)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid translations",
+			Message: err.Error(),
+		})
+	}
+
+	category := models.Category{
+		CoffeeShopID: &shopID,
+		Name:         req.Name,
+		DisplayName:  req.DisplayName,
+		Translations: translations,
+		Emoji:        req.Emoji,
+		Color:        req.Color,
+		OrderIndex:   req.OrderIndex,
+		IsActive:     true,
+	}
+
+	if err := database.DB.Create(&category).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to create category",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Category created successfully",
+		Data:    category,
+	})
+}
+
+// UpdateShopCategory updates a category owned by the admin's shop
+func (h *CategoryHandler) UpdateShopCategory(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid category ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+
+	var req models.CategoryUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	var category models.Category
+	if err := database.DB.Where("id = ? AND coffee_shop_id = ?", uint(id), shopID).First(&category).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Category not found",
+			Message: "template categories can only be overridden",
+		})
+	}
+
+	if req.Name != nil && *req.Name != category.Name {
+		var existingCategory models.Category
+		if err := database.DB.Where("name = ? AND id != ? AND (coffee_shop_id IS NULL OR coffee_shop_id = ?)", *req.Name, uint(id), shopID).First(&existingCategory).Error; err == nil {
+			return c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error: "Category name already exists",
+			})
+		}
+	}
+
+	if req.Name != nil {
+		category.Name = *req.Name
+	}
+	if req.DisplayName != nil {
+		category.DisplayName = *req.DisplayName
+	}
+	if req.Translations != nil {
+		translations, err := req.Translations.Normalize(models.FieldDisplayName)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid translations",
+				Message: err.Error(),
+			})
+		}
+		category.Translations = translations
+	}
+	if req.Emoji != nil {
+		category.Emoji = *req.Emoji
+	}
+	if req.Color != nil {
+		category.Color = *req.Color
+	}
+	if req.OrderIndex != nil {
+		category.OrderIndex = *req.OrderIndex
+	}
+	if req.IsActive != nil {
+		category.IsActive = *req.IsActive
+	}
+
+	if err := database.DB.Save(&category).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update category",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Category updated successfully",
+		Data:    category,
+	})
+}
+
+// DeleteShopCategory deletes a category owned by the admin's shop (soft delete)
+func (h *CategoryHandler) DeleteShopCategory(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid category ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+
+	var category models.Category
+	if err := database.DB.Where("id = ? AND coffee_shop_id = ?", uint(id), shopID).First(&category).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Category not found",
+		})
+	}
+
+	var count int64
+	database.DB.Model(&models.MenuItem{}).Where("category_id = ?", category.ID).Count(&count)
+	if count > 0 {
+		return c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error: "Cannot delete category with existing menu items",
+		})
+	}
+
+	if err := database.DB.Delete(&category).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to delete category",
 		})
@@ -190,3 +473,135 @@ func (h *CategoryHandler) DeleteCategory(c echo.Context) error {
 		Message: "Category deleted successfully",
 	})
 }
+
+// OverrideCategory sets the shop's override of a template category's name, emoji,
+// color, order or visibility
+func (h *CategoryHandler) OverrideCategory(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid category ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+
+	var req models.CategoryOverrideRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	var category models.Category
+	if err := database.DB.Where("id = ? AND coffee_shop_id IS NULL", uint(id)).First(&category).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Template category not found",
+		})
+	}
+
+	translations, err := req.Translations.Normalize(models.FieldDisplayName)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid translations",
+			Message: err.Error(),
+		})
+	}
+
+	override := models.CategoryOverride{CoffeeShopID: shopID, CategoryID: category.ID}
+	database.DB.Where(&override).First(&override)
+
+	override.DisplayName = req.DisplayName
+	override.Translations = translations
+	override.Emoji = req.Emoji
+	override.Color = req.Color
+	override.OrderIndex = req.OrderIndex
+	override.IsActive = req.IsActive
+
+	if err := database.DB.Save(&override).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to save category override",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Category override saved successfully",
+		Data:    override.Apply(category),
+	})
+}
+
+// ResetCategoryOverride removes the shop's override of a template category
+func (h *CategoryHandler) ResetCategoryOverride(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid category ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+
+	if err := database.DB.Where("category_id = ? AND coffee_shop_id = ?", uint(id), shopID).Delete(&models.CategoryOverride{}).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to reset category override",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Category override reset successfully",
+	})
+}
+
+// effectiveCategories merges template categories, the shop's overrides and the
+// shop's own categories, ordered by their effective order index
+func effectiveCategories(db *gorm.DB, shopID uint, activeOnly bool) ([]models.Category, error) {
+	var categories []models.Category
+	if err := db.Where("coffee_shop_id IS NULL OR coffee_shop_id = ?", shopID).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	var overrides []models.CategoryOverride
+	if err := db.Where("coffee_shop_id = ?", shopID).Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+
+	overrideByCategory := make(map[uint]*models.CategoryOverride, len(overrides))
+	for i := range overrides {
+		overrideByCategory[overrides[i].CategoryID] = &overrides[i]
+	}
+
+	effective := make([]models.Category, 0, len(categories))
+	for _, category := range categories {
+		if override, ok := overrideByCategory[category.ID]; ok && category.IsTemplate() {
+			category = override.Apply(category)
+		}
+		if activeOnly && !category.IsActive {
+			continue
+		}
+		effective = append(effective, category)
+	}
+
+	sort.SliceStable(effective, func(i, j int) bool {
+		if effective[i].OrderIndex != effective[j].OrderIndex {
+			return effective[i].OrderIndex < effective[j].OrderIndex
+		}
+		return effective[i].ID < effective[j].ID
+	})
+
+	return effective, nil
+}
+
+// categoryAvailableToShop reports whether a menu item of the shop may use the category
+func categoryAvailableToShop(categoryID, shopID uint) bool {
+	var count int64
+	database.DB.Model(&models.Category{}).Where("id = ? AND (coffee_shop_id IS NULL OR coffee_shop_id = ?)", categoryID, shopID).Count(&count)
+	return count > 0
+}
+
+// sameCategoryOwner scopes a query to categories with the same owner as category
+func sameCategoryOwner(category *models.Category) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if category.IsTemplate() {
+			return db.Where("coffee_shop_id IS NULL")
+		}
+		return db.Where("coffee_shop_id = ?", *category.CoffeeShopID)
+	}
+}
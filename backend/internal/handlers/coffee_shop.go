@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -9,6 +10,7 @@ import (
 	"coffee-shop-platform/internal/utils"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 type CoffeeShopHandler struct{}
@@ -17,6 +19,16 @@ func NewCoffeeShopHandler() *CoffeeShopHandler {
 	return &CoffeeShopHandler{}
 }
 
+// coffeeShopSortKeys are the keys shop lists can be sorted by
+var coffeeShopSortKeys = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"slug":       "slug",
+	"created_at": "created_at",
+}
+
+// GetCoffeeShops lists a tenant's shops, filtered by ?is_active= and ?q= on the name
+// or slug
 func (h *CoffeeShopHandler) GetCoffeeShops(c echo.Context) error {
 	tenantID, err := strconv.ParseUint(c.Param("tenantId"), 10, 32)
 	if err != nil {
@@ -25,14 +37,26 @@ func (h *CoffeeShopHandler) GetCoffeeShops(c echo.Context) error {
 		})
 	}
 
+	params, err := parseListParams(c, coffeeShopSortKeys, "id")
+	if params == nil {
+		return err
+	}
+
+	query, err := filterBool(c, database.DB.Model(&models.CoffeeShop{}).Where("tenant_id = ?", uint(tenantID)), "is_active", "is_active")
+	if query == nil {
+		return err
+	}
+	query = filterName(c, query, "name", "slug")
+
 	var coffeeShops []models.CoffeeShop
-	if err := database.DB.Where("tenant_id = ?", uint(tenantID)).Find(&coffeeShops).Error; err != nil {
+	total, err := params.find(query, &coffeeShops)
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to retrieve coffee shops",
 		})
 	}
 
-	return c.JSON(http.StatusOK, coffeeShops)
+	return c.JSON(http.StatusOK, listResponse(params, coffeeShops, total))
 }
 
 func (h *CoffeeShopHandler) CreateCoffeeShop(c echo.Context) error {
@@ -45,24 +69,65 @@ func (h *CoffeeShopHandler) CreateCoffeeShop(c echo.Context) error {
 
 	var req models.CoffeeShopCreateRequest
 	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	language := req.DefaultLanguage
+	if language == "" {
+		language = models.LanguagePersian
+	} else if !models.IsSupportedLanguage(language) {
 		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "Invalid request body",
+			Error: "Unsupported language",
+		})
+	}
+
+	slug := req.Slug
+	if slug == "" {
+		slug = uniqueShopSlug(database.DB, uint(tenantID), req.Name)
+	} else if !utils.IsValidSlug(slug) {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid slug",
+		})
+	} else if shopSlugTaken(database.DB, uint(tenantID), slug, 0) {
+		return c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error: "Slug already exists",
 		})
 	}
 
 	coffeeShop := models.CoffeeShop{
-		TenantID:     uint(tenantID),
-		Name:         req.Name,
-		Location:     req.Location,
-		Phone:        req.Phone,
-		InstagramURL: req.InstagramURL,
-		LogoURL:      req.LogoURL,
-		HeroImageURL: req.HeroImageURL,
-		Description:  req.Description,
-		IsActive:     true,
+		TenantID:        uint(tenantID),
+		Slug:            slug,
+		Name:            req.Name,
+		Location:        req.Location,
+		Phone:           req.Phone,
+		InstagramURL:    req.InstagramURL,
+		LogoURL:         req.LogoURL,
+		HeroImageURL:    req.HeroImageURL,
+		Description:     req.Description,
+		DefaultLanguage: language,
+		IsActive:        true,
+	}
+
+	var menuTemplate models.MenuTemplate
+	if req.MenuTemplateID != nil {
+		if err := database.DB.First(&menuTemplate, *req.MenuTemplateID).Error; err != nil || menuTemplate.Content == nil {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "Menu template not found",
+			})
+		}
 	}
 
-	if err := database.DB.Create(&coffeeShop).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&coffeeShop).Error; err != nil {
+			return err
+		}
+		if menuTemplate.Content == nil {
+			return nil
+		}
+		_, _, err := applyMenuTemplate(tx, coffeeShop.ID, menuTemplate.Content, models.MenuTemplateReplace, currentActor(c))
+		return err
+	})
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to create coffee shop",
 		})
@@ -102,9 +167,7 @@ func (h *CoffeeShopHandler) UpdateCoffeeShop(c echo.Context) error {
 
 	var req models.CoffeeShopUpdateRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "Invalid request body",
-		})
+		return invalidRequestBody(c, err)
 	}
 
 	var coffeeShop models.CoffeeShop
@@ -117,6 +180,19 @@ func (h *CoffeeShopHandler) UpdateCoffeeShop(c echo.Context) error {
 	if req.Name != nil {
 		coffeeShop.Name = *req.Name
 	}
+	if req.Slug != nil && *req.Slug != coffeeShop.Slug {
+		if !utils.IsValidSlug(*req.Slug) {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "Invalid slug",
+			})
+		}
+		if shopSlugTaken(database.DB, coffeeShop.TenantID, *req.Slug, coffeeShop.ID) {
+			return c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error: "Slug already exists",
+			})
+		}
+		coffeeShop.Slug = *req.Slug
+	}
 	if req.Location != nil {
 		coffeeShop.Location = *req.Location
 	}
@@ -126,6 +202,7 @@ func (h *CoffeeShopHandler) UpdateCoffeeShop(c echo.Context) error {
 	if req.InstagramURL != nil {
 		coffeeShop.InstagramURL = *req.InstagramURL
 	}
+	oldLogoURL, oldHeroImageURL := coffeeShop.LogoURL, coffeeShop.HeroImageURL
 	if req.LogoURL != nil {
 		coffeeShop.LogoURL = *req.LogoURL
 	}
@@ -135,16 +212,51 @@ func (h *CoffeeShopHandler) UpdateCoffeeShop(c echo.Context) error {
 	if req.Description != nil {
 		coffeeShop.Description = *req.Description
 	}
+	if req.DefaultLanguage != nil {
+		if !models.IsSupportedLanguage(*req.DefaultLanguage) {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "Unsupported language",
+			})
+		}
+		coffeeShop.DefaultLanguage = *req.DefaultLanguage
+	}
+	if req.Translations != nil {
+		translations, err := req.Translations.Normalize(models.FieldName, models.FieldDescription)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid translations",
+				Message: err.Error(),
+			})
+		}
+		coffeeShop.Translations = translations
+	}
+	deactivated := req.IsActive != nil && !*req.IsActive && coffeeShop.IsActive
 	if req.IsActive != nil {
 		coffeeShop.IsActive = *req.IsActive
 	}
 
-	if err := database.DB.Save(&coffeeShop).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&coffeeShop).Error; err != nil {
+			return err
+		}
+		if deactivated {
+			return revokeShopSessions(tx, coffeeShop.ID, models.RevokedByAdmin)
+		}
+		return nil
+	})
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to update coffee shop",
 		})
 	}
 
+	if coffeeShop.LogoURL != oldLogoURL {
+		releaseUploads(oldLogoURL)
+	}
+	if coffeeShop.HeroImageURL != oldHeroImageURL {
+		releaseUploads(oldHeroImageURL)
+	}
+
 	return c.JSON(http.StatusOK, models.SuccessResponse{
 		Message: "Coffee shop updated successfully",
 		Data:    coffeeShop,
@@ -159,7 +271,13 @@ func (h *CoffeeShopHandler) DeleteCoffeeShop(c echo.Context) error {
 		})
 	}
 
-	if err := database.DB.Delete(&models.CoffeeShop{}, uint(id)).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.CoffeeShop{}, uint(id)).Error; err != nil {
+			return err
+		}
+		return revokeShopSessions(tx, uint(id), models.RevokedByAdmin)
+	})
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to delete coffee shop",
 		})
@@ -180,8 +298,33 @@ func (h *CoffeeShopHandler) CreateShopAdmin(c echo.Context) error {
 
 	var req models.ShopAdminCreateRequest
 	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	var coffeeShop models.CoffeeShop
+	if err := database.DB.First(&coffeeShop, uint(shopID)).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Coffee shop not found",
+		})
+	}
+
+	if usernameTaken(database.DB, req.Username, 0) {
+		return c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error: "Username already exists",
+		})
+	}
+
+	// Admins created by the main admin are owners unless another role is given
+	var role *models.Role
+	if req.RoleID != nil {
+		role, err = findShopRole(database.DB, *req.RoleID, uint(shopID))
+	} else {
+		role = &models.Role{}
+		err = database.DB.Where("name = ? AND is_system = ?", models.RoleOwner, true).First(role).Error
+	}
+	if err != nil {
 		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "Invalid request body",
+			Error: "Role not found",
 		})
 	}
 
@@ -194,12 +337,13 @@ func (h *CoffeeShopHandler) CreateShopAdmin(c echo.Context) error {
 
 	admin := models.ShopAdmin{
 		CoffeeShopID: uint(shopID),
+		RoleID:       &role.ID,
 		Username:     req.Username,
 		PasswordHash: passwordHash,
 		IsActive:     true,
 	}
 
-	if err := database.DB.Create(&admin).Error; err != nil {
+	if err := database.DB.Omit("Role").Create(&admin).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to create shop admin",
 		})
@@ -210,3 +354,278 @@ func (h *CoffeeShopHandler) CreateShopAdmin(c echo.Context) error {
 		Data:    admin,
 	})
 }
+
+// GetPublicShops lists the active branches of the resolved tenant with their open_now status
+func (h *CoffeeShopHandler) GetPublicShops(c echo.Context) error {
+	tenantID := c.Get("tenant_id")
+	if tenantID == nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Tenant not found",
+		})
+	}
+
+	var coffeeShops []models.CoffeeShop
+	if err := database.DB.Where("tenant_id = ? AND is_active = ?", tenantID, true).Order("id ASC").Find(&coffeeShops).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve coffee shops",
+		})
+	}
+
+	branches := make([]models.PublicShopResponse, 0, len(coffeeShops))
+	for i := range coffeeShops {
+		newMenuLocalizer(c, &coffeeShops[i]).shop(&coffeeShops[i])
+		branch, err := publicShop(&coffeeShops[i])
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error: "Failed to retrieve opening hours",
+			})
+		}
+		branches = append(branches, branch)
+	}
+
+	return c.JSON(http.StatusOK, branches)
+}
+
+// findPublicShop loads the active shop of the resolved tenant given by the :slug param.
+// Routes without a slug get the tenant's default branch, its first active shop.
+func findPublicShop(c echo.Context) (*models.CoffeeShop, error) {
+	tenantID := c.Get("tenant_id")
+	if tenantID == nil {
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Tenant not found",
+		})
+	}
+
+	query := database.DB.Where("tenant_id = ? AND is_active = ?", tenantID, true)
+	if slug := c.Param("slug"); slug != "" {
+		query = query.Where("slug = ?", slug)
+	}
+
+	var coffeeShop models.CoffeeShop
+	if err := query.Order("id ASC").First(&coffeeShop).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Coffee shop not found",
+		})
+	}
+
+	return &coffeeShop, nil
+}
+
+// shopSlugTaken reports whether another shop of the tenant already uses slug
+func shopSlugTaken(db *gorm.DB, tenantID uint, slug string, exceptID uint) bool {
+	var count int64
+	db.Model(&models.CoffeeShop{}).Where("tenant_id = ? AND slug = ? AND id <> ?", tenantID, slug, exceptID).Count(&count)
+	return count > 0
+}
+
+// uniqueShopSlug derives a slug from a shop name that is free within the tenant
+func uniqueShopSlug(db *gorm.DB, tenantID uint, name string) string {
+	base := utils.Slugify(name)
+	if base == "" {
+		base = "shop"
+	}
+	slug := base
+	for i := 2; shopSlugTaken(db, tenantID, slug, 0); i++ {
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+	return slug
+}
+
+// usernameTaken reports whether another shop admin already uses username.
+// Usernames are unique across all shops so logins are unambiguous.
+func usernameTaken(db *gorm.DB, username string, exceptID uint) bool {
+	var count int64
+	db.Model(&models.ShopAdmin{}).Where("username = ? AND id <> ?", username, exceptID).Count(&count)
+	return count > 0
+}
+
+// findShopAdmin loads an admin of the shop given by the :shopId and :id params
+func findShopAdmin(c echo.Context) (*models.ShopAdmin, error) {
+	shopID, err := strconv.ParseUint(c.Param("shopId"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid shop ID",
+		})
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid shop admin ID",
+		})
+	}
+
+	var admin models.ShopAdmin
+	if err := database.DB.Preload("Role.Permissions").Where("id = ? AND coffee_shop_id = ?", uint(id), uint(shopID)).First(&admin).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Shop admin not found",
+		})
+	}
+
+	return &admin, nil
+}
+
+// shopAdminSortKeys are the keys shop admin lists can be sorted by
+var shopAdminSortKeys = map[string]string{
+	"id":         "id",
+	"username":   "username",
+	"created_at": "created_at",
+}
+
+// GetShopAdmins lists the admins of a coffee shop, filtered by ?is_active= and ?q= on
+// the username
+func (h *CoffeeShopHandler) GetShopAdmins(c echo.Context) error {
+	shopID, err := strconv.ParseUint(c.Param("shopId"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid shop ID",
+		})
+	}
+
+	params, err := parseListParams(c, shopAdminSortKeys, "id")
+	if params == nil {
+		return err
+	}
+
+	query, err := filterBool(c, database.DB.Model(&models.ShopAdmin{}).Where("coffee_shop_id = ?", uint(shopID)), "is_active", "is_active")
+	if query == nil {
+		return err
+	}
+	query = filterName(c, query, "username")
+
+	var admins []models.ShopAdmin
+	total, err := params.find(query, &admins, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Role.Permissions")
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve shop admins",
+		})
+	}
+
+	return c.JSON(http.StatusOK, listResponse(params, admins, total))
+}
+
+func (h *CoffeeShopHandler) GetShopAdmin(c echo.Context) error {
+	admin, err := findShopAdmin(c)
+	if admin == nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, admin)
+}
+
+// UpdateShopAdmin changes a shop admin's username, password, role or active status.
+// Password, role and status changes end the admin's existing sessions.
+func (h *CoffeeShopHandler) UpdateShopAdmin(c echo.Context) error {
+	admin, err := findShopAdmin(c)
+	if admin == nil {
+		return err
+	}
+
+	var req models.ShopAdminUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if req.Username != nil && *req.Username != admin.Username {
+		if usernameTaken(database.DB, *req.Username, admin.ID) {
+			return c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error: "Username already exists",
+			})
+		}
+		admin.Username = *req.Username
+	}
+	if req.Password != nil {
+		passwordHash, err := utils.HashPassword(*req.Password)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error: "Failed to hash password",
+			})
+		}
+		admin.PasswordHash = passwordHash
+	}
+	if req.RoleID != nil {
+		role, err := findShopRole(database.DB, *req.RoleID, admin.CoffeeShopID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "Role not found",
+			})
+		}
+		admin.RoleID = &role.ID
+	}
+	if req.IsActive != nil {
+		admin.IsActive = *req.IsActive
+	}
+
+	endSessions := req.Password != nil || req.RoleID != nil || req.IsActive != nil
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Role", "CoffeeShop").Save(admin).Error; err != nil {
+			return err
+		}
+		if endSessions {
+			return revokeUserSessions(tx, admin.ID, "shop_admin", models.RevokedByAdmin)
+		}
+		return nil
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update shop admin",
+		})
+	}
+
+	database.DB.Preload("Role.Permissions").First(admin, admin.ID)
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Shop admin updated successfully",
+		Data:    admin,
+	})
+}
+
+// DeactivateShopAdmin disables a shop admin's login and ends their sessions
+func (h *CoffeeShopHandler) DeactivateShopAdmin(c echo.Context) error {
+	admin, err := findShopAdmin(c)
+	if admin == nil {
+		return err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(admin).Update("is_active", false).Error; err != nil {
+			return err
+		}
+		return revokeUserSessions(tx, admin.ID, "shop_admin", models.RevokedByAdmin)
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to deactivate shop admin",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Shop admin deactivated successfully",
+		Data:    admin,
+	})
+}
+
+// DeleteShopAdmin deletes a shop admin and ends their sessions. The username can be reused.
+func (h *CoffeeShopHandler) DeleteShopAdmin(c echo.Context) error {
+	admin, err := findShopAdmin(c)
+	if admin == nil {
+		return err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(admin).Error; err != nil {
+			return err
+		}
+		return revokeUserSessions(tx, admin.ID, "shop_admin", models.RevokedByAdmin)
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to delete shop admin",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Shop admin deleted successfully",
+	})
+}
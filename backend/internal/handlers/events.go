@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/events"
+	"coffee-shop-platform/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// heartbeatInterval keeps idle streams open through proxies
+const heartbeatInterval = 25 * time.Second
+
+type EventHandler struct {
+	hub *events.Hub
+}
+
+func NewEventHandler() *EventHandler {
+	return &EventHandler{hub: events.DefaultHub}
+}
+
+// StreamShopEvents streams every event of the admin's shop (menu changes, orders)
+// as server-sent events
+func (h *EventHandler) StreamShopEvents(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+	return h.stream(c, events.ShopTopic(shopID))
+}
+
+// StreamPublicEvents streams public menu changes of the resolved tenant
+func (h *EventHandler) StreamPublicEvents(c echo.Context) error {
+	tenantID := c.Get("tenant_id")
+	if tenantID == nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Tenant not found",
+		})
+	}
+
+	return h.stream(c, events.PublicTopic(tenantID.(uint)))
+}
+
+func (h *EventHandler) stream(c echo.Context, topic string) error {
+	sub := h.hub.Subscribe(topic)
+	defer h.hub.Unsubscribe(sub)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	fmt.Fprint(res, ": connected\n\n")
+	res.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-heartbeat.C:
+			fmt.Fprint(res, ": ping\n\n")
+			res.Flush()
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and refetches
+				return nil
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+			res.Flush()
+		}
+	}
+}
+
+// publishShopEvent publishes an event for a shop, looking up its tenant for public events
+func publishShopEvent(eventType string, shopID uint, public bool, data any) {
+	event := events.Event{
+		Type:   eventType,
+		ShopID: shopID,
+		Public: public,
+		Data:   data,
+	}
+
+	if public {
+		var coffeeShop models.CoffeeShop
+		if err := database.DB.Select("id", "tenant_id").First(&coffeeShop, shopID).Error; err == nil {
+			event.TenantID = coffeeShop.TenantID
+		}
+	}
+
+	events.Publish(event)
+}
+
+// publishMenuItemEvent publishes a change of a menu item with only its ID. Public
+// subscribers refetch the menu, which hides unavailable items and options and
+// leaves out stock and other admin fields.
+func publishMenuItemEvent(eventType string, shopID, menuItemID uint) {
+	publishShopEvent(eventType, shopID, true, map[string]any{"id": menuItemID})
+}
@@ -0,0 +1,753 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/events"
+	"coffee-shop-platform/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InventoryHandler struct{}
+
+func NewInventoryHandler() *InventoryHandler {
+	return &InventoryHandler{}
+}
+
+// stockUpdate collects the effects of stock changes in a transaction so they can be
+// published once it commits
+type stockUpdate struct {
+	shopID uint
+	// menuItems are the items whose in-stock state may have changed
+	menuItems map[uint]bool
+	lowStock  []map[string]any
+}
+
+func newStockUpdate(shopID uint) *stockUpdate {
+	return &stockUpdate{shopID: shopID, menuItems: make(map[uint]bool)}
+}
+
+// adjustIngredient changes the stock of a locked ingredient and logs the adjustment
+func (u *stockUpdate) adjustIngredient(tx *gorm.DB, ingredient *models.Ingredient, delta float64, adjustment models.StockAdjustment) error {
+	old := ingredient.Stock
+	ingredient.Stock = math.Round((old+delta)*1000) / 1000
+	if err := tx.Model(ingredient).Update("stock", ingredient.Stock).Error; err != nil {
+		return err
+	}
+
+	adjustment.CoffeeShopID = ingredient.CoffeeShopID
+	adjustment.IngredientID = &ingredient.ID
+	adjustment.Delta = delta
+	adjustment.StockAfter = ingredient.Stock
+	if err := tx.Create(&adjustment).Error; err != nil {
+		return err
+	}
+
+	// Items run out when the stock drops below their recipe quantity, so the items
+	// whose quantity lies between the old and the new stock flip
+	low, high := math.Min(old, ingredient.Stock), math.Max(old, ingredient.Stock)
+	var menuItemIDs []uint
+	if err := tx.Model(&models.RecipeLine{}).Where("ingredient_id = ? AND quantity > ? AND quantity <= ?", ingredient.ID, low, high).
+		Pluck("menu_item_id", &menuItemIDs).Error; err != nil {
+		return err
+	}
+	for _, menuItemID := range menuItemIDs {
+		u.menuItems[menuItemID] = true
+	}
+
+	if threshold := ingredient.LowStockThreshold; threshold != nil && old > *threshold && ingredient.IsLowStock() {
+		u.lowStock = append(u.lowStock, map[string]any{
+			"ingredient_id":       ingredient.ID,
+			"name":                ingredient.Name,
+			"unit":                ingredient.Unit,
+			"stock":               ingredient.Stock,
+			"low_stock_threshold": *threshold,
+		})
+	}
+	return nil
+}
+
+// adjustMenuItem changes the count of a locked, counted menu item and logs the adjustment
+func (u *stockUpdate) adjustMenuItem(tx *gorm.DB, menuItem *models.MenuItem, delta int, adjustment models.StockAdjustment) error {
+	old := *menuItem.StockQuantity
+	stock := old + delta
+	menuItem.StockQuantity = &stock
+	if err := tx.Model(menuItem).Update("stock_quantity", stock).Error; err != nil {
+		return err
+	}
+
+	adjustment.CoffeeShopID = menuItem.CoffeeShopID
+	adjustment.MenuItemID = &menuItem.ID
+	adjustment.Delta = float64(delta)
+	adjustment.StockAfter = float64(stock)
+	if err := tx.Create(&adjustment).Error; err != nil {
+		return err
+	}
+
+	if (old > 0) != (stock > 0) {
+		u.menuItems[menuItem.ID] = true
+	}
+	if threshold := menuItem.LowStockThreshold; threshold != nil && old > *threshold && stock <= *threshold {
+		u.lowStock = append(u.lowStock, map[string]any{
+			"menu_item_id":        menuItem.ID,
+			"name":                menuItem.Name,
+			"stock":               stock,
+			"low_stock_threshold": *threshold,
+		})
+	}
+	return nil
+}
+
+// publish announces items that sold out or came back and new low-stock alerts
+func (u *stockUpdate) publish() {
+	if len(u.menuItems) > 0 {
+		availability, err := loadMenuAvailability(database.DB, u.shopID)
+		if err != nil {
+			return
+		}
+
+		ids := make([]uint, 0, len(u.menuItems))
+		for id := range u.menuItems {
+			ids = append(ids, id)
+		}
+		var menuItems []models.MenuItem
+		database.DB.Preload("Availability").Where("id IN ? AND coffee_shop_id = ?", ids, u.shopID).Find(&menuItems)
+
+		for _, menuItem := range menuItems {
+			publishShopEvent(events.MenuItemAvailabilityChanged, u.shopID, true, map[string]any{
+				"id":            menuItem.ID,
+				"in_stock":      availability.inStock(&menuItem),
+				"available_now": availability.available(&menuItem),
+			})
+		}
+	}
+
+	for _, alert := range u.lowStock {
+		publishShopEvent(events.StockLow, u.shopID, false, alert)
+	}
+}
+
+// lockIngredient loads and locks an ingredient of the shop
+func lockIngredient(tx *gorm.DB, shopID, ingredientID uint) (*models.Ingredient, error) {
+	var ingredient models.Ingredient
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND coffee_shop_id = ?", ingredientID, shopID).First(&ingredient).Error; err != nil {
+		return nil, err
+	}
+	return &ingredient, nil
+}
+
+// lockMenuItem loads and locks a menu item of the shop
+func lockMenuItem(tx *gorm.DB, shopID, menuItemID uint) (*models.MenuItem, error) {
+	var menuItem models.MenuItem
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND coffee_shop_id = ?", menuItemID, shopID).First(&menuItem).Error; err != nil {
+		return nil, err
+	}
+	return &menuItem, nil
+}
+
+// sortedIDs returns the keys of m in ascending order, so rows are always locked in the
+// same order
+func sortedIDs[V any](m map[uint]V) []uint {
+	ids := make([]uint, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// consumeOrderStock takes the counted items and recipe ingredients of a placed order
+// out of stock. It fails with an order validation error when stock runs short.
+func consumeOrderStock(tx *gorm.DB, order *models.Order, update *stockUpdate) error {
+	quantities := make(map[uint]int)
+	for _, line := range order.Lines {
+		quantities[line.MenuItemID] += line.Quantity
+	}
+
+	var recipe []models.RecipeLine
+	if err := tx.Where("menu_item_id IN ?", sortedIDs(quantities)).Find(&recipe).Error; err != nil {
+		return err
+	}
+	needed := make(map[uint]float64)
+	for _, line := range recipe {
+		needed[line.IngredientID] += line.Quantity * float64(quantities[line.MenuItemID])
+	}
+
+	adjustment := models.StockAdjustment{OrderID: &order.ID, Reason: models.StockReasonOrder}
+
+	for _, menuItemID := range sortedIDs(quantities) {
+		menuItem, err := lockMenuItem(tx, order.CoffeeShopID, menuItemID)
+		if err != nil {
+			return err
+		}
+		if menuItem.StockQuantity == nil {
+			continue
+		}
+		if *menuItem.StockQuantity < quantities[menuItemID] {
+			return invalidOrder("only %d of %q left", max(*menuItem.StockQuantity, 0), menuItem.Name)
+		}
+		if err := update.adjustMenuItem(tx, menuItem, -quantities[menuItemID], adjustment); err != nil {
+			return err
+		}
+	}
+
+	for _, ingredientID := range sortedIDs(needed) {
+		ingredient, err := lockIngredient(tx, order.CoffeeShopID, ingredientID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if ingredient.Stock < needed[ingredientID] {
+			return invalidOrder("not enough %s left for this order", ingredient.Name)
+		}
+		if err := update.adjustIngredient(tx, ingredient, -needed[ingredientID], adjustment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreOrderStock puts back what a cancelled order took out of stock
+func restoreOrderStock(tx *gorm.DB, order *models.Order, update *stockUpdate) error {
+	var taken []models.StockAdjustment
+	if err := tx.Where("order_id = ? AND reason = ?", order.ID, models.StockReasonOrder).Order("id ASC").Find(&taken).Error; err != nil {
+		return err
+	}
+
+	adjustment := models.StockAdjustment{OrderID: &order.ID, Reason: models.StockReasonOrderCancelled}
+	for _, t := range taken {
+		switch {
+		case t.MenuItemID != nil:
+			menuItem, err := lockMenuItem(tx, order.CoffeeShopID, *t.MenuItemID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			// The item may have stopped being counted since
+			if menuItem.StockQuantity == nil {
+				continue
+			}
+			if err := update.adjustMenuItem(tx, menuItem, -int(t.Delta), adjustment); err != nil {
+				return err
+			}
+		case t.IngredientID != nil:
+			ingredient, err := lockIngredient(tx, order.CoffeeShopID, *t.IngredientID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := update.adjustIngredient(tx, ingredient, -t.Delta, adjustment); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// findIngredient loads the ingredient from the :id param, scoped to the shop
+func findIngredient(c echo.Context) (*models.Ingredient, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid ingredient ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+
+	var ingredient models.Ingredient
+	if err := database.DB.Where("id = ? AND coffee_shop_id = ?", uint(id), shopID).First(&ingredient).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Ingredient not found",
+		})
+	}
+
+	return &ingredient, nil
+}
+
+// GetIngredients lists the shop's ingredients by name, filtered by ?q= on the name
+func (h *InventoryHandler) GetIngredients(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	params, err := parseListParams(c, map[string]string{"name": "name", "stock": "stock", "created_at": "created_at"}, "name")
+	if params == nil {
+		return err
+	}
+
+	query := filterName(c, database.DB.Model(&models.Ingredient{}).Where("coffee_shop_id = ?", shopID), "name")
+
+	var ingredients []models.Ingredient
+	total, err := params.find(query, &ingredients)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve ingredients",
+		})
+	}
+
+	return c.JSON(http.StatusOK, listResponse(params, ingredients, total))
+}
+
+// CreateIngredient adds an ingredient; a starting stock is logged as a restock
+func (h *InventoryHandler) CreateIngredient(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	var req models.IngredientCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if req.Name == "" || req.Unit == "" {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Name and unit are required",
+		})
+	}
+	if req.Stock < 0 || (req.LowStockThreshold != nil && *req.LowStockThreshold < 0) {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Stock and threshold must not be negative",
+		})
+	}
+
+	ingredient := models.Ingredient{
+		CoffeeShopID:      shopID,
+		Name:              req.Name,
+		Unit:              req.Unit,
+		LowStockThreshold: req.LowStockThreshold,
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&ingredient).Error; err != nil {
+			return err
+		}
+		if req.Stock == 0 {
+			return nil
+		}
+		return newStockUpdate(shopID).adjustIngredient(tx, &ingredient, req.Stock, models.StockAdjustment{
+			Reason: models.StockReasonRestock,
+			Note:   "Initial stock",
+			Actor:  currentActor(c),
+		})
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to create ingredient",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Ingredient created successfully",
+		Data:    ingredient,
+	})
+}
+
+// UpdateIngredient renames an ingredient or changes its unit or low-stock threshold
+func (h *InventoryHandler) UpdateIngredient(c echo.Context) error {
+	ingredient, err := findIngredient(c)
+	if ingredient == nil {
+		return err
+	}
+
+	var req models.IngredientUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if req.Name != nil && *req.Name != "" {
+		ingredient.Name = *req.Name
+	}
+	if req.Unit != nil && *req.Unit != "" {
+		ingredient.Unit = *req.Unit
+	}
+	if req.LowStockThreshold != nil {
+		if *req.LowStockThreshold < 0 {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "Threshold must not be negative",
+			})
+		}
+		ingredient.LowStockThreshold = req.LowStockThreshold
+	}
+	if req.ClearThreshold {
+		ingredient.LowStockThreshold = nil
+	}
+
+	if err := database.DB.Select("name", "unit", "low_stock_threshold").Save(ingredient).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update ingredient",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Ingredient updated successfully",
+		Data:    ingredient,
+	})
+}
+
+// DeleteIngredient deletes an ingredient and removes it from all recipes
+func (h *InventoryHandler) DeleteIngredient(c echo.Context) error {
+	ingredient, err := findIngredient(c)
+	if ingredient == nil {
+		return err
+	}
+
+	update := newStockUpdate(ingredient.CoffeeShopID)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var menuItemIDs []uint
+		if err := tx.Model(&models.RecipeLine{}).Where("ingredient_id = ? AND quantity > ?", ingredient.ID, ingredient.Stock).
+			Pluck("menu_item_id", &menuItemIDs).Error; err != nil {
+			return err
+		}
+		for _, menuItemID := range menuItemIDs {
+			update.menuItems[menuItemID] = true
+		}
+
+		if err := tx.Where("ingredient_id = ?", ingredient.ID).Delete(&models.RecipeLine{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(ingredient).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to delete ingredient",
+		})
+	}
+
+	update.publish()
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Ingredient deleted successfully",
+	})
+}
+
+// GetLowStock lists the ingredients and counted menu items at or below their threshold
+func (h *InventoryHandler) GetLowStock(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	response := models.LowStockResponse{
+		Ingredients: []models.Ingredient{},
+		MenuItems:   []models.MenuItem{},
+	}
+	if err := database.DB.Where("coffee_shop_id = ? AND low_stock_threshold IS NOT NULL AND stock <= low_stock_threshold", shopID).
+		Order("name ASC").Find(&response.Ingredients).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve low stock",
+		})
+	}
+	if err := database.DB.Where("coffee_shop_id = ? AND stock_quantity IS NOT NULL AND low_stock_threshold IS NOT NULL AND stock_quantity <= low_stock_threshold", shopID).
+		Order("order_index ASC").Find(&response.MenuItems).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve low stock",
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetStockAdjustments returns the shop's stock log, newest first, optionally for one
+// ?ingredient_id= or ?menu_item_id=
+func (h *InventoryHandler) GetStockAdjustments(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	params, err := parseListParams(c, map[string]string{"created_at": "created_at"}, "-created_at")
+	if params == nil {
+		return err
+	}
+
+	query := database.DB.Model(&models.StockAdjustment{}).Where("coffee_shop_id = ?", shopID)
+	for _, param := range []string{"ingredient_id", "menu_item_id", "order_id"} {
+		if query, err = filterID(c, query, param, param); query == nil {
+			return err
+		}
+	}
+
+	var adjustments []models.StockAdjustment
+	total, err := params.find(query, &adjustments)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve stock adjustments",
+		})
+	}
+
+	return c.JSON(http.StatusOK, listResponse(params, adjustments, total))
+}
+
+// CreateStockAdjustment changes the stock of an ingredient or a counted menu item and
+// logs it with the given reason
+func (h *InventoryHandler) CreateStockAdjustment(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	var req models.StockAdjustmentRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	var message string
+	switch {
+	case (req.IngredientID == nil) == (req.MenuItemID == nil):
+		message = "give either ingredient_id or menu_item_id"
+	case (req.Delta == nil) == (req.SetTo == nil):
+		message = "give either delta or set_to"
+	case req.Delta != nil && *req.Delta == 0:
+		message = "delta must not be zero"
+	case req.SetTo != nil && *req.SetTo < 0:
+		message = "set_to must not be negative"
+	case !models.IsManualStockReason(req.Reason):
+		message = fmt.Sprintf("reason must be one of %v", models.ManualStockReasons)
+	}
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid stock adjustment",
+			Message: message,
+		})
+	}
+
+	adjustment := models.StockAdjustment{
+		Reason: req.Reason,
+		Note:   req.Note,
+		Actor:  currentActor(c),
+	}
+	update := newStockUpdate(shopID)
+
+	var result any
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if req.IngredientID != nil {
+			ingredient, err := lockIngredient(tx, shopID, *req.IngredientID)
+			if err != nil {
+				return err
+			}
+			delta := 0.0
+			if req.Delta != nil {
+				delta = *req.Delta
+			} else {
+				delta = *req.SetTo - ingredient.Stock
+			}
+			if ingredient.Stock+delta < 0 {
+				return errNegativeStock
+			}
+			result = ingredient
+			return update.adjustIngredient(tx, ingredient, delta, adjustment)
+		}
+
+		menuItem, err := lockMenuItem(tx, shopID, *req.MenuItemID)
+		if err != nil {
+			return err
+		}
+		if menuItem.StockQuantity == nil {
+			return errStockNotTracked
+		}
+		var delta int
+		if req.Delta != nil {
+			delta = int(*req.Delta)
+			if float64(delta) != *req.Delta {
+				return errFractionalCount
+			}
+		} else {
+			if *req.SetTo != math.Trunc(*req.SetTo) {
+				return errFractionalCount
+			}
+			delta = int(*req.SetTo) - *menuItem.StockQuantity
+		}
+		if *menuItem.StockQuantity+delta < 0 {
+			return errNegativeStock
+		}
+		result = menuItem
+		return update.adjustMenuItem(tx, menuItem, delta, adjustment)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Ingredient or menu item not found",
+		})
+	}
+	if errors.Is(err, errNegativeStock) || errors.Is(err, errStockNotTracked) || errors.Is(err, errFractionalCount) {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid stock adjustment",
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to adjust stock",
+		})
+	}
+
+	update.publish()
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Stock adjusted successfully",
+		Data:    result,
+	})
+}
+
+var (
+	errNegativeStock   = errors.New("stock must not go below zero")
+	errStockNotTracked = errors.New("stock of this menu item is not counted")
+	errFractionalCount = errors.New("menu item counts must be whole numbers")
+)
+
+// GetRecipe returns the ingredients one serving of a menu item consumes
+func (h *InventoryHandler) GetRecipe(c echo.Context) error {
+	menuItem, err := findShopMenuItem(c)
+	if menuItem == nil {
+		return err
+	}
+
+	var recipe []models.RecipeLine
+	if err := database.DB.Preload("Ingredient").Where("menu_item_id = ?", menuItem.ID).Order("id ASC").Find(&recipe).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve recipe",
+		})
+	}
+
+	return c.JSON(http.StatusOK, recipe)
+}
+
+// UpdateRecipe replaces the ingredients of a menu item. An empty recipe stops tracking
+// ingredients for the item.
+func (h *InventoryHandler) UpdateRecipe(c echo.Context) error {
+	menuItem, err := findShopMenuItem(c)
+	if menuItem == nil {
+		return err
+	}
+
+	var req models.RecipeUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	recipe := make([]models.RecipeLine, 0, len(req.Lines))
+	seen := make(map[uint]bool)
+	for _, line := range req.Lines {
+		if line.Quantity <= 0 {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid recipe",
+				Message: "quantities must be positive",
+			})
+		}
+		if seen[line.IngredientID] {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid recipe",
+				Message: fmt.Sprintf("ingredient %d is listed more than once", line.IngredientID),
+			})
+		}
+		seen[line.IngredientID] = true
+		recipe = append(recipe, models.RecipeLine{
+			MenuItemID:   menuItem.ID,
+			IngredientID: line.IngredientID,
+			Quantity:     line.Quantity,
+		})
+	}
+
+	var count int64
+	database.DB.Model(&models.Ingredient{}).Where("id IN ? AND coffee_shop_id = ?", sortedIDs(seen), menuItem.CoffeeShopID).Count(&count)
+	if int(count) != len(seen) {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid recipe",
+			Message: "some ingredients do not belong to this shop",
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("menu_item_id = ?", menuItem.ID).Delete(&models.RecipeLine{}).Error; err != nil {
+			return err
+		}
+		if len(recipe) == 0 {
+			return nil
+		}
+		return tx.Create(&recipe).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update recipe",
+		})
+	}
+
+	update := newStockUpdate(menuItem.CoffeeShopID)
+	update.menuItems[menuItem.ID] = true
+	update.publish()
+
+	return h.GetRecipe(c)
+}
+
+// UpdateMenuItemStock starts, changes or stops counting a menu item. A new count is
+// logged as a stocktake.
+func (h *InventoryHandler) UpdateMenuItemStock(c echo.Context) error {
+	menuItem, err := findShopMenuItem(c)
+	if menuItem == nil {
+		return err
+	}
+
+	var req models.MenuItemStockRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if (req.StockQuantity != nil && *req.StockQuantity < 0) || (req.LowStockThreshold != nil && *req.LowStockThreshold < 0) {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Stock and threshold must not be negative",
+		})
+	}
+
+	update := newStockUpdate(menuItem.CoffeeShopID)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		locked, err := lockMenuItem(tx, menuItem.CoffeeShopID, menuItem.ID)
+		if err != nil {
+			return err
+		}
+		menuItem = locked
+
+		if err := tx.Model(menuItem).Update("low_stock_threshold", req.LowStockThreshold).Error; err != nil {
+			return err
+		}
+		menuItem.LowStockThreshold = req.LowStockThreshold
+
+		switch {
+		case req.StockQuantity == nil:
+			if menuItem.StockQuantity != nil && *menuItem.StockQuantity <= 0 {
+				update.menuItems[menuItem.ID] = true
+			}
+			menuItem.StockQuantity = nil
+			return tx.Model(menuItem).Update("stock_quantity", nil).Error
+		case menuItem.StockQuantity == nil:
+			zero := 0
+			menuItem.StockQuantity = &zero
+			if err := tx.Model(menuItem).Update("stock_quantity", 0).Error; err != nil {
+				return err
+			}
+			update.menuItems[menuItem.ID] = true
+		}
+		if delta := *req.StockQuantity - *menuItem.StockQuantity; delta != 0 {
+			return update.adjustMenuItem(tx, menuItem, delta, models.StockAdjustment{
+				Reason: models.StockReasonStocktake,
+				Actor:  currentActor(c),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update stock",
+		})
+	}
+
+	update.publish()
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Stock updated successfully",
+		Data:    menuItem,
+	})
+}
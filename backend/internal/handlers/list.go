@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"coffee-shop-platform/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// Page sizes of list endpoints
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// listParams are the paging and sorting parameters of a list request: ?limit=, the
+// start of the page as ?offset= or the ?cursor= of the previous page, and ?sort=
+// with one of the endpoint's sort keys, prefixed with "-" to sort descending
+type listParams struct {
+	limit  int
+	offset int
+	desc   bool
+	order  string
+}
+
+// parseListParams reads the paging and sorting parameters of a list request.
+// sortKeys maps the keys the endpoint can be sorted by to their columns, and
+// defaultSort applies without ?sort=. When a parameter is invalid, the error
+// response has been written.
+func parseListParams(c echo.Context, sortKeys map[string]string, defaultSort string) (*listParams, error) {
+	params := &listParams{limit: defaultPageSize}
+
+	if value := c.QueryParam("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid limit",
+				Message: "limit must be between 1 and " + strconv.Itoa(maxPageSize),
+			})
+		}
+		params.limit = limit
+	}
+
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		offset, ok := decodeCursor(cursor)
+		if !ok {
+			return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "Invalid cursor",
+			})
+		}
+		params.offset = offset
+	} else if value := c.QueryParam("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "Invalid offset",
+			})
+		}
+		params.offset = offset
+	}
+
+	sortParam := c.QueryParam("sort")
+	if sortParam == "" {
+		sortParam = defaultSort
+	}
+	params.desc = strings.HasPrefix(sortParam, "-")
+	column, ok := sortKeys[strings.TrimPrefix(sortParam, "-")]
+	if !ok {
+		keys := make([]string, 0, len(sortKeys))
+		for key := range sortKeys {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid sort",
+			Message: "sort by one of " + strings.Join(keys, ", "),
+		})
+	}
+
+	// Rows with the same sort value keep a stable order across pages
+	direction := " ASC"
+	if params.desc {
+		direction = " DESC"
+	}
+	params.order = column + direction
+	if column != "id" {
+		params.order += ", id" + direction
+	}
+	return params, nil
+}
+
+// find loads the page of rows query matches into dest and returns how many rows it
+// matches in total. scopes, such as preloads, only apply to loading the page.
+func (p *listParams) find(query *gorm.DB, dest any, scopes ...func(*gorm.DB) *gorm.DB) (int64, error) {
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	err := query.Scopes(scopes...).Order(p.order).Limit(p.limit).Offset(p.offset).Find(dest).Error
+	return total, err
+}
+
+// listResponse wraps a page of items, out of total, in the list envelope
+func listResponse[T any](p *listParams, items []T, total int64) models.ListResponse {
+	if items == nil {
+		items = []T{}
+	}
+	response := models.ListResponse{
+		Data:   items,
+		Total:  total,
+		Limit:  p.limit,
+		Offset: p.offset,
+	}
+	if next := p.offset + len(items); len(items) > 0 && int64(next) < total {
+		response.NextCursor = encodeCursor(next)
+	}
+	return response
+}
+
+// pageItems returns the page of items, which are already filtered and sorted by the
+// page's key, for lists built in memory
+func pageItems[T any](p *listParams, items []T) []T {
+	if p.desc {
+		reversed := make([]T, len(items))
+		for i, item := range items {
+			reversed[len(items)-1-i] = item
+		}
+		items = reversed
+	}
+	if p.offset >= len(items) {
+		return nil
+	}
+	end := p.offset + p.limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[p.offset:end]
+}
+
+// encodeCursor returns the opaque cursor of the page starting at offset
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+	offset, err := strconv.Atoi(string(data))
+	if err != nil || offset < 0 {
+		return 0, false
+	}
+	return offset, true
+}
+
+// filterBool applies ?param=true|false as a condition on column. When the value is
+// invalid, the error response has been written and the query is nil.
+func filterBool(c echo.Context, query *gorm.DB, param, column string) (*gorm.DB, error) {
+	value := c.QueryParam(param)
+	if value == "" {
+		return query, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid " + param,
+		})
+	}
+	return query.Where(column+" = ?", b), nil
+}
+
+// filterID applies ?param=<id> as a condition on column. When the value is invalid,
+// the error response has been written and the query is nil.
+func filterID(c echo.Context, query *gorm.DB, param, column string) (*gorm.DB, error) {
+	value := c.QueryParam(param)
+	if value == "" {
+		return query, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid " + param,
+		})
+	}
+	return query.Where(column+" = ?", uint(id)), nil
+}
+
+// filterName applies ?q= as a case-insensitive substring match on any of columns
+func filterName(c echo.Context, query *gorm.DB, columns ...string) *gorm.DB {
+	q := strings.TrimSpace(c.QueryParam("q"))
+	if q == "" {
+		return query
+	}
+
+	pattern := "%" + likeEscaper.Replace(q) + "%"
+	conditions := make([]string, len(columns))
+	args := make([]any, len(columns))
+	for i, column := range columns {
+		conditions[i] = column + " ILIKE ?"
+		args[i] = pattern
+	}
+	return query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
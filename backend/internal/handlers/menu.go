@@ -1,13 +1,20 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"sort"
 	"strconv"
 
 	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/events"
+	"coffee-shop-platform/internal/middleware"
 	"coffee-shop-platform/internal/models"
+	"coffee-shop-platform/internal/utils"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MenuHandler struct{}
@@ -16,35 +23,77 @@ func NewMenuHandler() *MenuHandler {
 	return &MenuHandler{}
 }
 
+// GetPublicMenuItems retrieves the menu items of the shop given by :slug, or of the
+// tenant's default branch, that are available now in the shop's local time
 func (h *MenuHandler) GetPublicMenuItems(c echo.Context) error {
-	tenantID := c.Get("tenant_id")
-	if tenantID == nil {
+	coffeeShop, err := findPublicShop(c)
+	if coffeeShop == nil {
+		return err
+	}
+
+	filter, err := parseDietaryFilter(c)
+	if err != nil {
 		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "Tenant not found",
+			Error:   "Invalid filter",
+			Message: err.Error(),
 		})
 	}
 
-	var menuItems []models.MenuItem
-	if err := database.DB.Preload("Category").Where("coffee_shop_id IN (SELECT id FROM coffee_shops WHERE tenant_id = ?) AND is_available = ?", tenantID, true).Order("order_index ASC").Find(&menuItems).Error; err != nil {
+	available, err := publicMenuItems(coffeeShop.ID, filter)
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to retrieve menu items",
 		})
 	}
+	newMenuLocalizer(c, coffeeShop).menuItems(available)
+	withImages(available)
+
+	return c.JSON(http.StatusOK, available)
+}
 
-	return c.JSON(http.StatusOK, menuItems)
+// menuItemSortKeys are the keys admin menu item lists can be sorted by
+var menuItemSortKeys = map[string]string{
+	"order_index": "order_index",
+	"name":        "name",
+	"price":       "price",
+	"created_at":  "created_at",
+	"id":          "id",
 }
 
+// GetMenuItems lists the shop's menu items, filtered by ?category_id=,
+// ?is_available= and ?q= on the name
 func (h *MenuHandler) GetMenuItems(c echo.Context) error {
 	shopID := c.Get("shop_id").(uint)
-	
+
+	params, err := parseListParams(c, menuItemSortKeys, "order_index")
+	if params == nil {
+		return err
+	}
+
+	query, err := filterID(c, database.DB.Model(&models.MenuItem{}).Where("coffee_shop_id = ?", shopID), "category_id", "category_id")
+	if query == nil {
+		return err
+	}
+	if query, err = filterBool(c, query, "is_available", "is_available"); query == nil {
+		return err
+	}
+	query = filterName(c, query, "name")
+
 	var menuItems []models.MenuItem
-	if err := database.DB.Preload("Category").Where("coffee_shop_id = ?", shopID).Order("order_index ASC").Find(&menuItems).Error; err != nil {
+	total, err := params.find(query, &menuItems, withMenuItemDetails)
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to retrieve menu items",
 		})
 	}
 
-	return c.JSON(http.StatusOK, menuItems)
+	// Show the schedules' effect next to the manual is_available switch
+	if availability, err := loadMenuAvailability(database.DB, shopID); err == nil {
+		availability.apply(menuItems)
+	}
+	withImages(menuItems)
+
+	return c.JSON(http.StatusOK, listResponse(params, menuItems, total))
 }
 
 func (h *MenuHandler) CreateMenuItem(c echo.Context) error {
@@ -52,8 +101,28 @@ func (h *MenuHandler) CreateMenuItem(c echo.Context) error {
 	
 	var req models.MenuItemCreateRequest
 	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if !categoryAvailableToShop(req.CategoryID, shopID) {
+		return categoryNotFound(c)
+	}
+	if errs := checkMenuItemPricing(&req); errs != nil {
+		return invalidFields(c, errs...)
+	}
+
+	translations, err := req.Translations.Normalize(models.FieldName, models.FieldDescription)
+	if err != nil {
 		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "Invalid request body",
+			Error:   "Invalid translations",
+			Message: err.Error(),
+		})
+	}
+	dietary, err := req.DietaryInfo.Normalize()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid dietary info",
+			Message: err.Error(),
 		})
 	}
 
@@ -61,7 +130,9 @@ func (h *MenuHandler) CreateMenuItem(c echo.Context) error {
 		CoffeeShopID:   shopID,
 		CategoryID:     req.CategoryID,
 		Name:           req.Name,
-		Price:          req.Price,
+		Description:    req.Description,
+		Translations:   translations,
+		DietaryInfo:    dietary,
 		PricePremium:   req.PricePremium,
 		HasDualPricing: req.HasDualPricing,
 		ImageURL:       req.ImageURL,
@@ -69,12 +140,34 @@ func (h *MenuHandler) CreateMenuItem(c echo.Context) error {
 		IsAvailable:    req.IsAvailable,
 	}
 
-	if err := database.DB.Create(&menuItem).Error; err != nil {
+	if len(req.PriceTiers) > 0 {
+		menuItem.PriceTiers = newPriceTiers(req.PriceTiers)
+		menuItem.SyncLegacyPrices()
+	} else {
+		menuItem.Price = *req.Price
+		menuItem.PriceTiers = menuItem.LegacyPriceTiers(models.DefaultPriceTierLabel, models.PremiumPriceTierLabel)
+	}
+
+	actor := currentActor(c)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&menuItem).Error; err != nil {
+			return err
+		}
+		for i := range menuItem.PriceTiers {
+			if err := recordPriceChange(tx, &menuItem, &menuItem.PriceTiers[i], nil, models.PriceSourceCreated, actor, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to create menu item",
 		})
 	}
 
+	publishMenuItemEvent(events.MenuItemCreated, shopID, menuItem.ID)
+
 	return c.JSON(http.StatusCreated, models.SuccessResponse{
 		Message: "Menu item created successfully",
 		Data:    menuItem,
@@ -92,12 +185,17 @@ func (h *MenuHandler) GetMenuItem(c echo.Context) error {
 	shopID := c.Get("shop_id").(uint)
 	
 	var menuItem models.MenuItem
-	if err := database.DB.Preload("Category").Where("id = ? AND coffee_shop_id = ?", uint(id), shopID).First(&menuItem).Error; err != nil {
+	if err := database.DB.Scopes(withMenuItemDetails).Where("id = ? AND coffee_shop_id = ?", uint(id), shopID).First(&menuItem).Error; err != nil {
 		return c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Error: "Menu item not found",
 		})
 	}
 
+	if availability, err := loadMenuAvailability(database.DB, shopID); err == nil {
+		availability.set(&menuItem)
+	}
+	menuItem.Images = imageSets(database.DB, menuItem.ImageURL)[menuItem.ImageURL]
+
 	return c.JSON(http.StatusOK, menuItem)
 }
 
@@ -113,8 +211,13 @@ func (h *MenuHandler) UpdateMenuItem(c echo.Context) error {
 	
 	var req models.MenuItemUpdateRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "Invalid request body",
+		return invalidRequestBody(c, err)
+	}
+
+	if permission := missingMenuItemPermission(c, &req); permission != "" {
+		return c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Permission denied",
+			Message: "requires " + permission,
 		})
 	}
 
@@ -125,10 +228,37 @@ func (h *MenuHandler) UpdateMenuItem(c echo.Context) error {
 		})
 	}
 
+	wasAvailable := menuItem.IsAvailable
+	oldImageURL := menuItem.ImageURL
+
 	if req.Name != nil {
 		menuItem.Name = *req.Name
 	}
+	if req.Description != nil {
+		menuItem.Description = *req.Description
+	}
+	if req.Translations != nil {
+		translations, err := req.Translations.Normalize(models.FieldName, models.FieldDescription)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid translations",
+				Message: err.Error(),
+			})
+		}
+		menuItem.Translations = translations
+	}
+	dietary, err := updatedDietaryInfo(menuItem.DietaryInfo, &req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid dietary info",
+			Message: err.Error(),
+		})
+	}
+	menuItem.DietaryInfo = dietary
 	if req.CategoryID != nil {
+		if !categoryAvailableToShop(*req.CategoryID, shopID) {
+			return categoryNotFound(c)
+		}
 		menuItem.CategoryID = *req.CategoryID
 	}
 	if req.Price != nil {
@@ -150,12 +280,54 @@ func (h *MenuHandler) UpdateMenuItem(c echo.Context) error {
 		menuItem.IsAvailable = *req.IsAvailable
 	}
 
-	if err := database.DB.Save(&menuItem).Error; err != nil {
+	legacyPricesChanged := req.Price != nil || req.PricePremium != nil || req.HasDualPricing != nil
+	if req.PriceTiers == nil && legacyPricesChanged && menuItem.HasDualPricing && menuItem.PricePremium == nil {
+		return pricePremiumRequired(c)
+	}
+	actor := currentActor(c)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if req.PriceTiers != nil {
+			if len(req.PriceTiers) == 0 {
+				return errNoPriceTiers
+			}
+			if err := savePriceTiers(tx, &menuItem, req.PriceTiers, actor); err != nil {
+				return err
+			}
+		} else if legacyPricesChanged {
+			if err := saveLegacyPriceTiers(tx, &menuItem, actor); err != nil {
+				return err
+			}
+		}
+		// Counts are only written by inventory changes, which may run concurrently
+		return tx.Omit(clause.Associations, "StockQuantity").Save(&menuItem).Error
+	})
+	if errors.Is(err, errNoPriceTiers) || errors.Is(err, errUnknownPriceTier) {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid price tiers",
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to update menu item",
 		})
 	}
 
+	database.DB.Scopes(withMenuItemDetails).First(&menuItem, menuItem.ID)
+
+	if menuItem.ImageURL != oldImageURL {
+		releaseUploads(oldImageURL)
+	}
+	menuItem.Images = imageSets(database.DB, menuItem.ImageURL)[menuItem.ImageURL]
+
+	publishMenuItemEvent(events.MenuItemUpdated, shopID, menuItem.ID)
+	if menuItem.IsAvailable != wasAvailable {
+		publishShopEvent(events.MenuItemAvailabilityChanged, shopID, true, map[string]any{
+			"id":           menuItem.ID,
+			"is_available": menuItem.IsAvailable,
+		})
+	}
+
 	return c.JSON(http.StatusOK, models.SuccessResponse{
 		Message: "Menu item updated successfully",
 		Data:    menuItem,
@@ -172,33 +344,52 @@ func (h *MenuHandler) DeleteMenuItem(c echo.Context) error {
 
 	shopID := c.Get("shop_id").(uint)
 	
+	var imageURLs []string
+	database.DB.Model(&models.MenuItem{}).Where("id = ? AND coffee_shop_id = ?", uint(id), shopID).Pluck("image_url", &imageURLs)
+
 	if err := database.DB.Where("id = ? AND coffee_shop_id = ?", uint(id), shopID).Delete(&models.MenuItem{}).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to delete menu item",
 		})
 	}
 
+	releaseUploads(imageURLs...)
+
+	publishMenuItemEvent(events.MenuItemDeleted, shopID, uint(id))
+
 	return c.JSON(http.StatusOK, models.SuccessResponse{
 		Message: "Menu item deleted successfully",
 	})
 }
 
+// GetShopSettings returns the shop admin's own shop, or publicly the shop given by
+// :slug or the tenant's default branch with its opening hours and open_now status
 func (h *MenuHandler) GetShopSettings(c echo.Context) error {
-	tenantID := c.Get("tenant_id")
-	if tenantID == nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "Tenant not found",
-		})
+	if shopID, ok := c.Get("shop_id").(uint); ok {
+		var coffeeShop models.CoffeeShop
+		if err := database.DB.First(&coffeeShop, shopID).Error; err != nil {
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error: "Coffee shop not found",
+			})
+		}
+		withShopImages(&coffeeShop)
+		return c.JSON(http.StatusOK, coffeeShop)
 	}
 
-	var coffeeShop models.CoffeeShop
-	if err := database.DB.Where("tenant_id = ?", tenantID).First(&coffeeShop).Error; err != nil {
-		return c.JSON(http.StatusNotFound, models.ErrorResponse{
-			Error: "Coffee shop not found",
+	coffeeShop, err := findPublicShop(c)
+	if coffeeShop == nil {
+		return err
+	}
+	newMenuLocalizer(c, coffeeShop).shop(coffeeShop)
+
+	response, err := publicShop(coffeeShop)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve opening hours",
 		})
 	}
 
-	return c.JSON(http.StatusOK, coffeeShop)
+	return c.JSON(http.StatusOK, response)
 }
 
 func (h *MenuHandler) UpdateShopSettings(c echo.Context) error {
@@ -206,9 +397,7 @@ func (h *MenuHandler) UpdateShopSettings(c echo.Context) error {
 	
 	var req models.CoffeeShopUpdateRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "Invalid request body",
-		})
+		return invalidRequestBody(c, err)
 	}
 
 	var coffeeShop models.CoffeeShop
@@ -221,6 +410,19 @@ func (h *MenuHandler) UpdateShopSettings(c echo.Context) error {
 	if req.Name != nil {
 		coffeeShop.Name = *req.Name
 	}
+	if req.Slug != nil && *req.Slug != coffeeShop.Slug {
+		if !utils.IsValidSlug(*req.Slug) {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "Invalid slug",
+			})
+		}
+		if shopSlugTaken(database.DB, coffeeShop.TenantID, *req.Slug, coffeeShop.ID) {
+			return c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error: "Slug already exists",
+			})
+		}
+		coffeeShop.Slug = *req.Slug
+	}
 	if req.Location != nil {
 		coffeeShop.Location = *req.Location
 	}
@@ -230,6 +432,7 @@ func (h *MenuHandler) UpdateShopSettings(c echo.Context) error {
 	if req.InstagramURL != nil {
 		coffeeShop.InstagramURL = *req.InstagramURL
 	}
+	oldLogoURL, oldHeroImageURL := coffeeShop.LogoURL, coffeeShop.HeroImageURL
 	if req.LogoURL != nil {
 		coffeeShop.LogoURL = *req.LogoURL
 	}
@@ -239,6 +442,24 @@ func (h *MenuHandler) UpdateShopSettings(c echo.Context) error {
 	if req.Description != nil {
 		coffeeShop.Description = *req.Description
 	}
+	if req.DefaultLanguage != nil {
+		if !models.IsSupportedLanguage(*req.DefaultLanguage) {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "Unsupported language",
+			})
+		}
+		coffeeShop.DefaultLanguage = *req.DefaultLanguage
+	}
+	if req.Translations != nil {
+		translations, err := req.Translations.Normalize(models.FieldName, models.FieldDescription)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid translations",
+				Message: err.Error(),
+			})
+		}
+		coffeeShop.Translations = translations
+	}
 	if req.IsActive != nil {
 		coffeeShop.IsActive = *req.IsActive
 	}
@@ -249,8 +470,248 @@ func (h *MenuHandler) UpdateShopSettings(c echo.Context) error {
 		})
 	}
 
+	if coffeeShop.LogoURL != oldLogoURL {
+		releaseUploads(oldLogoURL)
+	}
+	if coffeeShop.HeroImageURL != oldHeroImageURL {
+		releaseUploads(oldHeroImageURL)
+	}
+	withShopImages(&coffeeShop)
+
 	return c.JSON(http.StatusOK, models.SuccessResponse{
 		Message: "Shop settings updated successfully",
 		Data:    coffeeShop,
 	})
 }
+
+// missingMenuItemPermission returns the permission a menu item update needs that the
+// user lacks, or "" when allowed. Baristas may only toggle availability, and price
+// changes need menu.prices on top of menu.edit-level access. Price and detail changes
+// go live without a draft, so they need menu.publish as well.
+func missingMenuItemPermission(c echo.Context, req *models.MenuItemUpdateRequest) string {
+	pricesChanged := req.Price != nil || req.PricePremium != nil || req.HasDualPricing != nil || req.PriceTiers != nil
+	detailsChanged := req.Name != nil || req.Description != nil || req.Translations != nil || req.CategoryID != nil || req.ImageURL != nil || req.OrderIndex != nil ||
+		req.Ingredients != nil || req.Allergens != nil || req.AllergensDeclared != nil || req.DietaryTags != nil || req.Nutrition != nil
+
+	switch {
+	case pricesChanged && !middleware.HasPermission(c, models.PermMenuPrices):
+		return models.PermMenuPrices
+	case detailsChanged && !middleware.HasPermission(c, models.PermMenuEdit):
+		return models.PermMenuEdit
+	case (pricesChanged || detailsChanged) && !middleware.HasPermission(c, models.PermMenuPublish):
+		return models.PermMenuPublish
+	case req.IsAvailable != nil && !middleware.HasPermission(c, models.PermMenuAvailability) && !middleware.HasPermission(c, models.PermMenuEdit):
+		return models.PermMenuAvailability
+	}
+	return ""
+}
+
+var (
+	errNoPriceTiers         = errors.New("at least one price tier is required")
+	errUnknownPriceTier     = errors.New("price tier does not belong to this menu item")
+	errPricePremiumRequired = errors.New("price_premium is required when has_dual_pricing is set")
+)
+
+// publicMenuItems returns the shop's menu items customers can order now that pass filter
+func publicMenuItems(shopID uint, filter *dietaryFilter) ([]models.MenuItem, error) {
+	var menuItems []models.MenuItem
+	if err := database.DB.Scopes(withPublicMenuItemDetails).Where("coffee_shop_id = ? AND is_available = ?", shopID, true).Order("order_index ASC").Find(&menuItems).Error; err != nil {
+		return nil, err
+	}
+
+	availability, err := loadMenuAvailability(database.DB, shopID)
+	if err != nil {
+		return nil, err
+	}
+
+	available := make([]models.MenuItem, 0, len(menuItems))
+	for _, menuItem := range menuItems {
+		if availability.available(&menuItem) && filter.matches(&menuItem.DietaryInfo) {
+			available = append(available, menuItem)
+		}
+	}
+	return available, nil
+}
+
+// dietaryFilter narrows the public menu to items free of some allergens and carrying
+// some dietary tags. Items that don't declare their allergens never pass an allergen
+// filter.
+type dietaryFilter struct {
+	excludeAllergens models.StringList
+	tags             models.StringList
+}
+
+// parseDietaryFilter reads ?exclude_allergens=milk,nuts&tags=vegan
+func parseDietaryFilter(c echo.Context) (*dietaryFilter, error) {
+	excludeAllergens, err := models.ParseAllergens(c.QueryParam("exclude_allergens"))
+	if err != nil {
+		return nil, err
+	}
+	tags, err := models.ParseDietaryTags(c.QueryParam("tags"))
+	if err != nil {
+		return nil, err
+	}
+	return &dietaryFilter{excludeAllergens: excludeAllergens, tags: tags}, nil
+}
+
+func (f *dietaryFilter) matches(info *models.DietaryInfo) bool {
+	if len(f.excludeAllergens) > 0 && (!info.AllergensDeclared || info.Allergens.HasAny(f.excludeAllergens)) {
+		return false
+	}
+	return info.DietaryTags.HasAll(f.tags)
+}
+
+// updatedDietaryInfo returns info with the lists and nutrition facts of an update
+// request applied
+func updatedDietaryInfo(info models.DietaryInfo, req *models.MenuItemUpdateRequest) (models.DietaryInfo, error) {
+	if req.Ingredients != nil {
+		info.Ingredients = req.Ingredients
+	}
+	if req.AllergensDeclared != nil {
+		info.AllergensDeclared = *req.AllergensDeclared
+		if !info.AllergensDeclared {
+			info.Allergens = nil
+		}
+	}
+	if req.Allergens != nil {
+		info.Allergens = req.Allergens
+	}
+	if req.DietaryTags != nil {
+		info.DietaryTags = req.DietaryTags
+	}
+	if req.Nutrition != nil {
+		info.Nutrition = *req.Nutrition
+	}
+	return info.Normalize()
+}
+
+// withMenuItemDetails preloads category, price tiers and option groups in display order
+func withMenuItemDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Category").
+		Preload("Availability").
+		Preload("PriceTiers", orderByIndex).
+		Preload("OptionGroups", orderByIndex).
+		Preload("OptionGroups.Options", orderByIndex)
+}
+
+// withPublicMenuItemDetails is withMenuItemDetails without unavailable options
+func withPublicMenuItemDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Category").
+		Preload("Availability").
+		Preload("PriceTiers", orderByIndex).
+		Preload("OptionGroups", orderByIndex).
+		Preload("OptionGroups.Options", func(db *gorm.DB) *gorm.DB {
+			return db.Where("is_available = ?", true).Order("order_index ASC")
+		})
+}
+
+// sortedPriceTierRequests orders requested tiers by order_index, keeping the request order for ties
+func sortedPriceTierRequests(reqs []models.MenuItemPriceTierRequest) []models.MenuItemPriceTierRequest {
+	sorted := make([]models.MenuItemPriceTierRequest, len(reqs))
+	copy(sorted, reqs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].OrderIndex < sorted[j].OrderIndex
+	})
+	return sorted
+}
+
+func newPriceTiers(reqs []models.MenuItemPriceTierRequest) []models.MenuItemPriceTier {
+	var tiers []models.MenuItemPriceTier
+	for i, r := range sortedPriceTierRequests(reqs) {
+		tiers = append(tiers, models.MenuItemPriceTier{
+			Label:      r.Label,
+			Price:      r.Price,
+			OrderIndex: i,
+		})
+	}
+	return tiers
+}
+
+// savePriceTiers replaces the tiers of a menu item: tiers with a known ID are updated,
+// new ones are created and the rest are deleted. Legacy price fields are synced and
+// price changes are recorded in the history as made by actor.
+func savePriceTiers(tx *gorm.DB, menuItem *models.MenuItem, reqs []models.MenuItemPriceTierRequest, actor models.Actor) error {
+	var existing []models.MenuItemPriceTier
+	if err := tx.Where("menu_item_id = ?", menuItem.ID).Find(&existing).Error; err != nil {
+		return err
+	}
+
+	byID := make(map[uint]models.MenuItemPriceTier, len(existing))
+	for _, tier := range existing {
+		byID[tier.ID] = tier
+	}
+
+	kept := make(map[uint]bool)
+	var tiers []models.MenuItemPriceTier
+	for i, r := range sortedPriceTierRequests(reqs) {
+		tier := models.MenuItemPriceTier{MenuItemID: menuItem.ID}
+		var oldPrice *int
+		if r.ID != 0 {
+			current, ok := byID[r.ID]
+			if !ok {
+				return errUnknownPriceTier
+			}
+			tier = current
+			oldPrice = &current.Price
+		}
+		tier.Label = r.Label
+		tier.Price = r.Price
+		tier.OrderIndex = i
+
+		if err := tx.Save(&tier).Error; err != nil {
+			return err
+		}
+		if oldPrice == nil || *oldPrice != tier.Price {
+			if err := recordPriceChange(tx, menuItem, &tier, oldPrice, models.PriceSourceManual, actor, nil); err != nil {
+				return err
+			}
+		}
+		kept[tier.ID] = true
+		tiers = append(tiers, tier)
+	}
+
+	for _, tier := range existing {
+		if !kept[tier.ID] {
+			if err := tx.Delete(&tier).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	menuItem.PriceTiers = tiers
+	menuItem.SyncLegacyPrices()
+	return nil
+}
+
+// saveLegacyPriceTiers maps an update of Price/PricePremium/HasDualPricing onto the
+// first two tiers, so older clients can keep editing dual-priced items.
+func saveLegacyPriceTiers(tx *gorm.DB, menuItem *models.MenuItem, actor models.Actor) error {
+	var existing []models.MenuItemPriceTier
+	if err := tx.Where("menu_item_id = ?", menuItem.ID).Order("order_index ASC").Find(&existing).Error; err != nil {
+		return err
+	}
+
+	legacy := menuItem.LegacyPriceTiers(models.DefaultPriceTierLabel, models.PremiumPriceTierLabel)
+	var reqs []models.MenuItemPriceTierRequest
+	for i, tier := range legacy {
+		req := models.MenuItemPriceTierRequest{Label: tier.Label, Price: tier.Price, OrderIndex: i}
+		if i < len(existing) {
+			req.ID = existing[i].ID
+			req.Label = existing[i].Label
+		}
+		reqs = append(reqs, req)
+	}
+	// Tiers beyond the legacy pair are only kept while dual pricing stays on
+	if menuItem.HasDualPricing {
+		for i := len(legacy); i < len(existing); i++ {
+			reqs = append(reqs, models.MenuItemPriceTierRequest{
+				ID:         existing[i].ID,
+				Label:      existing[i].Label,
+				Price:      existing[i].Price,
+				OrderIndex: i,
+			})
+		}
+	}
+
+	return savePriceTiers(tx, menuItem, reqs, actor)
+}
@@ -0,0 +1,1093 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"coffee-shop-platform/internal/config"
+	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/events"
+	"coffee-shop-platform/internal/models"
+	"coffee-shop-platform/internal/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// menuPreviewTTL is how long signed draft preview links work
+const menuPreviewTTL = 7 * 24 * time.Hour
+
+// newDraftItemPrefix starts the keys of menu items a draft creates
+const newDraftItemPrefix = "new-"
+
+var (
+	errNoMenuDraft           = errors.New("the shop has no menu draft")
+	errDraftItemNotFound     = errors.New("menu item not found")
+	errDraftCategoryNotFound = errors.New("category not found")
+	errMenuVersionNotFound   = errors.New("menu version not found")
+)
+
+type MenuDraftHandler struct{}
+
+func NewMenuDraftHandler() *MenuDraftHandler {
+	return &MenuDraftHandler{}
+}
+
+// GetDraft returns the shop's staged menu changes
+func (h *MenuDraftHandler) GetDraft(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	var draft models.MenuDraft
+	if err := database.DB.Where("coffee_shop_id = ?", shopID).First(&draft).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "No menu draft",
+		})
+	}
+
+	return c.JSON(http.StatusOK, draft)
+}
+
+// DiscardDraft drops all staged menu changes
+func (h *MenuDraftHandler) DiscardDraft(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	result := database.DB.Where("coffee_shop_id = ?", shopID).Delete(&models.MenuDraft{})
+	if result.Error != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to discard draft",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "No menu draft",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Draft discarded successfully",
+	})
+}
+
+// CreateDraftItem stages a new menu item. It gets a key like "new-1" to edit it by
+// until the draft is published.
+func (h *MenuDraftHandler) CreateDraftItem(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	var req models.MenuItemCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if req.Translations != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "translations are not part of drafts, set them with PUT /api/admin/menu/:id",
+		})
+	}
+
+	if !categoryAvailableToShop(req.CategoryID, shopID) {
+		return categoryNotFound(c)
+	}
+	if errs := checkMenuItemPricing(&req); errs != nil {
+		return invalidFields(c, errs...)
+	}
+
+	dietary, err := req.DietaryInfo.Normalize()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid dietary info",
+			Message: err.Error(),
+		})
+	}
+
+	item := models.MenuContentItem{
+		Name:        req.Name,
+		Description: req.Description,
+		CategoryID:  req.CategoryID,
+		ImageURL:    req.ImageURL,
+		OrderIndex:  req.OrderIndex,
+		DietaryInfo: dietary,
+	}
+	if len(req.PriceTiers) > 0 {
+		item.PriceTiers = contentPriceTiers(newPriceTiers(req.PriceTiers))
+	} else {
+		legacy := models.MenuItem{Price: *req.Price, PricePremium: req.PricePremium, HasDualPricing: req.HasDualPricing}
+		item.PriceTiers = contentPriceTiers(legacy.LegacyPriceTiers(models.DefaultPriceTierLabel, models.PremiumPriceTierLabel))
+	}
+
+	draft, err := updateMenuDraft(shopID, currentActor(c), func(tx *gorm.DB, changes *models.MenuContent) error {
+		item.Key = nextDraftItemKey(changes)
+		changes.Items = append(changes.Items, item)
+		return nil
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update draft",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Menu item staged successfully",
+		Data:    draft,
+	})
+}
+
+// UpdateDraftItem stages changes to a menu item, given by its ID or the key of a
+// staged new item. Availability switches are not staged; they change immediately
+// through PUT /menu/:id.
+func (h *MenuDraftHandler) UpdateDraftItem(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+	key := c.Param("key")
+
+	var req models.MenuItemUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if req.IsAvailable != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "is_available is not part of drafts, change it with PUT /api/admin/menu/:id",
+		})
+	}
+	if req.Translations != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "translations are not part of drafts, set them with PUT /api/admin/menu/:id",
+		})
+	}
+
+	if permission := missingMenuItemPermission(c, &req); permission != "" {
+		return c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Permission denied",
+			Message: "requires " + permission,
+		})
+	}
+
+	if req.CategoryID != nil && !categoryAvailableToShop(*req.CategoryID, shopID) {
+		return categoryNotFound(c)
+	}
+
+	if _, err := updatedDietaryInfo(models.DietaryInfo{}, &req); err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid dietary info",
+			Message: err.Error(),
+		})
+	}
+
+	draft, err := updateMenuDraft(shopID, currentActor(c), func(tx *gorm.DB, changes *models.MenuContent) error {
+		item, err := draftItem(tx, shopID, changes, key)
+		if err != nil {
+			return err
+		}
+		if item.Deleted {
+			return errDraftItemNotFound
+		}
+		if req.HasDualPricing != nil && *req.HasDualPricing && req.PricePremium == nil && req.PriceTiers == nil && len(item.PriceTiers) < 2 {
+			return errPricePremiumRequired
+		}
+		stageMenuItemUpdate(item, &req)
+		if len(item.PriceTiers) == 0 {
+			return errNoPriceTiers
+		}
+		return nil
+	})
+	if errors.Is(err, errDraftItemNotFound) {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Menu item not found",
+		})
+	}
+	if errors.Is(err, errNoPriceTiers) {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid price tiers",
+			Message: err.Error(),
+		})
+	}
+	if errors.Is(err, errPricePremiumRequired) {
+		return pricePremiumRequired(c)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update draft",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Menu item staged successfully",
+		Data:    draft,
+	})
+}
+
+// DeleteDraftItem stages the deletion of a menu item, or drops a staged new item
+func (h *MenuDraftHandler) DeleteDraftItem(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+	key := c.Param("key")
+
+	draft, err := updateMenuDraft(shopID, currentActor(c), func(tx *gorm.DB, changes *models.MenuContent) error {
+		if strings.HasPrefix(key, newDraftItemPrefix) {
+			for i, item := range changes.Items {
+				if item.Key == key {
+					changes.Items = append(changes.Items[:i], changes.Items[i+1:]...)
+					return nil
+				}
+			}
+			return errDraftItemNotFound
+		}
+
+		item, err := draftItem(tx, shopID, changes, key)
+		if err != nil {
+			return err
+		}
+		item.Deleted = true
+		return nil
+	})
+	if errors.Is(err, errDraftItemNotFound) {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Menu item not found",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update draft",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Menu item deletion staged successfully",
+		Data:    draft,
+	})
+}
+
+// UpdateDraftCategory stages changes to how the shop shows a category. Template
+// categories are published as overrides.
+func (h *MenuDraftHandler) UpdateDraftCategory(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid category ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+
+	var req models.CategoryOverrideRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if req.Translations != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "translations are not part of drafts, set them with PUT /api/admin/shop/categories/:id or its /override",
+		})
+	}
+
+	draft, err := updateMenuDraft(shopID, currentActor(c), func(tx *gorm.DB, changes *models.MenuContent) error {
+		category, err := draftCategory(tx, shopID, changes, uint(id))
+		if err != nil {
+			return err
+		}
+		if req.DisplayName != nil {
+			category.DisplayName = *req.DisplayName
+		}
+		if req.Emoji != nil {
+			category.Emoji = *req.Emoji
+		}
+		if req.Color != nil {
+			category.Color = *req.Color
+		}
+		if req.OrderIndex != nil {
+			category.OrderIndex = *req.OrderIndex
+		}
+		if req.IsActive != nil {
+			category.IsActive = *req.IsActive
+		}
+		return nil
+	})
+	if errors.Is(err, errDraftCategoryNotFound) {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Category not found",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update draft",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Category staged successfully",
+		Data:    draft,
+	})
+}
+
+// CreatePreviewLink returns a signed link that shows the public menu with the draft
+// applied, for sharing without an admin login. The link follows later edits of the
+// draft and stops working once the draft is published or discarded.
+func (h *MenuDraftHandler) CreatePreviewLink(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	var draft models.MenuDraft
+	if err := database.DB.Select("id").Where("coffee_shop_id = ?", shopID).First(&draft).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "No menu draft",
+		})
+	}
+
+	cfg := c.Get("config").(*config.Config)
+	expiresAt := time.Now().Add(menuPreviewTTL).Truncate(time.Second)
+	token, err := utils.GenerateMenuPreviewToken(shopID, draft.ID, expiresAt, cfg)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to create preview link",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.MenuPreviewLinkResponse{
+		URL:       "/api/public/menu/preview?token=" + url.QueryEscape(token),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// GetMenuPreview shows the public menu of a signed preview link's draft
+func (h *MenuDraftHandler) GetMenuPreview(c echo.Context) error {
+	cfg := c.Get("config").(*config.Config)
+	shopID, draftID, err := utils.ParseMenuPreviewToken(c.QueryParam("token"), cfg.JWT.Secret)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error: "Invalid preview link",
+		})
+	}
+
+	var draft models.MenuDraft
+	if err := database.DB.Where("id = ? AND coffee_shop_id = ?", draftID, shopID).First(&draft).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Preview expired",
+			Message: "the draft was published or discarded",
+		})
+	}
+
+	var coffeeShop models.CoffeeShop
+	if err := database.DB.First(&coffeeShop, shopID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Coffee shop not found",
+		})
+	}
+
+	preview, err := previewMenu(shopID, &draft.Changes)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve menu preview",
+		})
+	}
+	localizer := newMenuLocalizer(c, &coffeeShop)
+	localizer.categories(preview.Categories)
+	localizer.menuItems(preview.MenuItems)
+
+	return c.JSON(http.StatusOK, preview)
+}
+
+// PublishDraft applies the draft to the live menu in one transaction and records the
+// result as a new menu version
+func (h *MenuDraftHandler) PublishDraft(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	var req models.MenuPublishRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	actor := currentActor(c)
+	var version *models.MenuVersion
+	var replacedImages []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockShopMenu(tx, shopID); err != nil {
+			return err
+		}
+
+		var draft models.MenuDraft
+		if err := tx.Where("coffee_shop_id = ?", shopID).First(&draft).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errNoMenuDraft
+			}
+			return err
+		}
+
+		// Keep the menu from before the first publish so it can be rolled back to
+		var count int64
+		if err := tx.Model(&models.MenuVersion{}).Where("coffee_shop_id = ?", shopID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			if _, err := recordMenuVersion(tx, shopID, "Menu before the first publish", nil, actor); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if replacedImages, err = applyMenuContent(tx, shopID, &draft.Changes, false, actor); err != nil {
+			return err
+		}
+		if version, err = recordMenuVersion(tx, shopID, req.Note, nil, actor); err != nil {
+			return err
+		}
+		return tx.Delete(&draft).Error
+	})
+	if errors.Is(err, errNoMenuDraft) {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "No menu draft",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to publish menu",
+		})
+	}
+
+	releaseUploads(replacedImages...)
+
+	publishShopEvent(events.MenuPublished, shopID, true, map[string]any{"version": version.Number})
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Menu published successfully",
+		Data:    version,
+	})
+}
+
+// GetMenuVersions lists the shop's published menu versions, newest first, without
+// their content
+func (h *MenuDraftHandler) GetMenuVersions(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	params, err := parseListParams(c, map[string]string{"number": "number"}, "-number")
+	if params == nil {
+		return err
+	}
+
+	var versions []models.MenuVersion
+	total, err := params.find(database.DB.Model(&models.MenuVersion{}).Where("coffee_shop_id = ?", shopID), &versions, omitContent)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve menu versions",
+		})
+	}
+
+	return c.JSON(http.StatusOK, listResponse(params, versions, total))
+}
+
+// GetMenuVersion returns a published menu version with its content
+func (h *MenuDraftHandler) GetMenuVersion(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid menu version ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+
+	var version models.MenuVersion
+	if err := database.DB.Where("id = ? AND coffee_shop_id = ?", uint(id), shopID).First(&version).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Menu version not found",
+		})
+	}
+
+	return c.JSON(http.StatusOK, version)
+}
+
+// RollbackMenuVersion makes a published version's content the live menu again in one
+// transaction: items created since are deleted and deleted ones restored. The result
+// is recorded as a new version; an open draft is kept.
+func (h *MenuDraftHandler) RollbackMenuVersion(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid menu version ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+
+	var req models.MenuPublishRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	actor := currentActor(c)
+	var version *models.MenuVersion
+	var replacedImages []string
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockShopMenu(tx, shopID); err != nil {
+			return err
+		}
+
+		var target models.MenuVersion
+		if err := tx.Where("id = ? AND coffee_shop_id = ?", uint(id), shopID).First(&target).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errMenuVersionNotFound
+			}
+			return err
+		}
+		if target.Content == nil {
+			return errMenuVersionNotFound
+		}
+
+		var err error
+		if replacedImages, err = applyMenuContent(tx, shopID, target.Content, true, actor); err != nil {
+			return err
+		}
+
+		note := req.Note
+		if note == "" {
+			note = fmt.Sprintf("Rolled back to version %d", target.Number)
+		}
+		version, err = recordMenuVersion(tx, shopID, note, &target.ID, actor)
+		return err
+	})
+	if errors.Is(err, errMenuVersionNotFound) {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Menu version not found",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to roll back menu",
+		})
+	}
+
+	releaseUploads(replacedImages...)
+
+	publishShopEvent(events.MenuPublished, shopID, true, map[string]any{
+		"version":       version.Number,
+		"restored_from": *version.RestoredFromID,
+	})
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Menu rolled back successfully",
+		Data:    version,
+	})
+}
+
+// updateMenuDraft changes the shop's draft under a row lock, starting the draft first
+// when the shop has none
+func updateMenuDraft(shopID uint, actor models.Actor, change func(tx *gorm.DB, changes *models.MenuContent) error) (*models.MenuDraft, error) {
+	var draft models.MenuDraft
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		start := models.MenuDraft{CoffeeShopID: shopID, Actor: actor}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&start).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("coffee_shop_id = ?", shopID).First(&draft).Error; err != nil {
+			return err
+		}
+		if err := change(tx, &draft.Changes); err != nil {
+			return err
+		}
+		return tx.Save(&draft).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// lockShopMenu serializes publishing and rolling back a shop's menu
+func lockShopMenu(tx *gorm.DB, shopID uint) error {
+	var coffeeShop models.CoffeeShop
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&coffeeShop, shopID).Error
+}
+
+// nextDraftItemKey returns an unused key for a new item of a draft
+func nextDraftItemKey(changes *models.MenuContent) string {
+	next := 1
+	for _, item := range changes.Items {
+		if n, err := strconv.Atoi(strings.TrimPrefix(item.Key, newDraftItemPrefix)); err == nil && strings.HasPrefix(item.Key, newDraftItemPrefix) && n >= next {
+			next = n + 1
+		}
+	}
+	return newDraftItemPrefix + strconv.Itoa(next)
+}
+
+// draftItem returns the draft's item with key, staging the live menu item first when
+// the draft does not change it yet
+func draftItem(tx *gorm.DB, shopID uint, changes *models.MenuContent, key string) (*models.MenuContentItem, error) {
+	for i := range changes.Items {
+		if changes.Items[i].Key == key {
+			return &changes.Items[i], nil
+		}
+	}
+
+	id, err := strconv.ParseUint(key, 10, 32)
+	if err != nil {
+		return nil, errDraftItemNotFound
+	}
+	var menuItem models.MenuItem
+	if err := tx.Preload("PriceTiers", orderByIndex).Where("id = ? AND coffee_shop_id = ?", uint(id), shopID).First(&menuItem).Error; err != nil {
+		return nil, errDraftItemNotFound
+	}
+
+	item := menuContentItem(&menuItem)
+	item.Key = key
+	changes.Items = append(changes.Items, item)
+	return &changes.Items[len(changes.Items)-1], nil
+}
+
+// draftCategory returns the draft's category with id, staging the shop's current view
+// of the category first when the draft does not change it yet
+func draftCategory(tx *gorm.DB, shopID uint, changes *models.MenuContent, id uint) (*models.MenuContentCategory, error) {
+	for i := range changes.Categories {
+		if changes.Categories[i].ID == id {
+			return &changes.Categories[i], nil
+		}
+	}
+
+	categories, err := effectiveCategories(tx, shopID, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range categories {
+		if categories[i].ID == id {
+			changes.Categories = append(changes.Categories, menuContentCategory(&categories[i]))
+			return &changes.Categories[len(changes.Categories)-1], nil
+		}
+	}
+	return nil, errDraftCategoryNotFound
+}
+
+// stageMenuItemUpdate applies an update request to a draft item. The legacy price
+// fields edit the first two tiers, as they do for live items. The request's dietary
+// info must have been checked.
+func stageMenuItemUpdate(item *models.MenuContentItem, req *models.MenuItemUpdateRequest) {
+	if req.Name != nil {
+		item.Name = *req.Name
+	}
+	if req.Description != nil {
+		item.Description = *req.Description
+	}
+	if req.CategoryID != nil {
+		item.CategoryID = *req.CategoryID
+	}
+	if req.ImageURL != nil {
+		item.ImageURL = *req.ImageURL
+	}
+	if req.OrderIndex != nil {
+		item.OrderIndex = *req.OrderIndex
+	}
+	item.DietaryInfo, _ = updatedDietaryInfo(item.DietaryInfo, req)
+
+	if req.PriceTiers != nil {
+		item.PriceTiers = contentPriceTiers(newPriceTiers(req.PriceTiers))
+		return
+	}
+	if req.Price != nil {
+		if len(item.PriceTiers) == 0 {
+			item.PriceTiers = append(item.PriceTiers, models.MenuContentPriceTier{Label: models.DefaultPriceTierLabel})
+		}
+		item.PriceTiers[0].Price = *req.Price
+	}
+	if req.HasDualPricing != nil && !*req.HasDualPricing && len(item.PriceTiers) > 1 {
+		item.PriceTiers = item.PriceTiers[:1]
+	}
+	if req.HasDualPricing != nil && *req.HasDualPricing && len(item.PriceTiers) == 1 {
+		premium := models.MenuContentPriceTier{Label: models.PremiumPriceTierLabel, Price: item.PriceTiers[0].Price}
+		item.PriceTiers = append(item.PriceTiers, premium)
+	}
+	if req.PricePremium != nil && len(item.PriceTiers) > 1 {
+		item.PriceTiers[1].Price = *req.PricePremium
+	}
+}
+
+// menuContentItem returns the content of a menu item; PriceTiers must be preloaded in order
+func menuContentItem(menuItem *models.MenuItem) models.MenuContentItem {
+	return models.MenuContentItem{
+		ID:          menuItem.ID,
+		Name:        menuItem.Name,
+		Description: menuItem.Description,
+		CategoryID:  menuItem.CategoryID,
+		ImageURL:    menuItem.ImageURL,
+		OrderIndex:  menuItem.OrderIndex,
+		PriceTiers:  contentPriceTiers(menuItem.PriceTiers),
+		DietaryInfo: menuItem.DietaryInfo,
+	}
+}
+
+func contentPriceTiers(tiers []models.MenuItemPriceTier) []models.MenuContentPriceTier {
+	content := make([]models.MenuContentPriceTier, 0, len(tiers))
+	for _, tier := range tiers {
+		content = append(content, models.MenuContentPriceTier{Label: tier.Label, Price: tier.Price})
+	}
+	return content
+}
+
+// menuContentCategory returns the content of a category as the shop shows it
+func menuContentCategory(category *models.Category) models.MenuContentCategory {
+	return models.MenuContentCategory{
+		ID:          category.ID,
+		DisplayName: category.DisplayName,
+		Emoji:       category.Emoji,
+		Color:       category.Color,
+		OrderIndex:  category.OrderIndex,
+		IsActive:    category.IsActive,
+	}
+}
+
+// applyContentToMenuItem sets a menu item's content fields, for previews
+func applyContentToMenuItem(menuItem *models.MenuItem, item *models.MenuContentItem) {
+	menuItem.Name = item.Name
+	menuItem.Description = item.Description
+	menuItem.DietaryInfo = item.DietaryInfo
+	menuItem.CategoryID = item.CategoryID
+	menuItem.ImageURL = item.ImageURL
+	menuItem.OrderIndex = item.OrderIndex
+	menuItem.PriceTiers = make([]models.MenuItemPriceTier, 0, len(item.PriceTiers))
+	for i, tier := range item.PriceTiers {
+		menuItem.PriceTiers = append(menuItem.PriceTiers, models.MenuItemPriceTier{
+			MenuItemID: menuItem.ID,
+			Label:      tier.Label,
+			Price:      tier.Price,
+			OrderIndex: i,
+		})
+	}
+	menuItem.SyncLegacyPrices()
+}
+
+// loadMenuContent returns the live content of the shop's menu
+func loadMenuContent(db *gorm.DB, shopID uint) (*models.MenuContent, error) {
+	var menuItems []models.MenuItem
+	if err := db.Preload("PriceTiers", orderByIndex).Where("coffee_shop_id = ?", shopID).Order("order_index ASC, id ASC").Find(&menuItems).Error; err != nil {
+		return nil, err
+	}
+	categories, err := effectiveCategories(db, shopID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &models.MenuContent{
+		Items:      make([]models.MenuContentItem, 0, len(menuItems)),
+		Categories: make([]models.MenuContentCategory, 0, len(categories)),
+	}
+	for i := range menuItems {
+		content.Items = append(content.Items, menuContentItem(&menuItems[i]))
+	}
+	for i := range categories {
+		content.Categories = append(content.Categories, menuContentCategory(&categories[i]))
+	}
+	return content, nil
+}
+
+// recordMenuVersion stores the live menu as the shop's next version
+func recordMenuVersion(tx *gorm.DB, shopID uint, note string, restoredFromID *uint, actor models.Actor) (*models.MenuVersion, error) {
+	content, err := loadMenuContent(tx, shopID)
+	if err != nil {
+		return nil, err
+	}
+
+	var last int
+	if err := tx.Model(&models.MenuVersion{}).Where("coffee_shop_id = ?", shopID).Select("COALESCE(MAX(number), 0)").Scan(&last).Error; err != nil {
+		return nil, err
+	}
+
+	version := &models.MenuVersion{
+		CoffeeShopID:   shopID,
+		Number:         last + 1,
+		Content:        content,
+		Note:           note,
+		RestoredFromID: restoredFromID,
+		Actor:          actor,
+	}
+	if err := tx.Create(version).Error; err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// applyMenuContent writes content to the live menu. With full set, content is the
+// whole menu and live items missing from it are deleted; otherwise only the items and
+// categories in content change. It returns the image URLs that are no longer used by
+// the changed items.
+func applyMenuContent(tx *gorm.DB, shopID uint, content *models.MenuContent, full bool, actor models.Actor) ([]string, error) {
+	for i := range content.Categories {
+		if err := applyMenuCategoryContent(tx, shopID, &content.Categories[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	var replacedImages []string
+	kept := make(map[uint]bool)
+	for i := range content.Items {
+		item := &content.Items[i]
+		if item.Deleted {
+			if item.ID != 0 {
+				imageURL, err := deleteContentMenuItem(tx, shopID, item.ID)
+				if err != nil {
+					return nil, err
+				}
+				replacedImages = append(replacedImages, imageURL)
+			}
+			continue
+		}
+
+		id, replacedImage, err := applyMenuItemContent(tx, shopID, item, actor)
+		if err != nil {
+			return nil, err
+		}
+		kept[id] = true
+		if replacedImage != "" {
+			replacedImages = append(replacedImages, replacedImage)
+		}
+	}
+
+	if full {
+		var liveIDs []uint
+		if err := tx.Model(&models.MenuItem{}).Where("coffee_shop_id = ?", shopID).Pluck("id", &liveIDs).Error; err != nil {
+			return nil, err
+		}
+		for _, id := range liveIDs {
+			if kept[id] {
+				continue
+			}
+			imageURL, err := deleteContentMenuItem(tx, shopID, id)
+			if err != nil {
+				return nil, err
+			}
+			replacedImages = append(replacedImages, imageURL)
+		}
+	}
+
+	return replacedImages, nil
+}
+
+// deleteContentMenuItem deletes a menu item and returns its image URL
+func deleteContentMenuItem(tx *gorm.DB, shopID, id uint) (string, error) {
+	var imageURLs []string
+	if err := tx.Model(&models.MenuItem{}).Where("id = ? AND coffee_shop_id = ?", id, shopID).Pluck("image_url", &imageURLs).Error; err != nil {
+		return "", err
+	}
+	if err := tx.Where("id = ? AND coffee_shop_id = ?", id, shopID).Delete(&models.MenuItem{}).Error; err != nil {
+		return "", err
+	}
+	if len(imageURLs) == 0 {
+		return "", nil
+	}
+	return imageURLs[0], nil
+}
+
+// applyMenuItemContent creates, restores or updates a menu item to match its content.
+// Tiers are matched by label, so tiers that stay keep their price history. It returns
+// the item's ID and the image URL it replaced, if any.
+func applyMenuItemContent(tx *gorm.DB, shopID uint, item *models.MenuContentItem, actor models.Actor) (uint, string, error) {
+	if item.ID == 0 {
+		menuItem := models.MenuItem{
+			CoffeeShopID: shopID,
+			IsAvailable:  true,
+		}
+		applyContentToMenuItem(&menuItem, item)
+		if err := tx.Create(&menuItem).Error; err != nil {
+			return 0, "", err
+		}
+		for i := range menuItem.PriceTiers {
+			if err := recordPriceChange(tx, &menuItem, &menuItem.PriceTiers[i], nil, models.PriceSourceCreated, actor, nil); err != nil {
+				return 0, "", err
+			}
+		}
+		return menuItem.ID, "", nil
+	}
+
+	var menuItem models.MenuItem
+	err := tx.Unscoped().Preload("PriceTiers", orderByIndex).Where("id = ? AND coffee_shop_id = ?", item.ID, shopID).First(&menuItem).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, "", nil
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	if menuItem.DeletedAt.Valid {
+		if err := tx.Unscoped().Model(&menuItem).Update("deleted_at", nil).Error; err != nil {
+			return 0, "", err
+		}
+		menuItem.DeletedAt = gorm.DeletedAt{}
+	}
+
+	oldImageURL := menuItem.ImageURL
+	menuItem.Name = item.Name
+	menuItem.Description = item.Description
+	menuItem.DietaryInfo = item.DietaryInfo
+	menuItem.ImageURL = item.ImageURL
+	menuItem.OrderIndex = item.OrderIndex
+	// Categories deleted since keep the item in its current category
+	if categoryAvailableToShop(item.CategoryID, shopID) {
+		menuItem.CategoryID = item.CategoryID
+	}
+
+	changed := len(item.PriceTiers) != len(menuItem.PriceTiers)
+	for i := 0; !changed && i < len(item.PriceTiers); i++ {
+		changed = item.PriceTiers[i].Label != menuItem.PriceTiers[i].Label || item.PriceTiers[i].Price != menuItem.PriceTiers[i].Price
+	}
+	if changed && len(item.PriceTiers) > 0 {
+		tierIDs := make(map[string]uint, len(menuItem.PriceTiers))
+		for _, tier := range menuItem.PriceTiers {
+			tierIDs[tier.Label] = tier.ID
+		}
+		reqs := make([]models.MenuItemPriceTierRequest, 0, len(item.PriceTiers))
+		for i, tier := range item.PriceTiers {
+			reqs = append(reqs, models.MenuItemPriceTierRequest{
+				ID:         tierIDs[tier.Label],
+				Label:      tier.Label,
+				Price:      tier.Price,
+				OrderIndex: i,
+			})
+			delete(tierIDs, tier.Label)
+		}
+		if err := savePriceTiers(tx, &menuItem, reqs, actor); err != nil {
+			return 0, "", err
+		}
+	}
+
+	// Counts are only written by inventory changes, which may run concurrently
+	if err := tx.Omit(clause.Associations, "StockQuantity").Save(&menuItem).Error; err != nil {
+		return 0, "", err
+	}
+
+	if menuItem.ImageURL != oldImageURL {
+		return menuItem.ID, oldImageURL, nil
+	}
+	return menuItem.ID, "", nil
+}
+
+// applyMenuCategoryContent updates a shop category, or the shop's override of a
+// template category, to match its content
+func applyMenuCategoryContent(tx *gorm.DB, shopID uint, content *models.MenuContentCategory) error {
+	var category models.Category
+	err := tx.Where("id = ? AND (coffee_shop_id IS NULL OR coffee_shop_id = ?)", content.ID, shopID).First(&category).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !category.IsTemplate() {
+		return tx.Model(&category).
+			Select("display_name", "emoji", "color", "order_index", "is_active").
+			Updates(models.Category{
+				DisplayName: content.DisplayName,
+				Emoji:       content.Emoji,
+				Color:       content.Color,
+				OrderIndex:  content.OrderIndex,
+				IsActive:    content.IsActive,
+			}).Error
+	}
+
+	var override models.CategoryOverride
+	err = tx.Where("coffee_shop_id = ? AND category_id = ?", shopID, category.ID).First(&override).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		override = models.CategoryOverride{CoffeeShopID: shopID, CategoryID: category.ID}
+	} else if err != nil {
+		return err
+	}
+
+	shown := override.Apply(category)
+	if menuContentCategory(&shown) == *content {
+		return nil
+	}
+	override.DisplayName = &content.DisplayName
+	if content.DisplayName == category.DisplayName {
+		// Showing the template's own name keeps its translations
+		override.DisplayName = nil
+	}
+	override.Emoji = &content.Emoji
+	override.Color = &content.Color
+	override.OrderIndex = &content.OrderIndex
+	override.IsActive = &content.IsActive
+	return tx.Save(&override).Error
+}
+
+// previewMenu builds the public menu as it would be with the draft changes applied
+func previewMenu(shopID uint, changes *models.MenuContent) (*models.MenuPreviewResponse, error) {
+	categories, err := effectiveCategories(database.DB, shopID, false)
+	if err != nil {
+		return nil, err
+	}
+	categoryChanges := make(map[uint]*models.MenuContentCategory, len(changes.Categories))
+	for i := range changes.Categories {
+		categoryChanges[changes.Categories[i].ID] = &changes.Categories[i]
+	}
+	visible := make([]models.Category, 0, len(categories))
+	categoryByID := make(map[uint]models.Category, len(categories))
+	for _, category := range categories {
+		if change, ok := categoryChanges[category.ID]; ok {
+			category.DisplayName = change.DisplayName
+			category.Emoji = change.Emoji
+			category.Color = change.Color
+			category.OrderIndex = change.OrderIndex
+			category.IsActive = change.IsActive
+		}
+		categoryByID[category.ID] = category
+		if category.IsActive {
+			visible = append(visible, category)
+		}
+	}
+	sort.SliceStable(visible, func(i, j int) bool {
+		if visible[i].OrderIndex != visible[j].OrderIndex {
+			return visible[i].OrderIndex < visible[j].OrderIndex
+		}
+		return visible[i].ID < visible[j].ID
+	})
+
+	var live []models.MenuItem
+	if err := database.DB.Scopes(withPublicMenuItemDetails).Where("coffee_shop_id = ? AND is_available = ?", shopID, true).Find(&live).Error; err != nil {
+		return nil, err
+	}
+
+	staged := make(map[string]*models.MenuContentItem, len(changes.Items))
+	for i := range changes.Items {
+		staged[changes.Items[i].Key] = &changes.Items[i]
+	}
+
+	menuItems := make([]models.MenuItem, 0, len(live))
+	for _, menuItem := range live {
+		if item, ok := staged[strconv.FormatUint(uint64(menuItem.ID), 10)]; ok {
+			if item.Deleted {
+				continue
+			}
+			applyContentToMenuItem(&menuItem, item)
+		}
+		menuItems = append(menuItems, menuItem)
+	}
+	for i := range changes.Items {
+		if item := &changes.Items[i]; item.ID == 0 && !item.Deleted {
+			menuItem := models.MenuItem{CoffeeShopID: shopID, IsAvailable: true}
+			applyContentToMenuItem(&menuItem, item)
+			menuItems = append(menuItems, menuItem)
+		}
+	}
+
+	availability, err := loadMenuAvailability(database.DB, shopID)
+	if err != nil {
+		return nil, err
+	}
+	available := make([]models.MenuItem, 0, len(menuItems))
+	for _, menuItem := range menuItems {
+		menuItem.Category = categoryByID[menuItem.CategoryID]
+		if availability.available(&menuItem) {
+			available = append(available, menuItem)
+		}
+	}
+	sort.SliceStable(available, func(i, j int) bool {
+		return available[i].OrderIndex < available[j].OrderIndex
+	})
+	withImages(available)
+
+	return &models.MenuPreviewResponse{
+		Categories: visible,
+		MenuItems:  available,
+	}, nil
+}
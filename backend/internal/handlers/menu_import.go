@@ -0,0 +1,769 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/events"
+	"coffee-shop-platform/internal/models"
+	"coffee-shop-platform/internal/spreadsheet"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxMenuImportBytes limits the size of imported menu files
+const maxMenuImportBytes = 5 << 20
+
+// maxMenuImportRows limits the number of menu items in an imported file
+const maxMenuImportRows = 2000
+
+// weekdayNames are the day names of the availability column, indexed by time.Weekday
+var weekdayNames = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+var (
+	errInvalidMenuFile   = errors.New("invalid menu file")
+	errMenuImportRejects = errors.New("menu import has rejected rows")
+)
+
+// ExportMenu returns the shop's menu items as CSV, or as XLSX with ?format=xlsx
+func (h *MenuHandler) ExportMenu(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = spreadsheet.FormatCSV
+	}
+	if format != spreadsheet.FormatCSV && format != spreadsheet.FormatXLSX {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid format",
+			Message: "format must be csv or xlsx",
+		})
+	}
+
+	var coffeeShop models.CoffeeShop
+	if err := database.DB.First(&coffeeShop, shopID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Coffee shop not found",
+		})
+	}
+
+	menuItems, err := loadSheetMenuItems(database.DB, shopID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to export menu",
+		})
+	}
+	categories, err := effectiveCategories(database.DB, shopID, false)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to export menu",
+		})
+	}
+	categoryNames := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Name
+	}
+
+	rows := [][]string{models.MenuColumns}
+	for i := range menuItems {
+		rows = append(rows, menuItemSheetRow(&menuItems[i], categoryNames))
+	}
+
+	var file bytes.Buffer
+	if err := spreadsheet.Write(&file, format, "Menu", rows); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to export menu",
+		})
+	}
+
+	filename := "menu." + format
+	if coffeeShop.Slug != "" {
+		filename = coffeeShop.Slug + "-" + filename
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.Blob(http.StatusOK, spreadsheet.ContentType(format), file.Bytes())
+}
+
+// ImportMenu creates and updates menu items from a CSV or XLSX file sent as the
+// multipart field "file", in the format of ExportMenu. Rows are matched to menu items
+// by id, or else by name. With ?dry_run=true only the report of what would change is
+// returned; otherwise the file is applied in one transaction, and only if no row is
+// rejected.
+func (h *MenuHandler) ImportMenu(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+	dryRun, _ := strconv.ParseBool(c.QueryParam("dry_run"))
+
+	data, err := readMenuImportFile(c)
+	if data == nil {
+		return err
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = spreadsheet.DetectFormat(data)
+	}
+	rows, err := spreadsheet.Read(data, format, spreadsheet.Limits{
+		Rows:    maxMenuImportRows + 1,
+		Columns: len(models.MenuColumns),
+	})
+	if errors.Is(err, spreadsheet.ErrTooLarge) {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid file",
+			Message: fmt.Sprintf("menu files may have at most %d items and the %d exported columns", maxMenuImportRows, len(models.MenuColumns)),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid file",
+			Message: "the file could not be read as csv or xlsx",
+		})
+	}
+
+	var plan *menuImport
+	if dryRun {
+		plan, err = planMenuImport(database.DB, shopID, rows)
+	} else {
+		actor := currentActor(c)
+		err = database.DB.Transaction(func(tx *gorm.DB) error {
+			plan, err = planMenuImport(tx, shopID, rows)
+			if err != nil {
+				return err
+			}
+			if len(plan.report.Reject) > 0 {
+				return errMenuImportRejects
+			}
+			return plan.apply(tx, actor)
+		})
+	}
+	if errors.Is(err, errInvalidMenuFile) {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid file",
+			Message: err.Error(),
+		})
+	}
+	if errors.Is(err, errMenuImportRejects) {
+		first := plan.report.Reject[0]
+		message := fmt.Sprintf("line %d: %s", first.Line, first.Errors[0])
+		if rejected := len(plan.report.Reject); rejected > 1 {
+			message += fmt.Sprintf(" (and %d more rejected rows; run a dry run to see them all)", rejected-1)
+		}
+		return c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "Import has invalid rows",
+			Message: message,
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to import menu",
+		})
+	}
+
+	plan.report.DryRun = dryRun
+	if dryRun {
+		return c.JSON(http.StatusOK, plan.report)
+	}
+
+	releaseUploads(plan.replacedImages...)
+
+	publishShopEvent(events.MenuImported, shopID, true, map[string]any{
+		"created": len(plan.report.Create),
+		"updated": len(plan.report.Update),
+	})
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Menu imported successfully",
+		Data:    plan.report,
+	})
+}
+
+// readMenuImportFile reads the multipart field "file"
+func readMenuImportFile(c echo.Context) ([]byte, error) {
+	tooLarge := func() error {
+		return c.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+			Error:   "File too large",
+			Message: fmt.Sprintf("files may be at most %d bytes", maxMenuImportBytes),
+		})
+	}
+
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxMenuImportBytes+multipartOverhead)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) || strings.Contains(err.Error(), "request body too large") {
+			return nil, tooLarge()
+		}
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid upload",
+			Message: "send the menu as the multipart form field \"file\"",
+		})
+	}
+	if fileHeader.Size > maxMenuImportBytes {
+		return nil, tooLarge()
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid upload",
+		})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxMenuImportBytes+1))
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid upload",
+		})
+	}
+	if len(data) > maxMenuImportBytes {
+		return nil, tooLarge()
+	}
+	return data, nil
+}
+
+// loadSheetMenuItems loads the shop's menu items with their price tiers and
+// availability windows in menu order
+func loadSheetMenuItems(db *gorm.DB, shopID uint) ([]models.MenuItem, error) {
+	var menuItems []models.MenuItem
+	err := db.Preload("PriceTiers", orderByIndex).
+		Preload("Availability", func(db *gorm.DB) *gorm.DB {
+			return db.Order("weekday ASC, starts_at ASC")
+		}).
+		Where("coffee_shop_id = ?", shopID).
+		Order("order_index ASC, id ASC").
+		Find(&menuItems).Error
+	return menuItems, err
+}
+
+// menuItemSheetRow formats a menu item as a row with models.MenuColumns
+func menuItemSheetRow(menuItem *models.MenuItem, categoryNames map[uint]string) []string {
+	id := ""
+	if menuItem.ID != 0 {
+		id = strconv.FormatUint(uint64(menuItem.ID), 10)
+	}
+	return []string{
+		id,
+		menuItem.Name,
+		categoryNames[menuItem.CategoryID],
+		formatPriceTiers(menuItem.PriceTiers),
+		menuItem.ImageURL,
+		strconv.Itoa(menuItem.OrderIndex),
+		strconv.FormatBool(menuItem.IsAvailable),
+		formatAvailability(menuItem.Availability),
+	}
+}
+
+// formatPriceTiers writes tiers as "Standard=45000; Premium=52000"
+func formatPriceTiers(tiers []models.MenuItemPriceTier) string {
+	parts := make([]string, 0, len(tiers))
+	for _, tier := range tiers {
+		parts = append(parts, fmt.Sprintf("%s=%d", tier.Label, tier.Price))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// parsePriceTiers reads tiers written by formatPriceTiers. A bare price is one tier
+// with the default label; an empty cell has no tiers.
+func parsePriceTiers(s string) ([]models.MenuItemPriceTierRequest, error) {
+	var parts []string
+	for _, part := range strings.Split(s, ";") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	var tiers []models.MenuItemPriceTierRequest
+	labels := make(map[string]bool)
+	for _, part := range parts {
+		label, priceText := models.DefaultPriceTierLabel, part
+		if i := strings.LastIndex(part, "="); i >= 0 {
+			label, priceText = strings.TrimSpace(part[:i]), part[i+1:]
+		} else if len(parts) > 1 {
+			return nil, fmt.Errorf("price tier %q must look like Label=Price", part)
+		}
+		if n := len([]rune(label)); n < 1 || n > 100 {
+			return nil, fmt.Errorf("price tier label %q must be 1 to 100 characters", label)
+		}
+		if labels[label] {
+			return nil, fmt.Errorf("price tier %q is listed twice", label)
+		}
+		labels[label] = true
+
+		price, err := parseSheetInt(priceText)
+		if err != nil || price < 0 {
+			return nil, fmt.Errorf("price of tier %q must be a whole number of at least 0", label)
+		}
+		tiers = append(tiers, models.MenuItemPriceTierRequest{
+			Label:      label,
+			Price:      price,
+			OrderIndex: len(tiers),
+		})
+	}
+	return tiers, nil
+}
+
+// formatAvailability writes windows grouped by time range, such as
+// "Mon-Fri 07:00-11:00; Sat,Sun 08:00-12:00". The output does not depend on the
+// order of the windows, so it can be compared.
+func formatAvailability(windows []models.AvailabilityWindow) string {
+	type group struct {
+		startsAt, endsAt string
+		days             [7]bool
+		first            time.Weekday
+	}
+	var groups []*group
+	bySpan := make(map[string]*group)
+	for _, window := range windows {
+		key := window.StartsAt + "-" + window.EndsAt
+		g, ok := bySpan[key]
+		if !ok {
+			g = &group{startsAt: window.StartsAt, endsAt: window.EndsAt, first: window.Weekday}
+			bySpan[key] = g
+			groups = append(groups, g)
+		}
+		g.days[window.Weekday] = true
+		if window.Weekday < g.first {
+			g.first = window.Weekday
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].first != groups[j].first {
+			return groups[i].first < groups[j].first
+		}
+		return groups[i].startsAt < groups[j].startsAt
+	})
+
+	parts := make([]string, 0, len(groups))
+	for _, g := range groups {
+		var days []string
+		for d := 0; d < 7; d++ {
+			if !g.days[d] {
+				continue
+			}
+			end := d
+			for end+1 < 7 && g.days[end+1] {
+				end++
+			}
+			switch {
+			case end-d >= 2:
+				days = append(days, weekdayNames[d]+"-"+weekdayNames[end])
+			case end > d:
+				days = append(days, weekdayNames[d], weekdayNames[end])
+			default:
+				days = append(days, weekdayNames[d])
+			}
+			d = end
+		}
+		parts = append(parts, fmt.Sprintf("%s %s-%s", strings.Join(days, ","), g.startsAt, g.endsAt))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// parseAvailability reads windows written by formatAvailability. Day ranges may wrap
+// around the week, such as Sat-Wed.
+func parseAvailability(s string) ([]models.AvailabilityWindowRequest, error) {
+	var reqs []models.AvailabilityWindowRequest
+	for _, part := range strings.Split(s, ";") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		span := strings.Split(fields[len(fields)-1], "-")
+		if len(fields) != 2 || len(span) != 2 {
+			return nil, fmt.Errorf("availability %q must look like Mon-Fri 07:00-11:00", strings.TrimSpace(part))
+		}
+
+		var weekdays []time.Weekday
+		seen := make(map[time.Weekday]bool)
+		for _, days := range strings.Split(fields[0], ",") {
+			bounds := strings.Split(days, "-")
+			if len(bounds) > 2 {
+				return nil, fmt.Errorf("invalid days %q", days)
+			}
+			from, err := parseWeekday(bounds[0])
+			if err != nil {
+				return nil, err
+			}
+			to := from
+			if len(bounds) == 2 {
+				if to, err = parseWeekday(bounds[1]); err != nil {
+					return nil, err
+				}
+			}
+			for d := from; ; d = (d + 1) % 7 {
+				if !seen[d] {
+					seen[d] = true
+					weekdays = append(weekdays, d)
+				}
+				if d == to {
+					break
+				}
+			}
+		}
+
+		reqs = append(reqs, models.AvailabilityWindowRequest{
+			Weekdays: weekdays,
+			StartsAt: span[0],
+			EndsAt:   span[1],
+		})
+	}
+	return reqs, nil
+}
+
+// parseWeekday reads a day name such as "Mon" or "monday"
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) >= 3 {
+		for i, name := range weekdayNames {
+			full := strings.ToLower(time.Weekday(i).String())
+			if strings.HasPrefix(full, s) && strings.HasPrefix(s, strings.ToLower(name)) {
+				return time.Weekday(i), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unknown day %q, expected Sun, Mon, Tue, Wed, Thu, Fri or Sat", s)
+}
+
+// parseSheetInt reads a whole number, allowing Persian and Arabic digits, thousands
+// separators and the decimal form spreadsheet programs may store numbers in
+func parseSheetInt(s string) (int, error) {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(s) {
+		switch {
+		case r >= '۰' && r <= '۹':
+			b.WriteRune('0' + r - '۰')
+		case r >= '٠' && r <= '٩':
+			b.WriteRune('0' + r - '٠')
+		case r == ',' || r == '٬' || r == '_' || r == ' ':
+		default:
+			b.WriteRune(r)
+		}
+	}
+	normalized := b.String()
+	if n, err := strconv.Atoi(normalized); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(normalized, 64)
+	if err != nil || f != math.Trunc(f) || math.Abs(f) > math.MaxInt32 {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return int(f), nil
+}
+
+// parseSheetBool reads true/false, yes/no or 1/0
+func parseSheetBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true", "yes", "1":
+		return true, nil
+	case "false", "no", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid value %q, expected true or false", s)
+}
+
+// validSheetURL accepts absolute http(s) URLs and paths on this server, such as
+// uploaded images
+func validSheetURL(s string) bool {
+	if strings.HasPrefix(s, "/") && !strings.HasPrefix(s, "//") {
+		return true
+	}
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// menuImportRow is a valid row that creates or updates a menu item
+type menuImportRow struct {
+	// existing is nil for rows that create a menu item
+	existing *models.MenuItem
+	// next is the menu item as the row leaves it
+	next  models.MenuItem
+	tiers []models.MenuItemPriceTierRequest
+}
+
+// menuImport is the plan of an import, made by planMenuImport
+type menuImport struct {
+	shopID         uint
+	report         models.MenuImportReport
+	rows           []*menuImportRow
+	replacedImages []string
+}
+
+// planMenuImport validates rows (the header first) against the shop's menu and
+// works out what each row changes
+func planMenuImport(db *gorm.DB, shopID uint, rows [][]string) (*menuImport, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: the file is empty", errInvalidMenuFile)
+	}
+
+	columns := make(map[string]int)
+	for i, header := range rows[0] {
+		header = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+		if _, dup := columns[header]; !dup {
+			columns[header] = i
+		}
+	}
+	var missing []string
+	for _, required := range []string{models.MenuColumnName, models.MenuColumnCategory, models.MenuColumnPriceTiers} {
+		if _, ok := columns[required]; !ok {
+			missing = append(missing, required)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: missing columns %s", errInvalidMenuFile, strings.Join(missing, ", "))
+	}
+
+	menuItems, err := loadSheetMenuItems(db, shopID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*models.MenuItem, len(menuItems))
+	byName := make(map[string][]*models.MenuItem)
+	for i := range menuItems {
+		menuItem := &menuItems[i]
+		byID[menuItem.ID] = menuItem
+		key := strings.ToLower(menuItem.Name)
+		byName[key] = append(byName[key], menuItem)
+	}
+
+	categories, err := effectiveCategories(db, shopID, false)
+	if err != nil {
+		return nil, err
+	}
+	categoryNames := make(map[uint]string, len(categories))
+	categoryByName := make(map[string]uint, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Name
+		categoryByName[strings.ToLower(category.Name)] = category.ID
+	}
+
+	plan := &menuImport{
+		shopID: shopID,
+		report: models.MenuImportReport{
+			Create: []models.MenuImportRow{},
+			Update: []models.MenuImportRow{},
+			Reject: []models.MenuImportRow{},
+		},
+	}
+	seenIDs := make(map[uint]int)
+	seenNames := make(map[string]int)
+
+	for i, cells := range rows[1:] {
+		line := i + 2
+		cell := func(column string) (string, bool) {
+			index, ok := columns[column]
+			if !ok {
+				return "", false
+			}
+			if index < len(cells) {
+				return strings.TrimSpace(cells[index]), true
+			}
+			return "", true
+		}
+		if strings.TrimSpace(strings.Join(cells, "")) == "" {
+			continue
+		}
+
+		report := models.MenuImportRow{Line: line}
+		reject := func(format string, args ...any) {
+			report.Errors = append(report.Errors, fmt.Sprintf(format, args...))
+		}
+		row := &menuImportRow{}
+
+		name, _ := cell(models.MenuColumnName)
+		report.Name = name
+		if n := len([]rune(name)); n < 2 || n > 100 {
+			reject("name must be 2 to 100 characters")
+		}
+
+		if idText, _ := cell(models.MenuColumnID); idText != "" {
+			id, err := parseSheetInt(idText)
+			if err != nil || id <= 0 || byID[uint(id)] == nil {
+				reject("menu item %s not found", idText)
+			} else {
+				row.existing = byID[uint(id)]
+			}
+		} else if matches := byName[strings.ToLower(name)]; len(matches) == 1 {
+			row.existing = matches[0]
+		} else if len(matches) > 1 {
+			reject("several menu items are named %q; fill in the id column", name)
+		}
+		if row.existing != nil {
+			report.MenuItemID = &row.existing.ID
+			if other, dup := seenIDs[row.existing.ID]; dup {
+				reject("menu item %d is also on line %d", row.existing.ID, other)
+			}
+			seenIDs[row.existing.ID] = line
+			row.next = *row.existing
+		} else {
+			if other, dup := seenNames[strings.ToLower(name)]; dup && name != "" {
+				reject("%q is also on line %d", name, other)
+			}
+			seenNames[strings.ToLower(name)] = line
+			row.next = models.MenuItem{CoffeeShopID: shopID, IsAvailable: true}
+		}
+		row.next.Name = name
+
+		categoryName, _ := cell(models.MenuColumnCategory)
+		if categoryID, ok := categoryByName[strings.ToLower(categoryName)]; ok {
+			row.next.CategoryID = categoryID
+		} else if categoryName == "" {
+			reject("category is required")
+		} else {
+			reject("unknown category %q", categoryName)
+		}
+
+		tiersText, _ := cell(models.MenuColumnPriceTiers)
+		if row.tiers, err = parsePriceTiers(tiersText); err != nil {
+			reject("%v", err)
+		} else if errs := checkMenuItemPricing(&models.MenuItemCreateRequest{PriceTiers: row.tiers}); errs != nil {
+			reject("%v", errs)
+		} else {
+			row.next.PriceTiers = newPriceTiers(row.tiers)
+			row.next.SyncLegacyPrices()
+		}
+
+		if imageURL, ok := cell(models.MenuColumnImageURL); ok {
+			if imageURL != "" && !validSheetURL(imageURL) {
+				reject("image_url %q is not a valid URL", imageURL)
+			}
+			row.next.ImageURL = imageURL
+		}
+
+		if orderText, _ := cell(models.MenuColumnOrderIndex); orderText != "" {
+			orderIndex, err := parseSheetInt(orderText)
+			if err != nil || orderIndex < 0 {
+				reject("order_index must be a whole number of at least 0")
+			}
+			row.next.OrderIndex = orderIndex
+		}
+
+		if availableText, _ := cell(models.MenuColumnIsAvailable); availableText != "" {
+			if row.next.IsAvailable, err = parseSheetBool(availableText); err != nil {
+				reject("is_available: %v", err)
+			}
+		}
+
+		if availabilityText, ok := cell(models.MenuColumnAvailability); ok {
+			reqs, err := parseAvailability(availabilityText)
+			if err == nil {
+				row.next.Availability, err = newAvailabilityWindows(shopID, reqs)
+			}
+			if err != nil {
+				reject("availability: %v", err)
+			}
+		}
+
+		if len(report.Errors) > 0 {
+			plan.report.Reject = append(plan.report.Reject, report)
+			continue
+		}
+
+		next := menuItemSheetRow(&row.next, categoryNames)
+		old := make([]string, len(next))
+		if row.existing != nil {
+			old = menuItemSheetRow(row.existing, categoryNames)
+		}
+		for j := 1; j < len(next); j++ {
+			if old[j] != next[j] {
+				report.Changes = append(report.Changes, models.MenuImportChange{
+					Field: models.MenuColumns[j],
+					Old:   old[j],
+					New:   next[j],
+				})
+			}
+		}
+
+		switch {
+		case row.existing == nil:
+			plan.report.Create = append(plan.report.Create, report)
+		case len(report.Changes) == 0:
+			plan.report.Unchanged++
+			continue
+		default:
+			plan.report.Update = append(plan.report.Update, report)
+		}
+		plan.rows = append(plan.rows, row)
+	}
+
+	return plan, nil
+}
+
+// apply makes the planned changes, recording price changes as made by actor
+func (p *menuImport) apply(tx *gorm.DB, actor models.Actor) error {
+	for _, row := range p.rows {
+		menuItem := row.next
+
+		if row.existing == nil {
+			if err := tx.Create(&menuItem).Error; err != nil {
+				return err
+			}
+			// is_available defaults to true, so Create writes true for a false IsAvailable
+			if !row.next.IsAvailable {
+				if err := tx.Model(&menuItem).Update("is_available", false).Error; err != nil {
+					return err
+				}
+			}
+			for i := range menuItem.PriceTiers {
+				if err := recordPriceChange(tx, &menuItem, &menuItem.PriceTiers[i], nil, models.PriceSourceCreated, actor, nil); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		existing := row.existing
+		if formatPriceTiers(menuItem.PriceTiers) != formatPriceTiers(existing.PriceTiers) {
+			// Keep the tiers whose label stays, so their price history continues
+			tierIDs := make(map[string]uint, len(existing.PriceTiers))
+			for _, tier := range existing.PriceTiers {
+				tierIDs[tier.Label] = tier.ID
+			}
+			reqs := make([]models.MenuItemPriceTierRequest, len(row.tiers))
+			for i, req := range row.tiers {
+				req.ID = tierIDs[req.Label]
+				reqs[i] = req
+			}
+			if err := savePriceTiers(tx, &menuItem, reqs, actor); err != nil {
+				return err
+			}
+		}
+
+		// Counts are only written by inventory changes, which may run concurrently
+		if err := tx.Omit(clause.Associations, "StockQuantity").Save(&menuItem).Error; err != nil {
+			return err
+		}
+
+		if formatAvailability(menuItem.Availability) != formatAvailability(existing.Availability) {
+			if err := tx.Where("menu_item_id = ?", menuItem.ID).Delete(&models.AvailabilityWindow{}).Error; err != nil {
+				return err
+			}
+			windows := menuItem.Availability
+			for i := range windows {
+				windows[i].ID = 0
+				windows[i].MenuItemID = &menuItem.ID
+			}
+			if len(windows) > 0 {
+				if err := tx.Create(&windows).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if menuItem.ImageURL != existing.ImageURL {
+			p.replacedImages = append(p.replacedImages, existing.ImageURL)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"coffee-shop-platform/internal/models"
+	"coffee-shop-platform/internal/spreadsheet"
+)
+
+func TestMenuSheetRoundTrip(t *testing.T) {
+	menuItem := models.MenuItem{
+		ID:          9,
+		Name:        "Omelette, \"house\" style",
+		CategoryID:  3,
+		ImageURL:    "/uploads/shops/1/omelette.jpg",
+		OrderIndex:  4,
+		IsAvailable: false,
+		PriceTiers: []models.MenuItemPriceTier{
+			{Label: "Standard", Price: 98000},
+			{Label: "Size=L", Price: 0},
+		},
+		Availability: []models.AvailabilityWindow{
+			{Weekday: time.Saturday, StartsAt: "07:00", EndsAt: "11:00"},
+			{Weekday: time.Sunday, StartsAt: "07:00", EndsAt: "11:00"},
+			{Weekday: time.Monday, StartsAt: "07:00", EndsAt: "11:00"},
+			{Weekday: time.Friday, StartsAt: "08:00", EndsAt: "12:00"},
+		},
+	}
+	row := menuItemSheetRow(&menuItem, map[uint]string{3: "breakfast"})
+
+	for _, format := range []string{spreadsheet.FormatCSV, spreadsheet.FormatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := spreadsheet.Write(&buf, format, "Menu", [][]string{models.MenuColumns, row}); err != nil {
+				t.Fatal(err)
+			}
+			rows, err := spreadsheet.Read(buf.Bytes(), format, spreadsheet.Limits{Rows: 2, Columns: len(models.MenuColumns)})
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if len(rows) != 2 || !reflect.DeepEqual(rows[0], models.MenuColumns) {
+				t.Fatalf("Read() = %q, want the header and one row", rows)
+			}
+			cells := rows[1]
+
+			if id, err := parseSheetInt(cells[0]); err != nil || uint(id) != menuItem.ID {
+				t.Errorf("id = %q, want %d", cells[0], menuItem.ID)
+			}
+			if cells[1] != menuItem.Name || cells[2] != "breakfast" || cells[4] != menuItem.ImageURL {
+				t.Errorf("name, category, image_url = %q, %q, %q", cells[1], cells[2], cells[4])
+			}
+			if orderIndex, err := parseSheetInt(cells[5]); err != nil || orderIndex != menuItem.OrderIndex {
+				t.Errorf("order_index = %q, want %d", cells[5], menuItem.OrderIndex)
+			}
+			if isAvailable, err := parseSheetBool(cells[6]); err != nil || isAvailable != menuItem.IsAvailable {
+				t.Errorf("is_available = %q, want %v", cells[6], menuItem.IsAvailable)
+			}
+
+			tiers, err := parsePriceTiers(cells[3])
+			if err != nil {
+				t.Fatalf("parsePriceTiers(%q) error = %v", cells[3], err)
+			}
+			wantTiers := []models.MenuItemPriceTierRequest{
+				{Label: "Standard", Price: 98000, OrderIndex: 0},
+				{Label: "Size=L", Price: 0, OrderIndex: 1},
+			}
+			if !reflect.DeepEqual(tiers, wantTiers) {
+				t.Errorf("price_tiers %q read back as %+v, want %+v", cells[3], tiers, wantTiers)
+			}
+
+			windows, err := parseAvailability(cells[7])
+			if err != nil {
+				t.Fatalf("parseAvailability(%q) error = %v", cells[7], err)
+			}
+			got := make(map[time.Weekday]string)
+			for _, window := range windows {
+				for _, day := range window.Weekdays {
+					got[day] = window.StartsAt + "-" + window.EndsAt
+				}
+			}
+			want := map[time.Weekday]string{
+				time.Saturday: "07:00-11:00",
+				time.Sunday:   "07:00-11:00",
+				time.Monday:   "07:00-11:00",
+				time.Friday:   "08:00-12:00",
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("availability %q read back as %v, want %v", cells[7], got, want)
+			}
+		})
+	}
+}
+
+func TestParsePriceTiers(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []models.MenuItemPriceTierRequest
+		wantErr bool
+	}{
+		{"empty", "  ", nil, false},
+		{"bare price", "45,000", []models.MenuItemPriceTierRequest{{Label: models.DefaultPriceTierLabel, Price: 45000}}, false},
+		{"labelled tiers", "Small=40000; Large = ۵۲۰۰۰ ;", []models.MenuItemPriceTierRequest{
+			{Label: "Small", Price: 40000},
+			{Label: "Large", Price: 52000, OrderIndex: 1},
+		}, false},
+		{"free tier", "Water=0", []models.MenuItemPriceTierRequest{{Label: "Water", Price: 0}}, false},
+		{"bare price among tiers", "Small=40000; 52000", nil, true},
+		{"duplicate label", "Small=1; Small=2", nil, true},
+		{"empty label", "=40000", nil, true},
+		{"negative price", "Small=-1", nil, true},
+		{"fractional price", "Small=1.5", nil, true},
+		{"not a number", "Small=free", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePriceTiers(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePriceTiers(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePriceTiers(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAvailability(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []models.AvailabilityWindowRequest
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"range", "Mon-Fri 07:00-11:00", []models.AvailabilityWindowRequest{
+			{Weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, StartsAt: "07:00", EndsAt: "11:00"},
+		}, false},
+		{"range across the weekend", "Fri-Sun 08:00-12:00", []models.AvailabilityWindowRequest{
+			{Weekdays: []time.Weekday{time.Friday, time.Saturday, time.Sunday}, StartsAt: "08:00", EndsAt: "12:00"},
+		}, false},
+		{"list, full names and repeats", "saturday,Sun,Sat 22:00-02:00; Wed 10:00-11:00", []models.AvailabilityWindowRequest{
+			{Weekdays: []time.Weekday{time.Saturday, time.Sunday}, StartsAt: "22:00", EndsAt: "02:00"},
+			{Weekdays: []time.Weekday{time.Wednesday}, StartsAt: "10:00", EndsAt: "11:00"},
+		}, false},
+		{"missing time", "Mon-Fri", nil, true},
+		{"missing days", "07:00-11:00", nil, true},
+		{"unknown day", "Mo 07:00-11:00", nil, true},
+		{"too many bounds", "Mon-Wed-Fri 07:00-11:00", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAvailability(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAvailability(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAvailability(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSheetInt(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"45000", 45000, false},
+		{" 45,000 ", 45000, false},
+		{"۴۵٬۰۰۰", 45000, false},
+		{"٤٥٠٠٠", 45000, false},
+		{"1_000", 1000, false},
+		{"45000.0", 45000, false},
+		{"4.5E4", 45000, false},
+		{"-3", -3, false},
+		{"45000.5", 0, true},
+		{"1e12", 0, true},
+		{"abc", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSheetInt(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseSheetInt(%q) = %d, %v, want %d, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/events"
+	"coffee-shop-platform/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type MenuOptionHandler struct{}
+
+func NewMenuOptionHandler() *MenuOptionHandler {
+	return &MenuOptionHandler{}
+}
+
+// findShopMenuItem loads the menu item from the :id param, scoped to the admin's shop
+func findShopMenuItem(c echo.Context) (*models.MenuItem, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid menu item ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+
+	var menuItem models.MenuItem
+	if err := database.DB.Where("id = ? AND coffee_shop_id = ?", uint(id), shopID).First(&menuItem).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Menu item not found",
+		})
+	}
+
+	return &menuItem, nil
+}
+
+// findOptionGroup loads the option group from the :groupId param, scoped to the menu item
+func findOptionGroup(c echo.Context, menuItemID uint) (*models.MenuOptionGroup, error) {
+	groupID, err := strconv.ParseUint(c.Param("groupId"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid option group ID",
+		})
+	}
+
+	var group models.MenuOptionGroup
+	if err := database.DB.Where("id = ? AND menu_item_id = ?", uint(groupID), menuItemID).First(&group).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Option group not found",
+		})
+	}
+
+	return &group, nil
+}
+
+// orderByIndex orders preloaded children (tiers, option groups, options) for display
+func orderByIndex(db *gorm.DB) *gorm.DB {
+	return db.Order("order_index ASC")
+}
+
+// GetOptionGroups lists the option groups of a menu item with their options
+func (h *MenuOptionHandler) GetOptionGroups(c echo.Context) error {
+	menuItem, err := findShopMenuItem(c)
+	if menuItem == nil {
+		return err
+	}
+
+	var groups []models.MenuOptionGroup
+	if err := database.DB.Preload("Options", orderByIndex).Where("menu_item_id = ?", menuItem.ID).Order("order_index ASC").Find(&groups).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve option groups",
+		})
+	}
+
+	return c.JSON(http.StatusOK, groups)
+}
+
+// CreateOptionGroup creates an option group, optionally with its options
+func (h *MenuOptionHandler) CreateOptionGroup(c echo.Context) error {
+	menuItem, err := findShopMenuItem(c)
+	if menuItem == nil {
+		return err
+	}
+
+	var req models.MenuOptionGroupCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if req.MaxSelect == 0 {
+		req.MaxSelect = 1
+	}
+	if req.MinSelect < 0 || req.MinSelect > req.MaxSelect {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "min_select must be between 0 and max_select",
+		})
+	}
+
+	group := models.MenuOptionGroup{
+		MenuItemID: menuItem.ID,
+		Name:       req.Name,
+		MinSelect:  req.MinSelect,
+		MaxSelect:  req.MaxSelect,
+		OrderIndex: req.OrderIndex,
+	}
+	for _, opt := range req.Options {
+		group.Options = append(group.Options, newMenuOption(opt))
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&group).Error; err != nil {
+			return err
+		}
+		for i := range group.Options {
+			if err := saveOptionAvailability(tx, &group.Options[i], req.Options[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to create option group",
+		})
+	}
+
+	publishMenuItemOptionsChanged(menuItem)
+
+	return c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Option group created successfully",
+		Data:    group,
+	})
+}
+
+// UpdateOptionGroup updates an option group's name and selection rules
+func (h *MenuOptionHandler) UpdateOptionGroup(c echo.Context) error {
+	menuItem, err := findShopMenuItem(c)
+	if menuItem == nil {
+		return err
+	}
+
+	group, err := findOptionGroup(c, menuItem.ID)
+	if group == nil {
+		return err
+	}
+
+	var req models.MenuOptionGroupUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if req.Name != nil {
+		group.Name = *req.Name
+	}
+	if req.MinSelect != nil {
+		group.MinSelect = *req.MinSelect
+	}
+	if req.MaxSelect != nil {
+		group.MaxSelect = *req.MaxSelect
+	}
+	if req.OrderIndex != nil {
+		group.OrderIndex = *req.OrderIndex
+	}
+
+	if group.MaxSelect < 1 || group.MinSelect < 0 || group.MinSelect > group.MaxSelect {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "min_select must be between 0 and max_select",
+		})
+	}
+
+	if err := database.DB.Save(group).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update option group",
+		})
+	}
+
+	publishMenuItemOptionsChanged(menuItem)
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Option group updated successfully",
+		Data:    group,
+	})
+}
+
+// DeleteOptionGroup deletes an option group and its options
+func (h *MenuOptionHandler) DeleteOptionGroup(c echo.Context) error {
+	menuItem, err := findShopMenuItem(c)
+	if menuItem == nil {
+		return err
+	}
+
+	group, err := findOptionGroup(c, menuItem.ID)
+	if group == nil {
+		return err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("option_group_id = ?", group.ID).Delete(&models.MenuOption{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(group).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to delete option group",
+		})
+	}
+
+	publishMenuItemOptionsChanged(menuItem)
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Option group deleted successfully",
+	})
+}
+
+// CreateOption adds an option to an option group
+func (h *MenuOptionHandler) CreateOption(c echo.Context) error {
+	menuItem, err := findShopMenuItem(c)
+	if menuItem == nil {
+		return err
+	}
+
+	group, err := findOptionGroup(c, menuItem.ID)
+	if group == nil {
+		return err
+	}
+
+	var req models.MenuOptionCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	option := newMenuOption(req)
+	option.OptionGroupID = group.ID
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&option).Error; err != nil {
+			return err
+		}
+		return saveOptionAvailability(tx, &option, req)
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to create option",
+		})
+	}
+
+	publishMenuItemOptionsChanged(menuItem)
+
+	return c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Option created successfully",
+		Data:    option,
+	})
+}
+
+// UpdateOption updates an option in an option group
+func (h *MenuOptionHandler) UpdateOption(c echo.Context) error {
+	menuItem, err := findShopMenuItem(c)
+	if menuItem == nil {
+		return err
+	}
+
+	group, err := findOptionGroup(c, menuItem.ID)
+	if group == nil {
+		return err
+	}
+
+	optionID, err := strconv.ParseUint(c.Param("optionId"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid option ID",
+		})
+	}
+
+	var req models.MenuOptionUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	var option models.MenuOption
+	if err := database.DB.Where("id = ? AND option_group_id = ?", uint(optionID), group.ID).First(&option).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Option not found",
+		})
+	}
+
+	if req.Name != nil {
+		option.Name = *req.Name
+	}
+	if req.PriceDelta != nil {
+		option.PriceDelta = *req.PriceDelta
+	}
+	if req.IsDefault != nil {
+		option.IsDefault = *req.IsDefault
+	}
+	if req.IsAvailable != nil {
+		option.IsAvailable = *req.IsAvailable
+	}
+	if req.OrderIndex != nil {
+		option.OrderIndex = *req.OrderIndex
+	}
+
+	if err := database.DB.Save(&option).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update option",
+		})
+	}
+
+	publishMenuItemOptionsChanged(menuItem)
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Option updated successfully",
+		Data:    option,
+	})
+}
+
+// DeleteOption removes an option from an option group
+func (h *MenuOptionHandler) DeleteOption(c echo.Context) error {
+	menuItem, err := findShopMenuItem(c)
+	if menuItem == nil {
+		return err
+	}
+
+	group, err := findOptionGroup(c, menuItem.ID)
+	if group == nil {
+		return err
+	}
+
+	optionID, err := strconv.ParseUint(c.Param("optionId"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid option ID",
+		})
+	}
+
+	if err := database.DB.Where("id = ? AND option_group_id = ?", uint(optionID), group.ID).Delete(&models.MenuOption{}).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to delete option",
+		})
+	}
+
+	publishMenuItemOptionsChanged(menuItem)
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Option deleted successfully",
+	})
+}
+
+func newMenuOption(req models.MenuOptionCreateRequest) models.MenuOption {
+	isAvailable := true
+	if req.IsAvailable != nil {
+		isAvailable = *req.IsAvailable
+	}
+
+	return models.MenuOption{
+		Name:        req.Name,
+		PriceDelta:  req.PriceDelta,
+		IsDefault:   req.IsDefault,
+		IsAvailable: isAvailable,
+		OrderIndex:  req.OrderIndex,
+	}
+}
+
+// saveOptionAvailability stores an option that req creates unavailable as such. The
+// column defaults to true, so Create writes true for a false IsAvailable.
+func saveOptionAvailability(tx *gorm.DB, option *models.MenuOption, req models.MenuOptionCreateRequest) error {
+	if req.IsAvailable == nil || *req.IsAvailable {
+		return nil
+	}
+	return tx.Model(option).Update("is_available", false).Error
+}
+
+// publishMenuItemOptionsChanged notifies subscribers that a menu item's options changed
+func publishMenuItemOptionsChanged(menuItem *models.MenuItem) {
+	publishMenuItemEvent(events.MenuItemUpdated, menuItem.CoffeeShopID, menuItem.ID)
+}
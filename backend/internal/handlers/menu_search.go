@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"unicode/utf8"
+
+	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/models"
+	"coffee-shop-platform/internal/search"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxSearchQueryLength is the longest search query accepted, in characters
+const maxSearchQueryLength = 100
+
+// Weights of the fields menu items are searched by
+const (
+	searchWeightName        = 3
+	searchWeightCategory    = 2
+	searchWeightDescription = 1
+)
+
+// SearchPublicMenu searches the public menu by ?q=, best matches first. The dietary
+// filters of the public menu apply.
+func (h *MenuHandler) SearchPublicMenu(c echo.Context) error {
+	coffeeShop, err := findPublicShop(c)
+	if coffeeShop == nil {
+		return err
+	}
+
+	query, err := parseSearchQuery(c)
+	if query == nil {
+		return err
+	}
+
+	filter, err := parseDietaryFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid filter",
+			Message: err.Error(),
+		})
+	}
+
+	available, err := publicMenuItems(coffeeShop.ID, filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to search menu items",
+		})
+	}
+
+	results, err := rankMenuItems(coffeeShop.ID, available, query)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to search menu items",
+		})
+	}
+	newMenuLocalizer(c, coffeeShop).menuItems(results)
+	withImages(results)
+
+	return c.JSON(http.StatusOK, results)
+}
+
+// SearchMenu searches all of the shop's menu items by ?q=, best matches first
+func (h *MenuHandler) SearchMenu(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	query, err := parseSearchQuery(c)
+	if query == nil {
+		return err
+	}
+
+	var menuItems []models.MenuItem
+	if err := database.DB.Scopes(withMenuItemDetails).Where("coffee_shop_id = ?", shopID).Order("order_index ASC").Find(&menuItems).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to search menu items",
+		})
+	}
+
+	results, err := rankMenuItems(shopID, menuItems, query)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to search menu items",
+		})
+	}
+
+	if availability, err := loadMenuAvailability(database.DB, shopID); err == nil {
+		availability.apply(results)
+	}
+	withImages(results)
+
+	return c.JSON(http.StatusOK, results)
+}
+
+// parseSearchQuery reads ?q=. When it is missing or too long, the error response has
+// been written.
+func parseSearchQuery(c echo.Context) (*search.Query, error) {
+	q := c.QueryParam("q")
+	if utf8.RuneCountInString(q) > maxSearchQueryLength {
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Search query is too long",
+		})
+	}
+
+	query := search.NewQuery(q)
+	if query.Empty() {
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Search query is required",
+		})
+	}
+	return &query, nil
+}
+
+// rankMenuItems returns the menu items that match query, best first and otherwise in
+// menu order. Names, category names and descriptions are searched in every language,
+// with categories as the shop shows them.
+func rankMenuItems(shopID uint, menuItems []models.MenuItem, query *search.Query) ([]models.MenuItem, error) {
+	categories, err := effectiveCategories(database.DB, shopID, false)
+	if err != nil {
+		return nil, err
+	}
+	categoryByID := make(map[uint]*models.Category, len(categories))
+	for i := range categories {
+		categoryByID[categories[i].ID] = &categories[i]
+	}
+
+	type result struct {
+		menuItem models.MenuItem
+		score    float64
+	}
+	var results []result
+	for _, menuItem := range menuItems {
+		fields := translatedFields(menuItem.Name, menuItem.Translations, models.FieldName, searchWeightName)
+		fields = append(fields, translatedFields(menuItem.Description, menuItem.Translations, models.FieldDescription, searchWeightDescription)...)
+		for _, ingredient := range menuItem.Ingredients {
+			fields = append(fields, search.Field{Text: ingredient, Weight: searchWeightDescription})
+		}
+		if category, ok := categoryByID[menuItem.CategoryID]; ok {
+			fields = append(fields, search.Field{Text: category.Name, Weight: searchWeightCategory})
+			fields = append(fields, translatedFields(category.DisplayName, category.Translations, models.FieldDisplayName, searchWeightCategory)...)
+		}
+
+		if score := query.Score(fields...); score > 0 {
+			results = append(results, result{menuItem: menuItem, score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+
+	ranked := make([]models.MenuItem, 0, len(results))
+	for _, r := range results {
+		ranked = append(ranked, r.menuItem)
+	}
+	return ranked, nil
+}
+
+// translatedFields returns a text and its translations into every language as search
+// fields
+func translatedFields(text string, translations models.Translations, field string, weight float64) []search.Field {
+	fields := []search.Field{{Text: text, Weight: weight}}
+	for language := range translations {
+		if translated := translations.Text(language, field); translated != "" {
+			fields = append(fields, search.Field{Text: translated, Weight: weight})
+		}
+	}
+	return fields
+}
@@ -0,0 +1,775 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/events"
+	"coffee-shop-platform/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errMenuTemplateNotFound = errors.New("menu template not found")
+	errMenuItemsNotFound    = errors.New("menu items not found")
+)
+
+type MenuTemplateHandler struct{}
+
+func NewMenuTemplateHandler() *MenuTemplateHandler {
+	return &MenuTemplateHandler{}
+}
+
+// GetMenuTemplates lists the menu templates without their content, filtered by ?q=
+// on the name
+func (h *MenuTemplateHandler) GetMenuTemplates(c echo.Context) error {
+	params, err := parseListParams(c, map[string]string{"name": "name", "created_at": "created_at"}, "name")
+	if params == nil {
+		return err
+	}
+
+	var menuTemplates []models.MenuTemplate
+	total, err := params.find(filterName(c, database.DB.Model(&models.MenuTemplate{}), "name"), &menuTemplates, omitContent)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve menu templates",
+		})
+	}
+
+	return c.JSON(http.StatusOK, listResponse(params, menuTemplates, total))
+}
+
+// omitContent leaves the content out of menu version and template lists
+func omitContent(db *gorm.DB) *gorm.DB {
+	return db.Omit("content")
+}
+
+// CreateMenuTemplate saves a shop's menu as a template
+func (h *MenuTemplateHandler) CreateMenuTemplate(c echo.Context) error {
+	var req models.MenuTemplateCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	var coffeeShop models.CoffeeShop
+	if err := database.DB.Select("id").First(&coffeeShop, req.ShopID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Coffee shop not found",
+		})
+	}
+
+	if menuTemplateNameTaken(req.Name, 0) {
+		return c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error: "Template name already exists",
+		})
+	}
+
+	menuTemplate, err := SaveMenuTemplate(database.DB, coffeeShop.ID, req.Name, req.Description)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to create menu template",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Menu template created successfully",
+		Data:    menuTemplate,
+	})
+}
+
+// GetMenuTemplate returns a menu template with its content
+func (h *MenuTemplateHandler) GetMenuTemplate(c echo.Context) error {
+	menuTemplate, err := findMenuTemplate(c)
+	if menuTemplate == nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, menuTemplate)
+}
+
+// UpdateMenuTemplate renames a menu template. Its content stays as saved; save the
+// shop again as a new template to take later changes.
+func (h *MenuTemplateHandler) UpdateMenuTemplate(c echo.Context) error {
+	menuTemplate, err := findMenuTemplate(c)
+	if menuTemplate == nil {
+		return err
+	}
+
+	var req models.MenuTemplateUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if req.Name != nil {
+		if menuTemplateNameTaken(*req.Name, menuTemplate.ID) {
+			return c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error: "Template name already exists",
+			})
+		}
+		menuTemplate.Name = *req.Name
+	}
+	if req.Description != nil {
+		menuTemplate.Description = *req.Description
+	}
+
+	if err := database.DB.Model(menuTemplate).Select("name", "description").Updates(menuTemplate).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update menu template",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Menu template updated successfully",
+		Data:    menuTemplate,
+	})
+}
+
+// DeleteMenuTemplate deletes a menu template. Shops it was applied to keep their menus.
+func (h *MenuTemplateHandler) DeleteMenuTemplate(c echo.Context) error {
+	menuTemplate, err := findMenuTemplate(c)
+	if menuTemplate == nil {
+		return err
+	}
+
+	if err := database.DB.Delete(menuTemplate).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to delete menu template",
+		})
+	}
+
+	var imageURLs []string
+	for _, item := range menuTemplate.Content.Items {
+		imageURLs = append(imageURLs, item.ImageURL)
+	}
+	releaseUploads(imageURLs...)
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Menu template deleted successfully",
+	})
+}
+
+// ApplyMenuTemplate applies a template to a shop of any tenant, merging it into the
+// shop's menu or replacing the menu
+func (h *MenuTemplateHandler) ApplyMenuTemplate(c echo.Context) error {
+	menuTemplate, err := findMenuTemplate(c)
+	if menuTemplate == nil {
+		return err
+	}
+
+	var req models.MenuTemplateApplyRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+	if req.Mode == "" {
+		req.Mode = models.MenuTemplateMerge
+	}
+	if req.Mode != models.MenuTemplateMerge && req.Mode != models.MenuTemplateReplace {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid mode",
+			Message: "mode must be merge or replace",
+		})
+	}
+
+	var report *models.MenuTemplateApplyReport
+	var replacedImages []string
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		report, replacedImages, err = applyMenuTemplate(tx, req.ShopID, menuTemplate.Content, req.Mode, currentActor(c))
+		return err
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Coffee shop not found",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to apply menu template",
+		})
+	}
+
+	releaseUploads(replacedImages...)
+
+	publishShopEvent(events.MenuCopied, req.ShopID, true, map[string]any{
+		"template_id": menuTemplate.ID,
+		"report":      report,
+	})
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Menu template applied successfully",
+		Data:    report,
+	})
+}
+
+// CopyMenuItems copies menu items from another shop, with their categories, prices,
+// options and images. Items the shop has by the same name are updated.
+func (h *MenuTemplateHandler) CopyMenuItems(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid coffee shop ID",
+		})
+	}
+
+	var req models.MenuCopyRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+	if len(req.MenuItemIDs) == 0 {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "menu_item_ids is required",
+		})
+	}
+	if req.SourceShopID == uint(id) {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Cannot copy menu items into the same shop",
+		})
+	}
+
+	var report *models.MenuTemplateApplyReport
+	var replacedImages []string
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		content, err := loadMenuTemplateContent(tx, req.SourceShopID, req.MenuItemIDs)
+		if err != nil {
+			return err
+		}
+		if len(content.Items) != len(req.MenuItemIDs) {
+			return errMenuItemsNotFound
+		}
+		report, replacedImages, err = applyMenuTemplate(tx, uint(id), content, models.MenuTemplateMerge, currentActor(c))
+		return err
+	})
+	if errors.Is(err, errMenuItemsNotFound) {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Menu item not found",
+			Message: "every menu item must belong to the source shop",
+		})
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Coffee shop not found",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to copy menu items",
+		})
+	}
+
+	releaseUploads(replacedImages...)
+
+	publishShopEvent(events.MenuCopied, uint(id), true, map[string]any{
+		"source_shop_id": req.SourceShopID,
+		"report":         report,
+	})
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Menu items copied successfully",
+		Data:    report,
+	})
+}
+
+// findMenuTemplate loads the template named by the :id parameter, or responds with an error
+func findMenuTemplate(c echo.Context) (*models.MenuTemplate, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid menu template ID",
+		})
+	}
+
+	var menuTemplate models.MenuTemplate
+	if err := database.DB.First(&menuTemplate, uint(id)).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Menu template not found",
+		})
+	}
+	if menuTemplate.Content == nil {
+		menuTemplate.Content = &models.MenuTemplateContent{}
+	}
+
+	return &menuTemplate, nil
+}
+
+func menuTemplateNameTaken(name string, exceptID uint) bool {
+	var count int64
+	database.DB.Model(&models.MenuTemplate{}).Where("name = ? AND id <> ?", name, exceptID).Count(&count)
+	return count > 0
+}
+
+// SaveMenuTemplate saves a shop's whole menu as a new template
+func SaveMenuTemplate(db *gorm.DB, shopID uint, name, description string) (*models.MenuTemplate, error) {
+	content, err := loadMenuTemplateContent(db, shopID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	menuTemplate := models.MenuTemplate{
+		Name:         name,
+		Description:  description,
+		SourceShopID: &shopID,
+		ItemCount:    len(content.Items),
+		Content:      content,
+	}
+	if err := db.Create(&menuTemplate).Error; err != nil {
+		return nil, err
+	}
+	return &menuTemplate, nil
+}
+
+// loadMenuTemplateContent returns a shop's menu as template content. With itemIDs,
+// only those items and their categories are included.
+func loadMenuTemplateContent(db *gorm.DB, shopID uint, itemIDs []uint) (*models.MenuTemplateContent, error) {
+	query := db.Scopes(withMenuItemDetails).Where("coffee_shop_id = ?", shopID)
+	if itemIDs != nil {
+		query = query.Where("id IN ?", itemIDs)
+	}
+	var menuItems []models.MenuItem
+	if err := query.Order("order_index ASC, id ASC").Find(&menuItems).Error; err != nil {
+		return nil, err
+	}
+
+	categories, err := effectiveCategories(db, shopID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []models.AvailabilityWindow
+	if err := db.Where("coffee_shop_id = ? AND category_id IS NOT NULL", shopID).Find(&windows).Error; err != nil {
+		return nil, err
+	}
+	categoryWindows := make(map[uint][]models.AvailabilityWindow)
+	for _, window := range windows {
+		categoryWindows[*window.CategoryID] = append(categoryWindows[*window.CategoryID], window)
+	}
+
+	used := make(map[uint]bool, len(menuItems))
+	for _, menuItem := range menuItems {
+		used[menuItem.CategoryID] = true
+	}
+
+	content := &models.MenuTemplateContent{
+		Categories: []models.MenuTemplateCategory{},
+		Items:      make([]models.MenuTemplateItem, 0, len(menuItems)),
+	}
+	categoryNames := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Name
+		if itemIDs != nil && !used[category.ID] {
+			continue
+		}
+		content.Categories = append(content.Categories, models.MenuTemplateCategory{
+			Name:         category.Name,
+			DisplayName:  category.DisplayName,
+			Emoji:        category.Emoji,
+			Color:        category.Color,
+			OrderIndex:   category.OrderIndex,
+			IsActive:     category.IsActive,
+			Translations: category.Translations,
+			Availability: availabilityRequests(categoryWindows[category.ID]),
+		})
+	}
+
+	for _, menuItem := range menuItems {
+		categoryName, ok := categoryNames[menuItem.CategoryID]
+		if !ok {
+			continue
+		}
+		content.Items = append(content.Items, models.MenuTemplateItem{
+			Name:         menuItem.Name,
+			Description:  menuItem.Description,
+			Translations: menuItem.Translations,
+			Category:     categoryName,
+			ImageURL:     menuItem.ImageURL,
+			OrderIndex:   menuItem.OrderIndex,
+			IsAvailable:  menuItem.IsAvailable,
+			PriceTiers:   contentPriceTiers(menuItem.PriceTiers),
+			OptionGroups: optionGroupRequests(menuItem.OptionGroups),
+			Availability: availabilityRequests(menuItem.Availability),
+			DietaryInfo:  menuItem.DietaryInfo,
+		})
+	}
+	return content, nil
+}
+
+// availabilityRequests groups windows with the same times into one request per time
+// range, the inverse of newAvailabilityWindows
+func availabilityRequests(windows []models.AvailabilityWindow) []models.AvailabilityWindowRequest {
+	sorted := append([]models.AvailabilityWindow(nil), windows...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Weekday != sorted[j].Weekday {
+			return sorted[i].Weekday < sorted[j].Weekday
+		}
+		return sorted[i].StartsAt < sorted[j].StartsAt
+	})
+
+	var reqs []models.AvailabilityWindowRequest
+	index := make(map[[2]string]int)
+	for _, window := range sorted {
+		span := [2]string{window.StartsAt, window.EndsAt}
+		i, ok := index[span]
+		if !ok {
+			i = len(reqs)
+			index[span] = i
+			reqs = append(reqs, models.AvailabilityWindowRequest{StartsAt: window.StartsAt, EndsAt: window.EndsAt})
+		}
+		reqs[i].Weekdays = append(reqs[i].Weekdays, window.Weekday)
+	}
+	return reqs
+}
+
+// optionGroupRequests returns option groups as the requests that create them
+func optionGroupRequests(groups []models.MenuOptionGroup) []models.MenuOptionGroupCreateRequest {
+	var reqs []models.MenuOptionGroupCreateRequest
+	for _, group := range groups {
+		req := models.MenuOptionGroupCreateRequest{
+			Name:       group.Name,
+			MinSelect:  group.MinSelect,
+			MaxSelect:  group.MaxSelect,
+			OrderIndex: group.OrderIndex,
+		}
+		for _, option := range group.Options {
+			isAvailable := option.IsAvailable
+			req.Options = append(req.Options, models.MenuOptionCreateRequest{
+				Name:        option.Name,
+				PriceDelta:  option.PriceDelta,
+				IsDefault:   option.IsDefault,
+				IsAvailable: &isAvailable,
+				OrderIndex:  option.OrderIndex,
+			})
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs
+}
+
+// applyMenuTemplate applies template content to a shop. Categories are matched by
+// name and items by name within the shop; see models.MenuTemplateMerge and
+// models.MenuTemplateReplace. It returns the image URLs the shop no longer uses.
+func applyMenuTemplate(tx *gorm.DB, shopID uint, content *models.MenuTemplateContent, mode string, actor models.Actor) (*models.MenuTemplateApplyReport, []string, error) {
+	if err := lockShopMenu(tx, shopID); err != nil {
+		return nil, nil, err
+	}
+
+	report := &models.MenuTemplateApplyReport{Mode: mode}
+	categoryIDs, err := applyTemplateCategories(tx, shopID, content.Categories, mode, report)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var existing []models.MenuItem
+	if err := tx.Scopes(withMenuItemDetails).Where("coffee_shop_id = ?", shopID).Order("order_index ASC, id ASC").Find(&existing).Error; err != nil {
+		return nil, nil, err
+	}
+	byName := make(map[string]*models.MenuItem, len(existing))
+	for i := range existing {
+		if _, ok := byName[existing[i].Name]; !ok {
+			byName[existing[i].Name] = &existing[i]
+		}
+	}
+
+	var replacedImages []string
+	kept := make(map[uint]bool)
+	for i := range content.Items {
+		item := &content.Items[i]
+		categoryID, ok := categoryIDs[item.Category]
+		if !ok || len(item.PriceTiers) == 0 {
+			continue
+		}
+
+		menuItem, ok := byName[item.Name]
+		if !ok {
+			if err := createTemplateMenuItem(tx, shopID, categoryID, item, actor); err != nil {
+				return nil, nil, err
+			}
+			report.Created++
+			continue
+		}
+
+		kept[menuItem.ID] = true
+		oldImageURL := menuItem.ImageURL
+		changed, err := updateTemplateMenuItem(tx, menuItem, categoryID, item, actor)
+		if err != nil {
+			return nil, nil, err
+		}
+		if changed {
+			report.Updated++
+		}
+		if menuItem.ImageURL != oldImageURL {
+			replacedImages = append(replacedImages, oldImageURL)
+		}
+	}
+
+	if mode == models.MenuTemplateReplace {
+		for i := range existing {
+			if kept[existing[i].ID] {
+				continue
+			}
+			if err := tx.Delete(&existing[i]).Error; err != nil {
+				return nil, nil, err
+			}
+			replacedImages = append(replacedImages, existing[i].ImageURL)
+			report.Deleted++
+		}
+	}
+
+	return report, replacedImages, nil
+}
+
+// applyTemplateCategories finds or creates the shop's category of each template
+// category and returns their IDs by name. Settings of existing categories are only
+// taken over when replacing.
+func applyTemplateCategories(tx *gorm.DB, shopID uint, categories []models.MenuTemplateCategory, mode string, report *models.MenuTemplateApplyReport) (map[string]uint, error) {
+	var existing []models.Category
+	if err := tx.Where("coffee_shop_id IS NULL OR coffee_shop_id = ?", shopID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*models.Category, len(existing))
+	for i := range existing {
+		// A shop category never shadows a template of the same name
+		if current, ok := byName[existing[i].Name]; !ok || !current.IsTemplate() {
+			byName[existing[i].Name] = &existing[i]
+		}
+	}
+
+	categoryIDs := make(map[string]uint, len(categories))
+	for _, templateCategory := range categories {
+		category, ok := byName[templateCategory.Name]
+		if ok && mode != models.MenuTemplateReplace {
+			categoryIDs[templateCategory.Name] = category.ID
+			continue
+		}
+
+		if ok {
+			settings := models.MenuContentCategory{
+				ID:          category.ID,
+				DisplayName: templateCategory.DisplayName,
+				Emoji:       templateCategory.Emoji,
+				Color:       templateCategory.Color,
+				OrderIndex:  templateCategory.OrderIndex,
+				IsActive:    templateCategory.IsActive,
+			}
+			if err := applyMenuCategoryContent(tx, shopID, &settings); err != nil {
+				return nil, err
+			}
+			if err := applyTemplateCategoryTranslations(tx, shopID, category, templateCategory.Translations); err != nil {
+				return nil, err
+			}
+		} else {
+			category = &models.Category{
+				CoffeeShopID: &shopID,
+				Name:         templateCategory.Name,
+				DisplayName:  templateCategory.DisplayName,
+				Translations: templateCategory.Translations,
+				Emoji:        templateCategory.Emoji,
+				Color:        templateCategory.Color,
+				OrderIndex:   templateCategory.OrderIndex,
+				IsActive:     true,
+			}
+			if err := tx.Create(category).Error; err != nil {
+				return nil, err
+			}
+			if !templateCategory.IsActive {
+				if err := tx.Model(category).Update("is_active", false).Error; err != nil {
+					return nil, err
+				}
+			}
+			report.CategoriesCreated++
+		}
+
+		if err := tx.Where("coffee_shop_id = ? AND category_id = ?", shopID, category.ID).Delete(&models.AvailabilityWindow{}).Error; err != nil {
+			return nil, err
+		}
+		if err := createTemplateWindows(tx, shopID, nil, &category.ID, templateCategory.Availability); err != nil {
+			return nil, err
+		}
+		categoryIDs[templateCategory.Name] = category.ID
+	}
+	return categoryIDs, nil
+}
+
+// applyTemplateCategoryTranslations makes a category's display name translations the
+// template's. Template categories get them through the shop's override.
+func applyTemplateCategoryTranslations(tx *gorm.DB, shopID uint, category *models.Category, translations models.Translations) error {
+	if !category.IsTemplate() {
+		return tx.Model(category).Update("translations", translations).Error
+	}
+
+	var override models.CategoryOverride
+	err := tx.Where("coffee_shop_id = ? AND category_id = ?", shopID, category.ID).First(&override).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		override = models.CategoryOverride{CoffeeShopID: shopID, CategoryID: category.ID}
+	} else if err != nil {
+		return err
+	}
+
+	shown := override.Apply(*category)
+	if shown.Translations.Equal(translations) {
+		return nil
+	}
+	override.Translations = translations
+	return tx.Save(&override).Error
+}
+
+// createTemplateMenuItem creates a menu item of a template with its options and windows
+func createTemplateMenuItem(tx *gorm.DB, shopID, categoryID uint, item *models.MenuTemplateItem, actor models.Actor) error {
+	menuItem := models.MenuItem{
+		CoffeeShopID: shopID,
+		CategoryID:   categoryID,
+		Name:         item.Name,
+		Description:  item.Description,
+		Translations: item.Translations,
+		DietaryInfo:  item.DietaryInfo,
+		ImageURL:     item.ImageURL,
+		OrderIndex:   item.OrderIndex,
+		IsAvailable:  true,
+	}
+	for i, tier := range item.PriceTiers {
+		menuItem.PriceTiers = append(menuItem.PriceTiers, models.MenuItemPriceTier{
+			Label:      tier.Label,
+			Price:      tier.Price,
+			OrderIndex: i,
+		})
+	}
+	menuItem.SyncLegacyPrices()
+
+	if err := tx.Create(&menuItem).Error; err != nil {
+		return err
+	}
+	for i := range menuItem.PriceTiers {
+		if err := recordPriceChange(tx, &menuItem, &menuItem.PriceTiers[i], nil, models.PriceSourceCreated, actor, nil); err != nil {
+			return err
+		}
+	}
+	if !item.IsAvailable {
+		if err := tx.Model(&menuItem).Update("is_available", false).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := createTemplateOptionGroups(tx, menuItem.ID, item.OptionGroups); err != nil {
+		return err
+	}
+	return createTemplateWindows(tx, shopID, &menuItem.ID, nil, item.Availability)
+}
+
+// updateTemplateMenuItem updates a shop's menu item to match a template item. Its
+// availability switch and stock are left alone. It reports whether anything changed.
+func updateTemplateMenuItem(tx *gorm.DB, menuItem *models.MenuItem, categoryID uint, item *models.MenuTemplateItem, actor models.Actor) (bool, error) {
+	changed := false
+
+	if !reflect.DeepEqual(contentPriceTiers(menuItem.PriceTiers), item.PriceTiers) {
+		// Keep the tiers whose label stays, so their price history continues
+		tierIDs := make(map[string]uint, len(menuItem.PriceTiers))
+		for _, tier := range menuItem.PriceTiers {
+			tierIDs[tier.Label] = tier.ID
+		}
+		reqs := make([]models.MenuItemPriceTierRequest, 0, len(item.PriceTiers))
+		for i, tier := range item.PriceTiers {
+			reqs = append(reqs, models.MenuItemPriceTierRequest{
+				ID:         tierIDs[tier.Label],
+				Label:      tier.Label,
+				Price:      tier.Price,
+				OrderIndex: i,
+			})
+			delete(tierIDs, tier.Label)
+		}
+		if err := savePriceTiers(tx, menuItem, reqs, actor); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	if menuItem.CategoryID != categoryID || menuItem.Description != item.Description || !menuItem.Translations.Equal(item.Translations) ||
+		!reflect.DeepEqual(menuItem.DietaryInfo, item.DietaryInfo) || menuItem.ImageURL != item.ImageURL || menuItem.OrderIndex != item.OrderIndex {
+		menuItem.CategoryID = categoryID
+		menuItem.Description = item.Description
+		menuItem.Translations = item.Translations
+		menuItem.DietaryInfo = item.DietaryInfo
+		menuItem.ImageURL = item.ImageURL
+		menuItem.OrderIndex = item.OrderIndex
+		changed = true
+	}
+	if changed {
+		// Counts are only written by inventory changes, which may run concurrently
+		if err := tx.Omit(clause.Associations, "StockQuantity").Save(menuItem).Error; err != nil {
+			return false, err
+		}
+	}
+
+	if !reflect.DeepEqual(optionGroupRequests(menuItem.OptionGroups), item.OptionGroups) {
+		var groupIDs []uint
+		for _, group := range menuItem.OptionGroups {
+			groupIDs = append(groupIDs, group.ID)
+		}
+		if len(groupIDs) > 0 {
+			if err := tx.Where("option_group_id IN ?", groupIDs).Delete(&models.MenuOption{}).Error; err != nil {
+				return false, err
+			}
+			if err := tx.Where("id IN ?", groupIDs).Delete(&models.MenuOptionGroup{}).Error; err != nil {
+				return false, err
+			}
+		}
+		if err := createTemplateOptionGroups(tx, menuItem.ID, item.OptionGroups); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	if !reflect.DeepEqual(availabilityRequests(menuItem.Availability), item.Availability) {
+		if err := tx.Where("menu_item_id = ?", menuItem.ID).Delete(&models.AvailabilityWindow{}).Error; err != nil {
+			return false, err
+		}
+		if err := createTemplateWindows(tx, menuItem.CoffeeShopID, &menuItem.ID, nil, item.Availability); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// createTemplateOptionGroups creates a menu item's option groups with their options
+func createTemplateOptionGroups(tx *gorm.DB, menuItemID uint, reqs []models.MenuOptionGroupCreateRequest) error {
+	for _, req := range reqs {
+		group := models.MenuOptionGroup{
+			MenuItemID: menuItemID,
+			Name:       req.Name,
+			MinSelect:  req.MinSelect,
+			MaxSelect:  req.MaxSelect,
+			OrderIndex: req.OrderIndex,
+		}
+		for _, opt := range req.Options {
+			group.Options = append(group.Options, newMenuOption(opt))
+		}
+		if err := tx.Create(&group).Error; err != nil {
+			return err
+		}
+		for i := range group.Options {
+			if err := saveOptionAvailability(tx, &group.Options[i], req.Options[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// createTemplateWindows creates the availability windows of a menu item or category
+func createTemplateWindows(tx *gorm.DB, shopID uint, menuItemID, categoryID *uint, reqs []models.AvailabilityWindowRequest) error {
+	windows, err := newAvailabilityWindows(shopID, reqs)
+	if err != nil || len(windows) == 0 {
+		return err
+	}
+	for i := range windows {
+		windows[i].MenuItemID = menuItemID
+		windows[i].CategoryID = categoryID
+	}
+	return tx.Create(&windows).Error
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"testing"
+
+	"coffee-shop-platform/internal/models"
+)
+
+func TestDietaryFilterMatches(t *testing.T) {
+	filter := &dietaryFilter{excludeAllergens: models.StringList{"milk"}, tags: models.StringList{"vegan"}}
+
+	tests := []struct {
+		name string
+		info models.DietaryInfo
+		want bool
+	}{
+		{"declared without allergens", models.DietaryInfo{AllergensDeclared: true, DietaryTags: models.StringList{"vegan"}}, true},
+		{"declared other allergens", models.DietaryInfo{Allergens: models.StringList{"nuts"}, AllergensDeclared: true, DietaryTags: models.StringList{"vegan"}}, true},
+		{"declared excluded allergen", models.DietaryInfo{Allergens: models.StringList{"milk"}, AllergensDeclared: true, DietaryTags: models.StringList{"vegan"}}, false},
+		{"undeclared allergens", models.DietaryInfo{DietaryTags: models.StringList{"vegan"}}, false},
+		{"missing tag", models.DietaryInfo{AllergensDeclared: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filter.matches(&tt.info); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !(&dietaryFilter{}).matches(&models.DietaryInfo{}) {
+		t.Error("empty filter left out an item without dietary info")
+	}
+}
+
+func TestUpdatedDietaryInfoAllergensDeclared(t *testing.T) {
+	declared := true
+	undeclared := false
+
+	tests := []struct {
+		name string
+		info models.DietaryInfo
+		req  models.MenuItemUpdateRequest
+		want bool
+	}{
+		{"empty list declares", models.DietaryInfo{}, models.MenuItemUpdateRequest{Allergens: []string{}}, true},
+		{"flag declares", models.DietaryInfo{}, models.MenuItemUpdateRequest{AllergensDeclared: &declared}, true},
+		{"flag undeclares", models.DietaryInfo{Allergens: models.StringList{"milk"}, AllergensDeclared: true}, models.MenuItemUpdateRequest{AllergensDeclared: &undeclared}, false},
+		{"other fields keep declaration", models.DietaryInfo{AllergensDeclared: true}, models.MenuItemUpdateRequest{DietaryTags: []string{"vegan"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := updatedDietaryInfo(tt.info, &tt.req)
+			if err != nil {
+				t.Fatalf("updatedDietaryInfo() error = %v", err)
+			}
+			if info.AllergensDeclared != tt.want {
+				t.Errorf("AllergensDeclared = %v, want %v", info.AllergensDeclared, tt.want)
+			}
+			if !info.AllergensDeclared && len(info.Allergens) > 0 {
+				t.Errorf("undeclared item kept allergens %v", info.Allergens)
+			}
+		})
+	}
+}
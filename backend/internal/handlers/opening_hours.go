@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/models"
+	"coffee-shop-platform/internal/schedule"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type OpeningHoursHandler struct{}
+
+func NewOpeningHoursHandler() *OpeningHoursHandler {
+	return &OpeningHoursHandler{}
+}
+
+// shopLocation returns the shop's time zone, falling back to the default for unknown names
+func shopLocation(coffeeShop *models.CoffeeShop) *time.Location {
+	if loc, err := time.LoadLocation(coffeeShop.TimeZone); err == nil {
+		return loc
+	}
+	loc, _ := time.LoadLocation(models.DefaultTimeZone)
+	return loc
+}
+
+// shopHours holds a shop's weekly hours and the exceptions in the status horizon
+type shopHours struct {
+	weekly     []models.OpeningHours
+	exceptions []models.OpeningException
+}
+
+// loadShopHours loads the weekly hours and the exceptions from yesterday on in the
+// shop's time zone
+func loadShopHours(db *gorm.DB, coffeeShop *models.CoffeeShop) (shopHours, error) {
+	var hours shopHours
+	if err := db.Where("coffee_shop_id = ?", coffeeShop.ID).Order("weekday ASC, opens_at ASC").Find(&hours.weekly).Error; err != nil {
+		return hours, err
+	}
+
+	from := schedule.DateOf(time.Now().In(shopLocation(coffeeShop))).AddDays(-1)
+	if err := db.Preload("Intervals", func(db *gorm.DB) *gorm.DB {
+		return db.Order("opens_at ASC")
+	}).Where("coffee_shop_id = ? AND date >= ?", coffeeShop.ID, from.String()).Order("date ASC").Find(&hours.exceptions).Error; err != nil {
+		return hours, err
+	}
+
+	return hours, nil
+}
+
+// configured reports whether the shop has set weekly hours. Shops without them have
+// no opening status, even with exceptions, which only change the weekly hours.
+func (h shopHours) configured() bool {
+	return len(h.weekly) > 0
+}
+
+// spans returns the opening spans of a date: the exception's if there is one,
+// otherwise the weekly hours of its weekday
+func (h shopHours) spans(date schedule.Date) []schedule.Span {
+	var spans []schedule.Span
+	for _, exception := range h.exceptions {
+		if exception.Date != date.String() {
+			continue
+		}
+		if exception.IsClosed {
+			return nil
+		}
+		for _, interval := range exception.Intervals {
+			if span, err := schedule.ParseSpan(interval.OpensAt, interval.ClosesAt); err == nil {
+				spans = append(spans, span)
+			}
+		}
+		return spans
+	}
+
+	for _, hours := range h.weekly {
+		if hours.Weekday != date.Weekday() {
+			continue
+		}
+		if span, err := schedule.ParseSpan(hours.OpensAt, hours.ClosesAt); err == nil {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// status returns whether the shop is open now and when that changes, or nil when
+// the shop has no hours
+func (h shopHours) status(coffeeShop *models.CoffeeShop) (*bool, *time.Time) {
+	if !h.configured() {
+		return nil, nil
+	}
+	status := schedule.At(time.Now(), shopLocation(coffeeShop), h.spans)
+	return &status.Open, status.NextChangeAt
+}
+
+// publicShop adds the opening hours and status to a shop shown to customers
+func publicShop(coffeeShop *models.CoffeeShop) (models.PublicShopResponse, error) {
+	hours, err := loadShopHours(database.DB, coffeeShop)
+	if err != nil {
+		return models.PublicShopResponse{}, err
+	}
+	withShopImages(coffeeShop)
+
+	response := models.PublicShopResponse{
+		CoffeeShop:   *coffeeShop,
+		OpeningHours: hours.weekly,
+	}
+	response.OpenNow, response.NextChangeAt = hours.status(coffeeShop)
+	return response, nil
+}
+
+// GetOpeningHours returns the shop's weekly hours, upcoming exceptions and status
+func (h *OpeningHoursHandler) GetOpeningHours(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	var coffeeShop models.CoffeeShop
+	if err := database.DB.First(&coffeeShop, shopID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Coffee shop not found",
+		})
+	}
+
+	return h.respond(c, &coffeeShop)
+}
+
+// UpdateOpeningHours replaces the shop's weekly hours and optionally its time zone
+func (h *OpeningHoursHandler) UpdateOpeningHours(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	var req models.OpeningHoursUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	var coffeeShop models.CoffeeShop
+	if err := database.DB.First(&coffeeShop, shopID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Coffee shop not found",
+		})
+	}
+
+	if req.TimeZone != nil {
+		if _, err := time.LoadLocation(*req.TimeZone); err != nil || *req.TimeZone == "" || *req.TimeZone == "Local" {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid time zone",
+				Message: "use an IANA time zone name such as Asia/Tehran",
+			})
+		}
+		coffeeShop.TimeZone = *req.TimeZone
+	}
+
+	weekly := make([]models.OpeningHours, 0, len(req.Weekly))
+	for _, hours := range req.Weekly {
+		if hours.Weekday < time.Sunday || hours.Weekday > time.Saturday {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid opening hours",
+				Message: "weekday must be between 0 (Sunday) and 6 (Saturday)",
+			})
+		}
+		if _, err := schedule.ParseSpan(hours.OpensAt, hours.ClosesAt); err != nil {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid opening hours",
+				Message: err.Error(),
+			})
+		}
+		weekly = append(weekly, models.OpeningHours{
+			CoffeeShopID: shopID,
+			Weekday:      hours.Weekday,
+			OpensAt:      hours.OpensAt,
+			ClosesAt:     hours.ClosesAt,
+		})
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if req.TimeZone != nil {
+			if err := tx.Model(&coffeeShop).Update("time_zone", coffeeShop.TimeZone).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("coffee_shop_id = ?", shopID).Delete(&models.OpeningHours{}).Error; err != nil {
+			return err
+		}
+		if len(weekly) == 0 {
+			return nil
+		}
+		return tx.Create(&weekly).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update opening hours",
+		})
+	}
+
+	return h.respond(c, &coffeeShop)
+}
+
+// SetOpeningException sets the hours of the :date param, replacing any earlier exception
+func (h *OpeningHoursHandler) SetOpeningException(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	date, err := schedule.ParseDate(c.Param("date"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid date",
+			Message: err.Error(),
+		})
+	}
+
+	var req models.OpeningExceptionRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if req.IsClosed == (len(req.Intervals) > 0) {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid opening exception",
+			Message: "give either is_closed or intervals",
+		})
+	}
+
+	exception := models.OpeningException{
+		CoffeeShopID: shopID,
+		Date:         date.String(),
+		IsClosed:     req.IsClosed,
+		Note:         req.Note,
+	}
+	for _, interval := range req.Intervals {
+		if _, err := schedule.ParseSpan(interval.OpensAt, interval.ClosesAt); err != nil {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid opening exception",
+				Message: err.Error(),
+			})
+		}
+		exception.Intervals = append(exception.Intervals, models.OpeningExceptionInterval{
+			OpensAt:  interval.OpensAt,
+			ClosesAt: interval.ClosesAt,
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deleteOpeningException(tx, shopID, exception.Date); err != nil {
+			return err
+		}
+		return tx.Create(&exception).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to save opening exception",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Opening exception saved successfully",
+		Data:    exception,
+	})
+}
+
+// DeleteOpeningException restores the weekly hours on the :date param
+func (h *OpeningHoursHandler) DeleteOpeningException(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	date, err := schedule.ParseDate(c.Param("date"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid date",
+			Message: err.Error(),
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		return deleteOpeningException(tx, shopID, date.String())
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to delete opening exception",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Opening exception deleted successfully",
+	})
+}
+
+func (h *OpeningHoursHandler) respond(c echo.Context, coffeeShop *models.CoffeeShop) error {
+	hours, err := loadShopHours(database.DB, coffeeShop)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve opening hours",
+		})
+	}
+
+	response := models.OpeningHoursResponse{
+		TimeZone:   coffeeShop.TimeZone,
+		Weekly:     hours.weekly,
+		Exceptions: hours.exceptions,
+	}
+	response.OpenNow, response.NextChangeAt = hours.status(coffeeShop)
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func deleteOpeningException(tx *gorm.DB, shopID uint, date string) error {
+	if err := tx.Where("opening_exception_id IN (SELECT id FROM opening_exceptions WHERE coffee_shop_id = ? AND date = ?)", shopID, date).
+		Delete(&models.OpeningExceptionInterval{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("coffee_shop_id = ? AND date = ?", shopID, date).Delete(&models.OpeningException{}).Error
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"coffee-shop-platform/internal/models"
+	"coffee-shop-platform/internal/schedule"
+)
+
+func TestShopHoursStatus(t *testing.T) {
+	coffeeShop := &models.CoffeeShop{TimeZone: "UTC"}
+	today := schedule.DateOf(time.Now().UTC())
+
+	// Open around the clock on every weekday
+	var allDay []models.OpeningHours
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		allDay = append(allDay, models.OpeningHours{Weekday: weekday, OpensAt: "00:00", ClosesAt: "24:00"})
+	}
+	closedToday := models.OpeningException{Date: today.String(), IsClosed: true}
+	closedTomorrow := models.OpeningException{Date: today.AddDays(1).String(), IsClosed: true}
+	morningToday := models.OpeningException{
+		Date:      today.String(),
+		Intervals: []models.OpeningExceptionInterval{{OpensAt: "00:00", ClosesAt: "00:01"}},
+	}
+
+	open, closed := true, false
+	tests := []struct {
+		name  string
+		hours shopHours
+		want  *bool
+	}{
+		{"no hours", shopHours{}, nil},
+		{"only a closed exception today", shopHours{exceptions: []models.OpeningException{closedToday}}, nil},
+		{"only an exception with hours", shopHours{exceptions: []models.OpeningException{morningToday}}, nil},
+		{"weekly hours", shopHours{weekly: allDay}, &open},
+		{"weekly hours, closed today", shopHours{weekly: allDay, exceptions: []models.OpeningException{closedToday}}, &closed},
+		{"weekly hours, closed tomorrow", shopHours{weekly: allDay, exceptions: []models.OpeningException{closedTomorrow}}, &open},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := tt.hours.status(coffeeShop)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil:
+				t.Errorf("status = %v, want %v", formatOpen(got), formatOpen(tt.want))
+			case *got != *tt.want:
+				t.Errorf("status = %v, want %v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func formatOpen(open *bool) string {
+	if open == nil {
+		return "no status"
+	}
+	if *open {
+		return "open"
+	}
+	return "closed"
+}
@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/events"
+	"coffee-shop-platform/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type OrderHandler struct{}
+
+func NewOrderHandler() *OrderHandler {
+	return &OrderHandler{}
+}
+
+// orderValidationError is returned for order requests that don't match the menu
+type orderValidationError struct {
+	message string
+}
+
+func (e *orderValidationError) Error() string {
+	return e.message
+}
+
+func invalidOrder(format string, args ...any) error {
+	return &orderValidationError{message: fmt.Sprintf(format, args...)}
+}
+
+var errOrderStatusChanged = errors.New("order status changed concurrently")
+
+// withOrderLines preloads order lines and their chosen options
+func withOrderLines(db *gorm.DB) *gorm.DB {
+	return db.Preload("Lines").Preload("Lines.Options")
+}
+
+// CreateOrder places an order on a coffee shop of the resolved tenant
+func (h *OrderHandler) CreateOrder(c echo.Context) error {
+	tenantID := c.Get("tenant_id")
+	if tenantID == nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Tenant not found",
+		})
+	}
+
+	var req models.OrderCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if len(req.Lines) == 0 {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid order",
+			Message: "order must contain at least one line",
+		})
+	}
+
+	// Orders go to the shop of the :slug param or the request, or to the tenant's first shop
+	shopQuery := database.DB.Where("tenant_id = ? AND is_active = ?", tenantID, true)
+	if slug := c.Param("slug"); slug != "" {
+		shopQuery = shopQuery.Where("slug = ?", slug)
+	} else if req.CoffeeShopID != nil {
+		shopQuery = shopQuery.Where("id = ?", *req.CoffeeShopID)
+	}
+	var coffeeShop models.CoffeeShop
+	if err := shopQuery.Order("id ASC").First(&coffeeShop).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Coffee shop not found",
+		})
+	}
+
+	// Shops that set opening hours only take orders while open
+	hours, err := loadShopHours(database.DB, &coffeeShop)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to create order",
+		})
+	}
+	if open, nextChangeAt := hours.status(&coffeeShop); open != nil && !*open {
+		message := "the shop is closed"
+		if nextChangeAt != nil {
+			message += ", it opens at " + nextChangeAt.In(shopLocation(&coffeeShop)).Format(time.RFC3339)
+		}
+		return c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Coffee shop is closed",
+			Message: message,
+		})
+	}
+
+	order := models.Order{
+		CoffeeShopID:    coffeeShop.ID,
+		Status:          models.OrderStatusReceived,
+		CustomerName:    req.CustomerName,
+		TableNumber:     req.TableNumber,
+		Note:            req.Note,
+		StatusChangedAt: time.Now(),
+	}
+
+	availability, err := loadMenuAvailability(database.DB, coffeeShop.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to create order",
+		})
+	}
+
+	update := newStockUpdate(coffeeShop.ID)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, lineReq := range req.Lines {
+			line, err := buildOrderLine(tx, coffeeShop.ID, availability, lineReq)
+			if err != nil {
+				return err
+			}
+			order.Lines = append(order.Lines, line)
+			order.Total += line.LineTotal
+		}
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+		return consumeOrderStock(tx, &order, update)
+	})
+
+	var validationErr *orderValidationError
+	if errors.As(err, &validationErr) {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid order",
+			Message: validationErr.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to create order",
+		})
+	}
+
+	publishShopEvent(events.OrderCreated, coffeeShop.ID, false, order)
+	update.publish()
+
+	return c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Order placed successfully",
+		Data:    order,
+	})
+}
+
+// buildOrderLine checks a requested line against the shop's menu and snapshots
+// the item name, tier and option prices
+func buildOrderLine(tx *gorm.DB, shopID uint, availability *menuAvailability, req models.OrderLineCreateRequest) (models.OrderLine, error) {
+	if req.Quantity < 1 || req.Quantity > 99 {
+		return models.OrderLine{}, invalidOrder("quantity for menu item %d must be between 1 and 99", req.MenuItemID)
+	}
+
+	var menuItem models.MenuItem
+	err := tx.Preload("PriceTiers", orderByIndex).
+		Preload("OptionGroups.Options").
+		Preload("Availability").
+		Where("id = ? AND coffee_shop_id = ? AND is_available = ?", req.MenuItemID, shopID, true).
+		First(&menuItem).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.OrderLine{}, invalidOrder("menu item %d is not available", req.MenuItemID)
+	}
+	if err != nil {
+		return models.OrderLine{}, err
+	}
+	if !availability.available(&menuItem) {
+		return models.OrderLine{}, invalidOrder("%q is not available at this time", menuItem.Name)
+	}
+
+	line := models.OrderLine{
+		MenuItemID: menuItem.ID,
+		Name:       menuItem.Name,
+		UnitPrice:  menuItem.Price,
+		Quantity:   req.Quantity,
+		Note:       req.Note,
+	}
+
+	if req.PriceTierID != nil {
+		found := false
+		for _, tier := range menuItem.PriceTiers {
+			if tier.ID == *req.PriceTierID {
+				tierID := tier.ID
+				line.PriceTierID = &tierID
+				line.TierLabel = tier.Label
+				line.UnitPrice = tier.Price
+				found = true
+				break
+			}
+		}
+		if !found {
+			return models.OrderLine{}, invalidOrder("price tier %d does not belong to menu item %d", *req.PriceTierID, menuItem.ID)
+		}
+	} else if len(menuItem.PriceTiers) > 0 {
+		tier := menuItem.PriceTiers[0]
+		line.PriceTierID = &tier.ID
+		line.TierLabel = tier.Label
+		line.UnitPrice = tier.Price
+	}
+
+	selected := make(map[uint]bool, len(req.OptionIDs))
+	for _, optionID := range req.OptionIDs {
+		if selected[optionID] {
+			return models.OrderLine{}, invalidOrder("option %d is selected more than once", optionID)
+		}
+		selected[optionID] = true
+	}
+
+	matched := 0
+	for _, group := range menuItem.OptionGroups {
+		count := 0
+		for _, option := range group.Options {
+			if !selected[option.ID] {
+				continue
+			}
+			if !option.IsAvailable {
+				return models.OrderLine{}, invalidOrder("option %q is not available", option.Name)
+			}
+			count++
+			line.UnitPrice += option.PriceDelta
+			line.Options = append(line.Options, models.OrderLineOption{
+				MenuOptionID: option.ID,
+				GroupName:    group.Name,
+				Name:         option.Name,
+				PriceDelta:   option.PriceDelta,
+			})
+		}
+		if count < group.MinSelect || count > group.MaxSelect {
+			return models.OrderLine{}, invalidOrder("%q on %q requires between %d and %d selections", group.Name, menuItem.Name, group.MinSelect, group.MaxSelect)
+		}
+		matched += count
+	}
+	if matched != len(selected) {
+		return models.OrderLine{}, invalidOrder("some options do not belong to menu item %d", menuItem.ID)
+	}
+
+	// Options may lower the price, but never below free
+	if line.UnitPrice < 0 {
+		line.UnitPrice = 0
+	}
+	line.LineTotal = line.UnitPrice * line.Quantity
+	return line, nil
+}
+
+// GetOrders lists the shop's orders, newest first, optionally filtered by ?status=
+func (h *OrderHandler) GetOrders(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	params, err := parseListParams(c, map[string]string{"created_at": "created_at", "id": "id"}, "-created_at")
+	if params == nil {
+		return err
+	}
+
+	query := database.DB.Model(&models.Order{}).Where("coffee_shop_id = ?", shopID)
+	if status := c.QueryParam("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var orders []models.Order
+	total, err := params.find(query, &orders, withOrderLines)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve orders",
+		})
+	}
+
+	return c.JSON(http.StatusOK, listResponse(params, orders, total))
+}
+
+// GetOrder retrieves a single order of the shop
+func (h *OrderHandler) GetOrder(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid order ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+
+	var order models.Order
+	if err := database.DB.Scopes(withOrderLines).Where("id = ? AND coffee_shop_id = ?", uint(id), shopID).First(&order).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Order not found",
+		})
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// UpdateOrderStatus moves an order along received → preparing → ready → completed,
+// or to cancelled from any open status
+func (h *OrderHandler) UpdateOrderStatus(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid order ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+
+	var req models.OrderStatusUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if !req.Status.IsValid() {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid order status",
+		})
+	}
+
+	var order models.Order
+	if err := database.DB.Where("id = ? AND coffee_shop_id = ?", uint(id), shopID).First(&order).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Order not found",
+		})
+	}
+
+	if !order.Status.CanTransitionTo(req.Status) {
+		return c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Invalid status transition",
+			Message: fmt.Sprintf("cannot move order from %s to %s", order.Status, req.Status),
+		})
+	}
+
+	// Only update if nobody changed the status in the meantime. Cancelled orders
+	// give back the stock they took.
+	now := time.Now()
+	update := newStockUpdate(shopID)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND status = ?", order.ID, order.Status).
+			Updates(map[string]any{"status": req.Status, "status_changed_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOrderStatusChanged
+		}
+		if req.Status == models.OrderStatusCancelled {
+			return restoreOrderStock(tx, &order, update)
+		}
+		return nil
+	})
+	if errors.Is(err, errOrderStatusChanged) {
+		return c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error: "Order status changed concurrently, please retry",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update order status",
+		})
+	}
+
+	database.DB.Scopes(withOrderLines).First(&order, order.ID)
+
+	publishShopEvent(events.OrderStatusChanged, shopID, false, map[string]any{
+		"id":     order.ID,
+		"status": order.Status,
+	})
+	update.publish()
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Order status updated successfully",
+		Data:    order,
+	})
+}
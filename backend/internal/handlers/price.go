@@ -0,0 +1,480 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/events"
+	"coffee-shop-platform/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// priceSchedulerBatchSize caps the scheduled changes applied in one transaction
+const priceSchedulerBatchSize = 500
+
+type PriceHandler struct{}
+
+func NewPriceHandler() *PriceHandler {
+	return &PriceHandler{}
+}
+
+// currentActor returns the authenticated user as the author of a change
+func currentActor(c echo.Context) models.Actor {
+	var actor models.Actor
+	if userID, ok := c.Get("user_id").(uint); ok {
+		actor.ChangedByID = &userID
+	}
+	actor.ChangedByType, _ = c.Get("user_type").(string)
+	actor.ChangedByName, _ = c.Get("username").(string)
+	return actor
+}
+
+// recordPriceChange adds a tier's new price to the history. oldPrice is nil for a new tier.
+func recordPriceChange(tx *gorm.DB, menuItem *models.MenuItem, tier *models.MenuItemPriceTier, oldPrice *int, source string, actor models.Actor, scheduledID *uint) error {
+	return tx.Create(&models.PriceChange{
+		CoffeeShopID:           menuItem.CoffeeShopID,
+		MenuItemID:             menuItem.ID,
+		PriceTierID:            tier.ID,
+		TierLabel:              tier.Label,
+		OldPrice:               oldPrice,
+		NewPrice:               tier.Price,
+		Source:                 source,
+		Actor:                  actor,
+		ScheduledPriceChangeID: scheduledID,
+	}).Error
+}
+
+// GetPriceHistory returns the price history of a menu item, newest first, optionally
+// for one tier (?price_tier_id=)
+func (h *PriceHandler) GetPriceHistory(c echo.Context) error {
+	menuItem, err := findShopMenuItem(c)
+	if menuItem == nil {
+		return err
+	}
+
+	params, err := parseListParams(c, map[string]string{"created_at": "created_at"}, "-created_at")
+	if params == nil {
+		return err
+	}
+
+	query, err := filterID(c, database.DB.Model(&models.PriceChange{}).Where("menu_item_id = ?", menuItem.ID), "price_tier_id", "price_tier_id")
+	if query == nil {
+		return err
+	}
+
+	var history []models.PriceChange
+	total, err := params.find(query, &history)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve price history",
+		})
+	}
+
+	return c.JSON(http.StatusOK, listResponse(params, history, total))
+}
+
+// SchedulePriceChange schedules a new price for one of the menu item's tiers
+func (h *PriceHandler) SchedulePriceChange(c echo.Context) error {
+	menuItem, err := findShopMenuItem(c)
+	if menuItem == nil {
+		return err
+	}
+
+	var req models.ScheduledPriceChangeRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if req.Price < 0 {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Price must not be negative",
+		})
+	}
+	if !req.EffectiveAt.After(time.Now()) {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "effective_at must be in the future",
+		})
+	}
+
+	var tier models.MenuItemPriceTier
+	if err := database.DB.Where("id = ? AND menu_item_id = ?", req.PriceTierID, menuItem.ID).First(&tier).Error; err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid price tiers",
+			Message: errUnknownPriceTier.Error(),
+		})
+	}
+
+	change := models.ScheduledPriceChange{
+		CoffeeShopID: menuItem.CoffeeShopID,
+		MenuItemID:   menuItem.ID,
+		PriceTierID:  tier.ID,
+		NewPrice:     req.Price,
+		EffectiveAt:  req.EffectiveAt,
+		Status:       models.ScheduledPricePending,
+		Actor:        currentActor(c),
+	}
+
+	if err := database.DB.Omit(clause.Associations).Create(&change).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to schedule price change",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Price change scheduled successfully",
+		Data:    change,
+	})
+}
+
+// GetScheduledPriceChanges lists the shop's scheduled price changes by effective time.
+// Only pending changes are listed unless ?status= is given.
+func (h *PriceHandler) GetScheduledPriceChanges(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	status := c.QueryParam("status")
+	if status == "" {
+		status = models.ScheduledPricePending
+	}
+
+	params, err := parseListParams(c, map[string]string{"effective_at": "effective_at"}, "effective_at")
+	if params == nil {
+		return err
+	}
+
+	query := database.DB.Model(&models.ScheduledPriceChange{}).Where("coffee_shop_id = ? AND status = ?", shopID, status)
+	query, err = filterID(c, query, "menu_item_id", "menu_item_id")
+	if query == nil {
+		return err
+	}
+
+	var changes []models.ScheduledPriceChange
+	total, err := params.find(query, &changes, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("MenuItem").Preload("PriceTier")
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve scheduled price changes",
+		})
+	}
+
+	return c.JSON(http.StatusOK, listResponse(params, changes, total))
+}
+
+// CancelScheduledPriceChange cancels a pending scheduled price change
+func (h *PriceHandler) CancelScheduledPriceChange(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid scheduled price change ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+
+	result := database.DB.Model(&models.ScheduledPriceChange{}).
+		Where("id = ? AND coffee_shop_id = ? AND status = ?", uint(id), shopID, models.ScheduledPricePending).
+		Update("status", models.ScheduledPriceCancelled)
+	if result.Error != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to cancel scheduled price change",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Pending price change not found",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Scheduled price change cancelled successfully",
+	})
+}
+
+// bulkPriceChangeLines computes the new price of every tier the bulk change covers
+func bulkPriceChangeLines(db *gorm.DB, shopID uint, req *models.BulkPriceChangeRequest) ([]models.BulkPriceChangeLine, error) {
+	if req.Mode != models.BulkPricePercent && req.Mode != models.BulkPriceFixed {
+		return nil, fmt.Errorf("mode must be %q or %q", models.BulkPricePercent, models.BulkPriceFixed)
+	}
+	if req.Value == 0 {
+		return nil, errors.New("value must not be zero")
+	}
+	if req.RoundTo < 0 {
+		return nil, errors.New("round_to must not be negative")
+	}
+	if !req.EffectiveAt.After(time.Now()) {
+		return nil, errors.New("effective_at must be in the future")
+	}
+
+	query := db.Preload("PriceTiers", orderByIndex).Where("coffee_shop_id = ?", shopID)
+	if req.CategoryID != nil {
+		if !categoryAvailableToShop(*req.CategoryID, shopID) {
+			return nil, errors.New("category not found")
+		}
+		query = query.Where("category_id = ?", *req.CategoryID)
+	}
+
+	var menuItems []models.MenuItem
+	if err := query.Order("order_index ASC, id ASC").Find(&menuItems).Error; err != nil {
+		return nil, err
+	}
+
+	lines := make([]models.BulkPriceChangeLine, 0, len(menuItems))
+	for _, menuItem := range menuItems {
+		for _, tier := range menuItem.PriceTiers {
+			newPrice := float64(tier.Price) + req.Value
+			if req.Mode == models.BulkPricePercent {
+				newPrice = float64(tier.Price) * (1 + req.Value/100)
+			}
+			if req.RoundTo > 0 {
+				newPrice = math.Round(newPrice/float64(req.RoundTo)) * float64(req.RoundTo)
+			}
+			if newPrice < 0 {
+				return nil, fmt.Errorf("%s (%s) would get a negative price", menuItem.Name, tier.Label)
+			}
+			lines = append(lines, models.BulkPriceChangeLine{
+				MenuItemID:  menuItem.ID,
+				Name:        menuItem.Name,
+				PriceTierID: tier.ID,
+				TierLabel:   tier.Label,
+				OldPrice:    tier.Price,
+				NewPrice:    int(math.Round(newPrice)),
+			})
+		}
+	}
+	return lines, nil
+}
+
+// bindBulkPriceChange binds a bulk price change request and computes its lines
+func bindBulkPriceChange(c echo.Context) (*models.BulkPriceChangeRequest, []models.BulkPriceChangeLine, error) {
+	var req models.BulkPriceChangeRequest
+	if err := c.Bind(&req); err != nil {
+		return nil, nil, invalidRequestBody(c, err)
+	}
+
+	lines, err := bulkPriceChangeLines(database.DB, c.Get("shop_id").(uint), &req)
+	if err != nil {
+		return nil, nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid bulk price change",
+			Message: err.Error(),
+		})
+	}
+	return &req, lines, nil
+}
+
+// PreviewBulkPriceChange returns the prices a bulk change would set without scheduling it.
+// Prices are computed from the current prices, ignoring other pending changes.
+func (h *PriceHandler) PreviewBulkPriceChange(c echo.Context) error {
+	req, lines, err := bindBulkPriceChange(c)
+	if req == nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.BulkPriceChangePreview{
+		EffectiveAt: req.EffectiveAt,
+		Lines:       lines,
+	})
+}
+
+// CreateBulkPriceChange schedules the changes of a bulk price change as one batch.
+// Tiers whose price would not change are left out.
+func (h *PriceHandler) CreateBulkPriceChange(c echo.Context) error {
+	req, lines, err := bindBulkPriceChange(c)
+	if req == nil {
+		return err
+	}
+
+	shopID := c.Get("shop_id").(uint)
+	actor := currentActor(c)
+
+	batch := models.PriceChangeBatch{
+		CoffeeShopID: shopID,
+		CategoryID:   req.CategoryID,
+		Mode:         req.Mode,
+		Value:        req.Value,
+		RoundTo:      req.RoundTo,
+		EffectiveAt:  req.EffectiveAt,
+		Actor:        actor,
+	}
+	for _, line := range lines {
+		if line.NewPrice == line.OldPrice {
+			continue
+		}
+		batch.Changes = append(batch.Changes, models.ScheduledPriceChange{
+			CoffeeShopID: shopID,
+			MenuItemID:   line.MenuItemID,
+			PriceTierID:  line.PriceTierID,
+			NewPrice:     line.NewPrice,
+			EffectiveAt:  req.EffectiveAt,
+			Status:       models.ScheduledPricePending,
+			Actor:        actor,
+		})
+	}
+
+	if len(batch.Changes) == 0 {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid bulk price change",
+			Message: "no prices would change",
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Changes").Create(&batch).Error; err != nil {
+			return err
+		}
+		for i := range batch.Changes {
+			batch.Changes[i].BatchID = &batch.ID
+		}
+		return tx.Omit(clause.Associations).Create(&batch.Changes).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to schedule price changes",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Price changes scheduled successfully",
+		Data:    batch,
+	})
+}
+
+// CancelPriceChangeBatch cancels the pending changes of a bulk price change
+func (h *PriceHandler) CancelPriceChangeBatch(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid batch ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+
+	var batch models.PriceChangeBatch
+	if err := database.DB.Where("id = ? AND coffee_shop_id = ?", uint(id), shopID).First(&batch).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Batch not found",
+		})
+	}
+
+	result := database.DB.Model(&models.ScheduledPriceChange{}).
+		Where("batch_id = ? AND status = ?", batch.ID, models.ScheduledPricePending).
+		Update("status", models.ScheduledPriceCancelled)
+	if result.Error != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to cancel price changes",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Price changes cancelled successfully",
+		Data:    map[string]any{"cancelled": result.RowsAffected},
+	})
+}
+
+// RunPriceScheduler applies due scheduled price changes every interval until ctx is done
+func RunPriceScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := applyDuePriceChanges(time.Now()); err != nil {
+			log.Printf("Failed to apply scheduled price changes: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// applyDuePriceChanges applies the pending changes due at now, oldest first. Rows are
+// locked with SKIP LOCKED so several server instances can run the scheduler.
+func applyDuePriceChanges(now time.Time) error {
+	for {
+		var updated map[uint]uint
+		var due []models.ScheduledPriceChange
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("status = ? AND effective_at <= ?", models.ScheduledPricePending, now).
+				Order("effective_at ASC, id ASC").Limit(priceSchedulerBatchSize).Find(&due).Error; err != nil {
+				return err
+			}
+
+			updated = make(map[uint]uint)
+			for i := range due {
+				applied, err := applyScheduledPriceChange(tx, &due[i], now)
+				if err != nil {
+					return err
+				}
+				if applied {
+					updated[due[i].MenuItemID] = due[i].CoffeeShopID
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for menuItemID, shopID := range updated {
+			publishMenuItemEvent(events.MenuItemUpdated, shopID, menuItemID)
+		}
+
+		if len(due) < priceSchedulerBatchSize {
+			return nil
+		}
+	}
+}
+
+// applyScheduledPriceChange sets the tier's new price and records it in the history as
+// made by whoever scheduled it. Changes of deleted tiers or items are skipped.
+func applyScheduledPriceChange(tx *gorm.DB, change *models.ScheduledPriceChange, now time.Time) (bool, error) {
+	var menuItem models.MenuItem
+	err := tx.Preload("PriceTiers", orderByIndex).First(&menuItem, change.MenuItemID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	var tier *models.MenuItemPriceTier
+	for i := range menuItem.PriceTiers {
+		if menuItem.PriceTiers[i].ID == change.PriceTierID {
+			tier = &menuItem.PriceTiers[i]
+		}
+	}
+	if tier == nil {
+		return false, tx.Model(change).Update("status", models.ScheduledPriceSkipped).Error
+	}
+
+	oldPrice := tier.Price
+	tier.Price = change.NewPrice
+	if oldPrice != tier.Price {
+		if err := tx.Model(tier).Update("price", tier.Price).Error; err != nil {
+			return false, err
+		}
+		if err := recordPriceChange(tx, &menuItem, tier, &oldPrice, models.PriceSourceScheduled, change.Actor, &change.ID); err != nil {
+			return false, err
+		}
+		// Only the prices are written, so concurrent edits to the rest of the item survive
+		menuItem.SyncLegacyPrices()
+		if err := tx.Model(&menuItem).Select("price", "price_premium", "has_dual_pricing").Updates(&menuItem).Error; err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Model(change).Updates(map[string]any{
+		"status":     models.ScheduledPriceApplied,
+		"applied_at": now,
+	}).Error
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/models"
+	"coffee-shop-platform/internal/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type StaffHandler struct{}
+
+func NewStaffHandler() *StaffHandler {
+	return &StaffHandler{}
+}
+
+// shopRoleScope scopes a role query to the built-in roles and the shop's own roles
+func shopRoleScope(shopID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("coffee_shop_id IS NULL OR coffee_shop_id = ?", shopID)
+	}
+}
+
+// findShopRole loads a role that staff of the shop may be given
+func findShopRole(db *gorm.DB, roleID, shopID uint) (*models.Role, error) {
+	var role models.Role
+	if err := db.Scopes(shopRoleScope(shopID)).Preload("Permissions").First(&role, roleID).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// GetRoles lists the roles available to the shop and all known permissions
+func (h *StaffHandler) GetRoles(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	var roles []models.Role
+	if err := database.DB.Scopes(shopRoleScope(shopID)).Preload("Permissions").Order("is_system DESC, id ASC").Find(&roles).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve roles",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"roles":       roles,
+		"permissions": models.AllPermissions,
+	})
+}
+
+// CreateRole creates a custom role for the shop
+func (h *StaffHandler) CreateRole(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	var req models.RoleCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if len(req.Permissions) == 0 {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "At least one permission is required",
+		})
+	}
+
+	role := models.Role{
+		CoffeeShopID: &shopID,
+		Name:         req.Name,
+		DisplayName:  req.DisplayName,
+	}
+	seen := make(map[string]bool)
+	for _, permission := range req.Permissions {
+		if !models.IsKnownPermission(permission) {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Unknown permission",
+				Message: permission,
+			})
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			role.Permissions = append(role.Permissions, models.RolePermission{Permission: permission})
+		}
+	}
+
+	var count int64
+	database.DB.Model(&models.Role{}).Scopes(shopRoleScope(shopID)).Where("name = ?", req.Name).Count(&count)
+	if count > 0 {
+		return c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error: "Role name already exists",
+		})
+	}
+
+	if err := database.DB.Create(&role).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to create role",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Role created successfully",
+		Data:    role,
+	})
+}
+
+// GetStaff lists the shop's staff accounts with their roles
+func (h *StaffHandler) GetStaff(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	var staff []models.ShopAdmin
+	if err := database.DB.Preload("Role.Permissions").Where("coffee_shop_id = ?", shopID).Order("id ASC").Find(&staff).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve staff",
+		})
+	}
+
+	return c.JSON(http.StatusOK, staff)
+}
+
+// CreateStaff adds a staff account to the owner's shop
+func (h *StaffHandler) CreateStaff(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	var req models.StaffCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	role, err := findShopRole(database.DB, req.RoleID, shopID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Role not found",
+		})
+	}
+
+	if usernameTaken(database.DB, req.Username, 0) {
+		return c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error: "Username already exists",
+		})
+	}
+
+	passwordHash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to hash password",
+		})
+	}
+
+	admin := models.ShopAdmin{
+		CoffeeShopID: shopID,
+		RoleID:       &role.ID,
+		Username:     req.Username,
+		PasswordHash: passwordHash,
+		IsActive:     true,
+	}
+
+	if err := database.DB.Omit("Role").Create(&admin).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to create staff account",
+		})
+	}
+	admin.Role = role
+
+	return c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Staff account created successfully",
+		Data:    admin,
+	})
+}
+
+// UpdateStaff changes a staff account's role or active status. The account's
+// sessions are revoked so the change applies immediately.
+func (h *StaffHandler) UpdateStaff(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid staff ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+
+	var req models.StaffUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	if c.Get("user_id") == uint(id) {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "You cannot change your own role or status",
+		})
+	}
+
+	var admin models.ShopAdmin
+	if err := database.DB.Where("id = ? AND coffee_shop_id = ?", uint(id), shopID).First(&admin).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Staff account not found",
+		})
+	}
+
+	if req.RoleID != nil {
+		role, err := findShopRole(database.DB, *req.RoleID, shopID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "Role not found",
+			})
+		}
+		admin.RoleID = &role.ID
+	}
+	if req.IsActive != nil {
+		admin.IsActive = *req.IsActive
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Role", "CoffeeShop").Save(&admin).Error; err != nil {
+			return err
+		}
+		return revokeUserSessions(tx, admin.ID, "shop_admin", models.RevokedByAdmin)
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to update staff account",
+		})
+	}
+
+	database.DB.Preload("Role.Permissions").First(&admin, admin.ID)
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Staff account updated successfully",
+		Data:    admin,
+	})
+}
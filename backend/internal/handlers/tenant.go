@@ -16,23 +16,42 @@ func NewTenantHandler() *TenantHandler {
 	return &TenantHandler{}
 }
 
+// tenantSortKeys are the keys tenant lists can be sorted by
+var tenantSortKeys = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"subdomain":  "subdomain",
+	"created_at": "created_at",
+}
+
+// GetTenants lists tenants, filtered by ?is_active= and ?q= on the name or subdomain
 func (h *TenantHandler) GetTenants(c echo.Context) error {
+	params, err := parseListParams(c, tenantSortKeys, "id")
+	if params == nil {
+		return err
+	}
+
+	query, err := filterBool(c, database.DB.Model(&models.Tenant{}), "is_active", "is_active")
+	if query == nil {
+		return err
+	}
+	query = filterName(c, query, "name", "subdomain")
+
 	var tenants []models.Tenant
-	if err := database.DB.Find(&tenants).Error; err != nil {
+	total, err := params.find(query, &tenants)
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to retrieve tenants",
 		})
 	}
 
-	return c.JSON(http.StatusOK, tenants)
+	return c.JSON(http.StatusOK, listResponse(params, tenants, total))
 }
 
 func (h *TenantHandler) CreateTenant(c echo.Context) error {
 	var req models.TenantCreateRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "Invalid request body",
-		})
+		return invalidRequestBody(c, err)
 	}
 
 	tenant := models.Tenant{
@@ -81,9 +100,7 @@ func (h *TenantHandler) UpdateTenant(c echo.Context) error {
 
 	var req models.TenantUpdateRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "Invalid request body",
-		})
+		return invalidRequestBody(c, err)
 	}
 
 	var tenant models.Tenant
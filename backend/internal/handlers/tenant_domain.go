@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"coffee-shop-platform/internal/config"
+	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/models"
+	"coffee-shop-platform/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// normalizeDomain lowercases a domain and checks it is a plain host name
+func normalizeDomain(domain string) (string, bool) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if len(domain) > 253 || !strings.Contains(domain, ".") || net.ParseIP(domain) != nil {
+		return "", false
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return "", false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return "", false
+			}
+		}
+	}
+	return domain, true
+}
+
+// GetTenantDomains lists the custom domains of a tenant
+func (h *TenantHandler) GetTenantDomains(c echo.Context) error {
+	tenantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid tenant ID",
+		})
+	}
+
+	var domains []models.TenantDomain
+	if err := database.DB.Where("tenant_id = ?", uint(tenantID)).Order("domain ASC").Find(&domains).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve domains",
+		})
+	}
+
+	return c.JSON(http.StatusOK, domains)
+}
+
+// CreateTenantDomain adds an unverified custom domain to a tenant. The response holds
+// the TXT record to publish before calling the verify endpoint.
+func (h *TenantHandler) CreateTenantDomain(c echo.Context) error {
+	tenantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid tenant ID",
+		})
+	}
+
+	var req models.TenantDomainCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidRequestBody(c, err)
+	}
+
+	domain, ok := normalizeDomain(req.Domain)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid domain",
+		})
+	}
+
+	cfg := c.Get("config").(*config.Config)
+	if base := cfg.Tenant.BaseDomain; base != "" && (domain == base || strings.HasSuffix(domain, "."+base)) {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid domain",
+			Message: "subdomains of " + base + " are routed by tenant subdomain",
+		})
+	}
+
+	var tenant models.Tenant
+	if err := database.DB.First(&tenant, uint(tenantID)).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Tenant not found",
+		})
+	}
+
+	var count int64
+	database.DB.Model(&models.TenantDomain{}).Where("domain = ?", domain).Count(&count)
+	if count > 0 {
+		return c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error: "Domain already registered",
+		})
+	}
+
+	token, err := utils.GenerateTokenID()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to generate verification token",
+		})
+	}
+
+	tenantDomain := models.TenantDomain{
+		TenantID:          tenant.ID,
+		Domain:            domain,
+		VerificationToken: token,
+	}
+	if err := database.DB.Create(&tenantDomain).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to add domain",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "Add a TXT record " + tenantDomain.VerificationRecord() + " with the verification token, then verify the domain",
+		Data:    tenantDomain,
+	})
+}
+
+// VerifyTenantDomain checks the domain's TXT record and, when it holds the
+// verification token, starts routing the domain to the tenant
+func (h *TenantHandler) VerifyTenantDomain(c echo.Context) error {
+	tenantDomain, err := findTenantDomain(c)
+	if tenantDomain == nil {
+		return err
+	}
+
+	if tenantDomain.VerifiedAt == nil {
+		ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+		defer cancel()
+
+		records, err := net.DefaultResolver.LookupTXT(ctx, tenantDomain.VerificationRecord())
+		if err != nil || !containsString(records, tenantDomain.VerificationToken) {
+			return c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+				Error:   "Domain verification failed",
+				Message: "TXT record " + tenantDomain.VerificationRecord() + " does not contain the verification token",
+			})
+		}
+
+		now := time.Now()
+		if err := database.DB.Model(tenantDomain).Update("verified_at", now).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error: "Failed to verify domain",
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Domain verified successfully",
+		Data:    tenantDomain,
+	})
+}
+
+// DeleteTenantDomain removes a custom domain from a tenant
+func (h *TenantHandler) DeleteTenantDomain(c echo.Context) error {
+	tenantDomain, err := findTenantDomain(c)
+	if tenantDomain == nil {
+		return err
+	}
+
+	if err := database.DB.Delete(tenantDomain).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to delete domain",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Domain deleted successfully",
+	})
+}
+
+// findTenantDomain loads the domain from the :domainId param, scoped to the :id tenant
+func findTenantDomain(c echo.Context) (*models.TenantDomain, error) {
+	tenantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid tenant ID",
+		})
+	}
+	domainID, err := strconv.ParseUint(c.Param("domainId"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid domain ID",
+		})
+	}
+
+	var tenantDomain models.TenantDomain
+	if err := database.DB.Where("id = ? AND tenant_id = ?", uint(domainID), uint(tenantID)).First(&tenantDomain).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Domain not found",
+		})
+	}
+
+	return &tenantDomain, nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
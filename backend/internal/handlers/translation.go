@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"net/http"
+
+	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/i18n"
+	"coffee-shop-platform/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TranslationHandler struct{}
+
+func NewTranslationHandler() *TranslationHandler {
+	return &TranslationHandler{}
+}
+
+// menuLocalizer shows menu content in the first language of a chain that has it: the
+// languages the customer asked for with ?lang= or Accept-Language, then the shop's
+// default language
+type menuLocalizer struct {
+	chain        []string
+	shopLanguage string
+}
+
+// newMenuLocalizer negotiates the language of a public response and sets its
+// Content-Language header. Without a shop, content is in the template language.
+func newMenuLocalizer(c echo.Context, coffeeShop *models.CoffeeShop) *menuLocalizer {
+	language := models.TemplateLanguage
+	if coffeeShop != nil {
+		language = shopLanguage(coffeeShop)
+	}
+
+	chain := i18n.Chain(c.QueryParam("lang"), c.Request().Header.Get("Accept-Language"), models.SupportedLanguages, language)
+	c.Response().Header().Set("Content-Language", chain[0])
+	c.Response().Header().Add(echo.HeaderVary, "Accept-Language")
+
+	return &menuLocalizer{chain: chain, shopLanguage: language}
+}
+
+// shopLanguage returns the language a shop's menu is written in
+func shopLanguage(coffeeShop *models.CoffeeShop) string {
+	if coffeeShop.DefaultLanguage == "" {
+		return models.LanguagePersian
+	}
+	return coffeeShop.DefaultLanguage
+}
+
+// text returns field in the first language of the chain that has it. base is the
+// untranslated text, written in baseLanguage.
+func (l *menuLocalizer) text(translations models.Translations, field, base, baseLanguage string) string {
+	for _, language := range l.chain {
+		if language == baseLanguage {
+			return base
+		}
+		if text := translations.Text(language, field); text != "" {
+			return text
+		}
+	}
+	return base
+}
+
+// menuItems translates menu items and their categories
+func (l *menuLocalizer) menuItems(menuItems []models.MenuItem) {
+	for i := range menuItems {
+		menuItem := &menuItems[i]
+		menuItem.Name = l.text(menuItem.Translations, models.FieldName, menuItem.Name, l.shopLanguage)
+		menuItem.Description = l.text(menuItem.Translations, models.FieldDescription, menuItem.Description, l.shopLanguage)
+		menuItem.Translations = nil
+		if menuItem.Category.ID != 0 {
+			l.category(&menuItem.Category)
+		}
+	}
+}
+
+// categories translates categories' display names
+func (l *menuLocalizer) categories(categories []models.Category) {
+	for i := range categories {
+		l.category(&categories[i])
+	}
+}
+
+func (l *menuLocalizer) category(category *models.Category) {
+	category.DisplayName = l.text(category.Translations, models.FieldDisplayName, category.DisplayName, category.BaseLanguage(l.shopLanguage))
+	category.Translations = nil
+}
+
+// shop translates a shop's name and description
+func (l *menuLocalizer) shop(coffeeShop *models.CoffeeShop) {
+	coffeeShop.Name = l.text(coffeeShop.Translations, models.FieldName, coffeeShop.Name, l.shopLanguage)
+	coffeeShop.Description = l.text(coffeeShop.Translations, models.FieldDescription, coffeeShop.Description, l.shopLanguage)
+	coffeeShop.Translations = nil
+}
+
+// GetMissingTranslations lists the texts of the shop's menu that are not translated
+// into ?lang=, or into any supported language other than the shop's default
+func (h *TranslationHandler) GetMissingTranslations(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	var coffeeShop models.CoffeeShop
+	if err := database.DB.First(&coffeeShop, shopID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Coffee shop not found",
+		})
+	}
+	base := shopLanguage(&coffeeShop)
+
+	var languages []string
+	if lang := c.QueryParam("lang"); lang != "" {
+		if !models.IsSupportedLanguage(lang) {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "Unsupported language",
+			})
+		}
+		languages = []string{lang}
+	} else {
+		for _, language := range models.SupportedLanguages {
+			if language != base {
+				languages = append(languages, language)
+			}
+		}
+	}
+
+	categories, err := effectiveCategories(database.DB, shopID, true)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve categories",
+		})
+	}
+
+	var menuItems []models.MenuItem
+	if err := database.DB.Where("coffee_shop_id = ?", shopID).Order("order_index ASC, id ASC").Find(&menuItems).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve menu items",
+		})
+	}
+
+	missing := []models.MissingTranslation{}
+	check := func(entityType string, id uint, translations models.Translations, field, text, baseLanguage string) {
+		if text == "" {
+			return
+		}
+		for _, language := range languages {
+			if language != baseLanguage && translations.Text(language, field) == "" {
+				missing = append(missing, models.MissingTranslation{
+					Type:     entityType,
+					ID:       id,
+					Field:    field,
+					Language: language,
+					Text:     text,
+				})
+			}
+		}
+	}
+
+	check(models.TranslationTypeShop, coffeeShop.ID, coffeeShop.Translations, models.FieldName, coffeeShop.Name, base)
+	check(models.TranslationTypeShop, coffeeShop.ID, coffeeShop.Translations, models.FieldDescription, coffeeShop.Description, base)
+	for i := range categories {
+		category := &categories[i]
+		check(models.TranslationTypeCategory, category.ID, category.Translations, models.FieldDisplayName, category.DisplayName, category.BaseLanguage(base))
+	}
+	for _, menuItem := range menuItems {
+		check(models.TranslationTypeMenuItem, menuItem.ID, menuItem.Translations, models.FieldName, menuItem.Name, base)
+		check(models.TranslationTypeMenuItem, menuItem.ID, menuItem.Translations, models.FieldDescription, menuItem.Description, base)
+	}
+
+	return c.JSON(http.StatusOK, missing)
+}
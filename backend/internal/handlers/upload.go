@@ -0,0 +1,501 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"coffee-shop-platform/internal/config"
+	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/imaging"
+	"coffee-shop-platform/internal/models"
+	"coffee-shop-platform/internal/storage"
+	"coffee-shop-platform/internal/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// imageExtensions maps the sniffed content types accepted for upload to file
+// extensions. Every type must be one imaging can re-encode, so no upload keeps its
+// metadata.
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// multipartOverhead is allowed on top of the file size for the multipart envelope
+const multipartOverhead = 64 << 10
+
+type UploadHandler struct{}
+
+func NewUploadHandler() *UploadHandler {
+	return &UploadHandler{}
+}
+
+// uploadReferenced reports whether a menu item or shop still uses the URL. Images of
+// menu drafts, published menu versions and menu templates are kept so publishing,
+// rolling back and applying templates restore them.
+func uploadReferenced(db *gorm.DB, url string) (bool, error) {
+	var count int64
+	if err := db.Model(&models.MenuItem{}).Where("image_url = ?", url).Count(&count).Error; err != nil || count > 0 {
+		return count > 0, err
+	}
+	if err := db.Model(&models.CoffeeShop{}).Where("logo_url = ? OR hero_image_url = ?", url, url).Count(&count).Error; err != nil || count > 0 {
+		return count > 0, err
+	}
+
+	// Matches menu content with any item using the URL
+	filter, err := json.Marshal(map[string]any{"items": []map[string]string{{"image_url": url}}})
+	if err != nil {
+		return false, err
+	}
+	if err := db.Model(&models.MenuDraft{}).Where("changes @> ?::jsonb", string(filter)).Count(&count).Error; err != nil || count > 0 {
+		return count > 0, err
+	}
+	if err := db.Model(&models.MenuVersion{}).Where("content @> ?::jsonb", string(filter)).Count(&count).Error; err != nil || count > 0 {
+		return count > 0, err
+	}
+	if err := db.Model(&models.MenuTemplate{}).Where("content @> ?::jsonb", string(filter)).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// deleteUpload removes an upload's files and records
+func deleteUpload(ctx context.Context, db *gorm.DB, upload *models.Upload) error {
+	var variants []models.ImageVariant
+	if err := db.Where("upload_id = ?", upload.ID).Find(&variants).Error; err != nil {
+		return err
+	}
+	for _, variant := range variants {
+		if err := storage.Files.Delete(ctx, variant.Key); err != nil {
+			return err
+		}
+	}
+	if err := storage.Files.Delete(ctx, upload.Key); err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("upload_id = ?", upload.ID).Delete(&models.ImageVariant{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(upload).Error
+	})
+}
+
+// variantKey names a variant's file after the original's: shops/1/abc.jpg → shops/1/abc_card.jpg
+func variantKey(key, name, ext string) string {
+	return strings.TrimSuffix(key, path.Ext(key)) + "_" + name + ext
+}
+
+// storeVariants writes the variants of a processed image and returns their records
+func storeVariants(ctx context.Context, key string, result *imaging.Result) ([]models.ImageVariant, error) {
+	var variants []models.ImageVariant
+	for _, v := range imaging.Variants {
+		encoded := result.Variants[v.Name]
+		variant := models.ImageVariant{
+			Name:        v.Name,
+			Key:         variantKey(key, v.Name, encoded.Ext),
+			ContentType: encoded.ContentType,
+			Size:        int64(len(encoded.Data)),
+			Width:       encoded.Width,
+			Height:      encoded.Height,
+		}
+		if err := storage.Files.Put(ctx, variant.Key, encoded.Data, encoded.ContentType); err != nil {
+			return nil, err
+		}
+		variant.URL = storage.Files.URL(variant.Key)
+		variants = append(variants, variant)
+	}
+	return variants, nil
+}
+
+// RegenerateImageVariants recreates the variants of every decodable upload from its
+// stored original, e.g. after the variant sizes changed. Originals uploaded before
+// variants existed are re-encoded in place to strip their metadata. It returns the
+// number of regenerated uploads.
+func RegenerateImageVariants(ctx context.Context) (int, error) {
+	var uploads []models.Upload
+	if err := database.DB.Order("id ASC").Find(&uploads).Error; err != nil {
+		return 0, err
+	}
+
+	regenerated := 0
+	for i := range uploads {
+		upload := &uploads[i]
+		if !imaging.CanProcess(upload.ContentType) {
+			continue
+		}
+
+		data, err := storage.Files.Get(ctx, upload.Key)
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Printf("Skipping upload %d: %s is missing from storage", upload.ID, upload.Key)
+			continue
+		}
+		if err != nil {
+			return regenerated, err
+		}
+
+		result, err := imaging.Process(data, upload.ContentType)
+		if err != nil {
+			log.Printf("Skipping upload %d: %v", upload.ID, err)
+			continue
+		}
+
+		// The original keeps its key, so it is only replaced when the format stays the same
+		if result.Original.ContentType == upload.ContentType {
+			if err := storage.Files.Put(ctx, upload.Key, result.Original.Data, upload.ContentType); err != nil {
+				return regenerated, err
+			}
+			upload.Size = int64(len(result.Original.Data))
+			upload.Width, upload.Height = result.Original.Width, result.Original.Height
+		}
+
+		var old []models.ImageVariant
+		if err := database.DB.Where("upload_id = ?", upload.ID).Find(&old).Error; err != nil {
+			return regenerated, err
+		}
+		variants, err := storeVariants(ctx, upload.Key, result)
+		if err != nil {
+			return regenerated, err
+		}
+		for j := range variants {
+			variants[j].UploadID = upload.ID
+		}
+
+		err = database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(upload).Select("size", "width", "height").Updates(upload).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("upload_id = ?", upload.ID).Delete(&models.ImageVariant{}).Error; err != nil {
+				return err
+			}
+			return tx.Create(&variants).Error
+		})
+		if err != nil {
+			return regenerated, err
+		}
+
+		// Remove variant files whose key changed, e.g. from .png to .jpg
+		for _, variant := range old {
+			if !containsVariantKey(variants, variant.Key) {
+				storage.Files.Delete(ctx, variant.Key)
+			}
+		}
+		regenerated++
+	}
+	return regenerated, nil
+}
+
+func containsVariantKey(variants []models.ImageVariant, key string) bool {
+	for _, variant := range variants {
+		if variant.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// imageSets returns the variants of the uploads behind urls by URL. URLs that are not
+// uploads, or uploads without variants, are left out.
+func imageSets(db *gorm.DB, urls ...string) map[string]*models.ImageSet {
+	sets := make(map[string]*models.ImageSet)
+	var wanted []string
+	for _, url := range urls {
+		if url != "" {
+			wanted = append(wanted, url)
+		}
+	}
+	if len(wanted) == 0 {
+		return sets
+	}
+
+	var uploads []models.Upload
+	if err := db.Preload("Variants").Where("url IN ?", wanted).Find(&uploads).Error; err != nil {
+		return sets
+	}
+	for _, upload := range uploads {
+		if len(upload.Variants) == 0 {
+			continue
+		}
+		sort.Slice(upload.Variants, func(i, j int) bool {
+			return upload.Variants[i].Width < upload.Variants[j].Width
+		})
+
+		set := &models.ImageSet{Variants: make(map[string]string, len(upload.Variants))}
+		srcset := make([]string, 0, len(upload.Variants))
+		seenWidths := make(map[int]bool)
+		for _, variant := range upload.Variants {
+			set.Variants[variant.Name] = variant.URL
+			// Small originals give several variants of the same width
+			if !seenWidths[variant.Width] {
+				seenWidths[variant.Width] = true
+				srcset = append(srcset, fmt.Sprintf("%s %dw", variant.URL, variant.Width))
+			}
+		}
+		set.Srcset = strings.Join(srcset, ", ")
+		sets[upload.URL] = set
+	}
+	return sets
+}
+
+// withImages sets Images on menu items with uploaded images
+func withImages(menuItems []models.MenuItem) {
+	urls := make([]string, 0, len(menuItems))
+	for _, menuItem := range menuItems {
+		urls = append(urls, menuItem.ImageURL)
+	}
+	sets := imageSets(database.DB, urls...)
+	for i := range menuItems {
+		menuItems[i].Images = sets[menuItems[i].ImageURL]
+	}
+}
+
+// withShopImages sets LogoImages and HeroImages on a shop with uploaded images
+func withShopImages(coffeeShop *models.CoffeeShop) {
+	sets := imageSets(database.DB, coffeeShop.LogoURL, coffeeShop.HeroImageURL)
+	coffeeShop.LogoImages = sets[coffeeShop.LogoURL]
+	coffeeShop.HeroImages = sets[coffeeShop.HeroImageURL]
+}
+
+// releaseUploads deletes the uploaded files behind urls that nothing refers to any
+// more. URLs that are not uploads, such as pasted links, are ignored. Failures are
+// logged; the cleanup command picks up what is left.
+func releaseUploads(urls ...string) {
+	for _, url := range urls {
+		if url == "" {
+			continue
+		}
+		var upload models.Upload
+		if err := database.DB.Where("url = ?", url).First(&upload).Error; err != nil {
+			continue
+		}
+		referenced, err := uploadReferenced(database.DB, url)
+		if err != nil || referenced {
+			continue
+		}
+		if err := deleteUpload(context.Background(), database.DB, &upload); err != nil {
+			log.Printf("Failed to delete upload %s: %v", upload.Key, err)
+		}
+	}
+}
+
+// UploadImage stores an image sent as the multipart field "file". The type is sniffed
+// from the content, not taken from the file name or the client's Content-Type. JPEG,
+// PNG and GIF images are re-encoded without metadata and resized into variants; WebP
+// images are stored as sent.
+func (h *UploadHandler) UploadImage(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+	maxBytes := c.Get("config").(*config.Config).Storage.MaxUploadBytes
+	tooLarge := func() error {
+		return c.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+			Error:   "File too large",
+			Message: fmt.Sprintf("files may be at most %d bytes", maxBytes),
+		})
+	}
+
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxBytes+multipartOverhead)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) || strings.Contains(err.Error(), "request body too large") {
+			return tooLarge()
+		}
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid upload",
+			Message: "send the image as the multipart form field \"file\"",
+		})
+	}
+	if fileHeader.Size > maxBytes {
+		return tooLarge()
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid upload",
+		})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid upload",
+		})
+	}
+	if int64(len(data)) > maxBytes {
+		return tooLarge()
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return c.JSON(http.StatusUnsupportedMediaType, models.ErrorResponse{
+			Error:   "Unsupported file type",
+			Message: "upload a JPEG, PNG or GIF image",
+		})
+	}
+
+	var result *imaging.Result
+	if imaging.CanProcess(contentType) {
+		result, err = imaging.Process(data, contentType)
+		if errors.Is(err, imaging.ErrTooLarge) {
+			return c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+				Error:   "Invalid image",
+				Message: fmt.Sprintf("images can have at most %d megapixels", imaging.MaxPixels/1_000_000),
+			})
+		}
+		if err != nil {
+			return c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+				Error:   "Invalid image",
+				Message: "the image could not be decoded",
+			})
+		}
+		data, contentType, ext = result.Original.Data, result.Original.ContentType, result.Original.Ext
+	}
+
+	id, err := utils.GenerateTokenID()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to store file",
+		})
+	}
+	key := fmt.Sprintf("shops/%d/%s%s", shopID, id, ext)
+
+	upload := models.Upload{
+		CoffeeShopID: shopID,
+		Key:          key,
+		URL:          storage.Files.URL(key),
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+		Actor:        currentActor(c),
+	}
+	if result != nil {
+		upload.Width, upload.Height = result.Original.Width, result.Original.Height
+	}
+
+	ctx := c.Request().Context()
+	err = storage.Files.Put(ctx, key, data, contentType)
+	if err == nil && result != nil {
+		upload.Variants, err = storeVariants(ctx, key, result)
+	}
+	if err == nil {
+		err = database.DB.Create(&upload).Error
+	}
+	if err != nil {
+		log.Printf("Failed to store upload %s: %v", key, err)
+		storage.Files.Delete(ctx, key)
+		for _, variant := range upload.Variants {
+			storage.Files.Delete(ctx, variant.Key)
+		}
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to store file",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "File uploaded successfully",
+		Data:    upload,
+	})
+}
+
+// GetUploads lists the shop's uploads, newest first
+func (h *UploadHandler) GetUploads(c echo.Context) error {
+	shopID := c.Get("shop_id").(uint)
+
+	params, err := parseListParams(c, map[string]string{"created_at": "created_at", "size": "size"}, "-created_at")
+	if params == nil {
+		return err
+	}
+
+	var uploads []models.Upload
+	total, err := params.find(database.DB.Model(&models.Upload{}).Where("coffee_shop_id = ?", shopID), &uploads, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Variants")
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve uploads",
+		})
+	}
+
+	return c.JSON(http.StatusOK, listResponse(params, uploads, total))
+}
+
+// DeleteUpload deletes an upload that no menu item or shop uses
+func (h *UploadHandler) DeleteUpload(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid upload ID",
+		})
+	}
+
+	shopID := c.Get("shop_id").(uint)
+
+	var upload models.Upload
+	if err := database.DB.Where("id = ? AND coffee_shop_id = ?", uint(id), shopID).First(&upload).Error; err != nil {
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Upload not found",
+		})
+	}
+
+	referenced, err := uploadReferenced(database.DB, upload.URL)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to delete upload",
+		})
+	}
+	if referenced {
+		return c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error: "Upload is in use",
+		})
+	}
+
+	if err := deleteUpload(c.Request().Context(), database.DB, &upload); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to delete upload",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Upload deleted successfully",
+	})
+}
+
+// CleanupOrphanedUploads deletes uploads older than minAge that no menu item or shop
+// uses, e.g. images uploaded but never saved or left behind by deleted shops. It
+// returns the number of deleted uploads.
+func CleanupOrphanedUploads(ctx context.Context, minAge time.Duration) (int, error) {
+	var uploads []models.Upload
+	if err := database.DB.Where("created_at < ?", time.Now().Add(-minAge)).Order("id ASC").Find(&uploads).Error; err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for i := range uploads {
+		referenced, err := uploadReferenced(database.DB, uploads[i].URL)
+		if err != nil {
+			return deleted, err
+		}
+		if referenced {
+			continue
+		}
+		if err := deleteUpload(ctx, database.DB, &uploads[i]); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
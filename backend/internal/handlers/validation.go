@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"coffee-shop-platform/internal/models"
+	"coffee-shop-platform/internal/validation"
+
+	"github.com/labstack/echo/v4"
+)
+
+// invalidRequestBody writes the response to a request body that didn't bind: the
+// fields that failed validation, or a plain error when it isn't valid JSON. A request
+// type with a broken validate tag is a server error.
+func invalidRequestBody(c echo.Context, err error) error {
+	var fieldErrors validation.Errors
+	if errors.As(err, &fieldErrors) {
+		return invalidFields(c, fieldErrors...)
+	}
+	var tagErr *validation.TagError
+	if errors.As(err, &tagErr) {
+		log.Printf("Failed to validate %s %s: %v", c.Request().Method, c.Path(), err)
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to validate request",
+		})
+	}
+	return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Error: "Invalid request body",
+	})
+}
+
+// invalidFields writes the response to a request whose fields break validation
+// rules, such as rules that depend on other fields or on the database
+func invalidFields(c echo.Context, fieldErrors ...validation.FieldError) error {
+	return c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Error:   "Validation failed",
+		Message: validation.Errors(fieldErrors).Error(),
+		Fields:  fieldErrors,
+	})
+}
+
+// invalidField writes the response to a request whose field breaks a rule
+func invalidField(c echo.Context, field, rule, message string) error {
+	return invalidFields(c, validation.FieldError{Field: field, Rule: rule, Message: message})
+}
+
+// categoryNotFound writes the response to a menu item request whose category_id is
+// neither the shop's nor a template category
+func categoryNotFound(c echo.Context) error {
+	return invalidField(c, "category_id", "exists", "must be a category of the shop or a template category")
+}
+
+// pricePremiumRequired writes the response to a menu item request that turns on dual
+// pricing without a premium price
+func pricePremiumRequired(c echo.Context) error {
+	return invalidFields(c, pricePremiumRequiredError)
+}
+
+var pricePremiumRequiredError = validation.FieldError{Field: "price_premium", Rule: "required", Message: "is required when has_dual_pricing is set"}
+
+// checkMenuItemPricing checks the pricing rules of a new menu item that its tags
+// can't express: without price_tiers it needs a price, which may be 0 for free items,
+// and a price_premium when has_dual_pricing is set. It returns nil when the pricing
+// is valid.
+func checkMenuItemPricing(req *models.MenuItemCreateRequest) validation.Errors {
+	if len(req.PriceTiers) > 0 {
+		return nil
+	}
+
+	var errs validation.Errors
+	if req.Price == nil {
+		errs = append(errs, validation.FieldError{Field: "price", Rule: "required", Message: "is required without price_tiers"})
+	}
+	if req.HasDualPricing && req.PricePremium == nil {
+		errs = append(errs, pricePremiumRequiredError)
+	}
+	return errs
+}
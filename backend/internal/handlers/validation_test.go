@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"testing"
+
+	"coffee-shop-platform/internal/models"
+)
+
+func TestCheckMenuItemPricing(t *testing.T) {
+	free, price := 0, 45000
+
+	tests := []struct {
+		name string
+		req  models.MenuItemCreateRequest
+		want []string
+	}{
+		{"price", models.MenuItemCreateRequest{Price: &price}, nil},
+		{"free", models.MenuItemCreateRequest{Price: &free}, nil},
+		{"free tier", models.MenuItemCreateRequest{PriceTiers: []models.MenuItemPriceTierRequest{{Label: "Water", Price: 0}}}, nil},
+		{"no price", models.MenuItemCreateRequest{}, []string{"price"}},
+		{"dual pricing without premium", models.MenuItemCreateRequest{Price: &price, HasDualPricing: true}, []string{"price_premium"}},
+		{"tiers replace dual pricing", models.MenuItemCreateRequest{HasDualPricing: true, PriceTiers: []models.MenuItemPriceTierRequest{{Label: "Small", Price: 1}}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, fieldError := range checkMenuItemPricing(&tt.req) {
+				got = append(got, fieldError.Field)
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("checkMenuItemPricing() failed %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Package i18n picks the language of a response from what the client asks for.
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Chain returns the languages to show content in, best first: lang (e.g. from a
+// ?lang= parameter), then the languages of an Accept-Language header by quality, and
+// finally defaultLanguage. Only supported languages are included, each once. Region
+// subtags are ignored, so en-US and en-GB both ask for en.
+func Chain(lang, acceptLanguage string, supported []string, defaultLanguage string) []string {
+	var chain []string
+	add := func(language string) {
+		language = primaryTag(language)
+		if !contains(supported, language) || contains(chain, language) {
+			return
+		}
+		chain = append(chain, language)
+	}
+
+	add(lang)
+	for _, language := range ParseAcceptLanguage(acceptLanguage) {
+		add(language)
+	}
+	if !contains(chain, defaultLanguage) {
+		chain = append(chain, defaultLanguage)
+	}
+	return chain
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header ordered
+// by quality, leaving out "*" and tags with q=0
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(name) != "q" {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				q = 0
+			}
+			quality = q
+		}
+		if quality <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].quality > tags[j].quality
+	})
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+// primaryTag returns the lowercased language of a tag without its region: fa-IR → fa
+func primaryTag(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	return tag
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
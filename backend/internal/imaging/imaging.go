@@ -0,0 +1,199 @@
+// Package imaging re-encodes uploaded images and resizes them into responsive variants
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// Variant is a named width images are resized to
+type Variant struct {
+	Name  string
+	Width int
+}
+
+// Variants are the sizes generated for every image, smallest first
+var Variants = []Variant{
+	{Name: "thumbnail", Width: 160},
+	{Name: "card", Width: 480},
+	{Name: "hero", Width: 1280},
+}
+
+// MaxOriginalWidth caps the width of the re-encoded original
+const MaxOriginalWidth = 2560
+
+// MaxPixels caps the size of images that are decoded, since a small file can declare
+// huge dimensions and every pixel takes 4 bytes once decoded
+const MaxPixels = 40_000_000
+
+const jpegQuality = 82
+
+var (
+	// ErrUnsupported is returned for image formats that cannot be decoded
+	ErrUnsupported = errors.New("imaging: unsupported image format")
+	// ErrTooLarge is returned for images with more than MaxPixels pixels
+	ErrTooLarge = errors.New("imaging: image dimensions too large")
+)
+
+// Image is an encoded image
+type Image struct {
+	Data        []byte
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+}
+
+// Result is a re-encoded original and its variants by name
+type Result struct {
+	Original Image
+	Variants map[string]Image
+}
+
+// CanProcess reports whether images of the content type can be decoded
+func CanProcess(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+		return true
+	}
+	return false
+}
+
+// Process decodes an image, applies its EXIF orientation and re-encodes it without
+// metadata, along with one copy per variant. Variants are never wider than the
+// original, so a small image gets copies at its own size. Images with more than
+// MaxPixels pixels are rejected with ErrTooLarge before they are decoded.
+func Process(data []byte, contentType string) (*Result, error) {
+	var decodeConfig func(io.Reader) (image.Config, error)
+	var decode func(io.Reader) (image.Image, error)
+	switch contentType {
+	case "image/jpeg":
+		decodeConfig, decode = jpeg.DecodeConfig, jpeg.Decode
+	case "image/png":
+		decodeConfig, decode = png.DecodeConfig, png.Decode
+	case "image/gif":
+		// Only the first frame of animations is kept
+		decodeConfig, decode = gif.DecodeConfig, gif.Decode
+	default:
+		return nil, ErrUnsupported
+	}
+
+	config, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || int64(config.Width)*int64(config.Height) > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	src, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if contentType == "image/jpeg" {
+		src = orient(src, jpegOrientation(data))
+	}
+
+	rgba := toNRGBA(src)
+	result := &Result{Variants: make(map[string]Image, len(Variants))}
+
+	result.Original, err = encode(resize(rgba, MaxOriginalWidth))
+	if err != nil {
+		return nil, err
+	}
+	for _, variant := range Variants {
+		encoded, err := encode(resize(rgba, variant.Width))
+		if err != nil {
+			return nil, err
+		}
+		result.Variants[variant.Name] = encoded
+	}
+	return result, nil
+}
+
+// encode writes opaque images as JPEG and images with transparency as PNG
+func encode(img *image.NRGBA) (Image, error) {
+	var buf bytes.Buffer
+	encoded := Image{Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+	if img.Opaque() {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return Image{}, err
+		}
+		encoded.ContentType, encoded.Ext = "image/jpeg", ".jpg"
+	} else {
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := encoder.Encode(&buf, img); err != nil {
+			return Image{}, err
+		}
+		encoded.ContentType, encoded.Ext = "image/png", ".png"
+	}
+	encoded.Data = buf.Bytes()
+	return encoded, nil
+}
+
+func toNRGBA(src image.Image) *image.NRGBA {
+	if img, ok := src.(*image.NRGBA); ok && img.Rect.Min == (image.Point{}) {
+		return img
+	}
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// resize scales img down to width, keeping the aspect ratio, by averaging the source
+// pixels each target pixel covers. Images already narrower are returned unchanged.
+func resize(img *image.NRGBA, width int) *image.NRGBA {
+	sw, sh := img.Rect.Dx(), img.Rect.Dy()
+	if sw <= width {
+		return img
+	}
+	height := sh * width / sw
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*sh/height, (y+1)*sh/height
+		if y1 == y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0, x1 := x*sw/width, (x+1)*sw/width
+			if x1 == x0 {
+				x1 = x0 + 1
+			}
+
+			// Average with colors weighted by alpha so transparent pixels don't darken edges
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := img.Pix[sy*img.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					alpha := uint64(p[3])
+					r += uint64(p[0]) * alpha
+					g += uint64(p[1]) * alpha
+					b += uint64(p[2]) * alpha
+					a += alpha
+					n++
+				}
+			}
+
+			d := dst.Pix[y*dst.Stride+x*4:]
+			if a > 0 {
+				d[0] = uint8((r + a/2) / a)
+				d[1] = uint8((g + a/2) / a)
+				d[2] = uint8((b + a/2) / a)
+			}
+			d[3] = uint8((a + n/2) / n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,177 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestResize(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		target        int
+		wantW, wantH  int
+	}{
+		{"halves", 4, 2, 2, 2, 1},
+		{"keeps aspect ratio", 300, 200, 150, 150, 100},
+		{"never enlarges", 100, 50, 160, 100, 50},
+		{"keeps at least one row", 1000, 1, 10, 10, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resize(image.NewNRGBA(image.Rect(0, 0, tt.width, tt.height)), tt.target)
+			if got.Rect.Dx() != tt.wantW || got.Rect.Dy() != tt.wantH {
+				t.Errorf("resize(%dx%d, %d) = %dx%d, want %dx%d", tt.width, tt.height, tt.target, got.Rect.Dx(), got.Rect.Dy(), tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestResizeAveragesByAlpha(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 200, G: 100, B: 0, A: 255})
+	// A transparent black pixel must not darken its opaque neighbour
+	img.SetNRGBA(1, 0, color.NRGBA{})
+
+	got := resize(img, 1).NRGBAAt(0, 0)
+	want := color.NRGBA{R: 200, G: 100, B: 0, A: 128}
+	if got != want {
+		t.Errorf("resize averaged to %v, want %v", got, want)
+	}
+}
+
+// numbered returns a w×h image whose pixel at (x, y) has red x and green y
+func numbered(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), A: 255})
+		}
+	}
+	return img
+}
+
+func TestOrient(t *testing.T) {
+	// The source is 3×2; each case lists where its top-left and top-right pixels end up
+	tests := []struct {
+		orientation       int
+		wantW, wantH      int
+		topLeft, topRight image.Point
+	}{
+		{1, 3, 2, image.Pt(0, 0), image.Pt(2, 0)},
+		{2, 3, 2, image.Pt(2, 0), image.Pt(0, 0)},
+		{3, 3, 2, image.Pt(2, 1), image.Pt(0, 1)},
+		{4, 3, 2, image.Pt(0, 1), image.Pt(2, 1)},
+		{5, 2, 3, image.Pt(0, 0), image.Pt(0, 2)},
+		{6, 2, 3, image.Pt(1, 0), image.Pt(1, 2)},
+		{7, 2, 3, image.Pt(1, 2), image.Pt(1, 0)},
+		{8, 2, 3, image.Pt(0, 2), image.Pt(0, 0)},
+	}
+	for _, tt := range tests {
+		got := toNRGBA(orient(numbered(3, 2), tt.orientation))
+		if got.Rect.Dx() != tt.wantW || got.Rect.Dy() != tt.wantH {
+			t.Errorf("orientation %d: size %dx%d, want %dx%d", tt.orientation, got.Rect.Dx(), got.Rect.Dy(), tt.wantW, tt.wantH)
+			continue
+		}
+		if c := got.NRGBAAt(tt.topLeft.X, tt.topLeft.Y); c.R != 0 || c.G != 0 {
+			t.Errorf("orientation %d: pixel at %v came from (%d,%d), want the top-left", tt.orientation, tt.topLeft, c.R, c.G)
+		}
+		if c := got.NRGBAAt(tt.topRight.X, tt.topRight.Y); c.R != 2 || c.G != 0 {
+			t.Errorf("orientation %d: pixel at %v came from (%d,%d), want the top-right", tt.orientation, tt.topRight, c.R, c.G)
+		}
+	}
+}
+
+// exifJPEG returns the start of a JPEG with an APP1 Exif segment holding the
+// orientation in the given byte order
+func exifJPEG(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], 0x0112)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(data[4:], uint16(len(segment)+2))
+	return append(append(data, segment...), 0xFF, 0xDA, 0, 2)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"little endian", exifJPEG(binary.LittleEndian, 6), 6},
+		{"big endian", exifJPEG(binary.BigEndian, 8), 8},
+		{"out of range", exifJPEG(binary.BigEndian, 9), 1},
+		{"no exif", []byte{0xFF, 0xD8, 0xFF, 0xDA, 0, 2}, 1},
+		{"not a jpeg", []byte("GIF89a"), 1},
+		{"truncated", exifJPEG(binary.LittleEndian, 6)[:20], 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Errorf("jpegOrientation() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcess(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, numbered(3000, 10)); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Process(buf.Bytes(), "image/png")
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if result.Original.Width != MaxOriginalWidth || result.Original.ContentType != "image/jpeg" {
+		t.Errorf("original is %dpx %s, want %dpx image/jpeg", result.Original.Width, result.Original.ContentType, MaxOriginalWidth)
+	}
+	for _, variant := range Variants {
+		if got := result.Variants[variant.Name].Width; got != variant.Width {
+			t.Errorf("variant %s is %dpx wide, want %d", variant.Name, got, variant.Width)
+		}
+	}
+
+	if _, err := Process(buf.Bytes(), "image/webp"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Process(webp) error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestProcessRejectsHugeDimensions(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+
+	// Declare 10000×10000 pixels in the IHDR chunk, which follows the 8-byte
+	// signature, and fix up its CRC
+	data := buf.Bytes()
+	ihdr := data[8 : 8+8+13+4]
+	binary.BigEndian.PutUint32(ihdr[8:], 10000)
+	binary.BigEndian.PutUint32(ihdr[12:], 10000)
+	binary.BigEndian.PutUint32(ihdr[21:], crc32.ChecksumIEEE(ihdr[4:21]))
+
+	if _, err := Process(data, "image/png"); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Process() error = %v, want ErrTooLarge", err)
+	}
+}
@@ -37,17 +37,49 @@ func AuthMiddleware() echo.MiddlewareFunc {
 				})
 			}
 
-			// Store user info in context
-			c.Set("user_id", (*claims)["user_id"])
+			// Tokens of logged out or compromised sessions are rejected before they expire
+			familyID, _ := (*claims)["fid"].(string)
+			if familyID == "" || tokenFamilyRevoked(familyID) {
+				return c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+					Error: "Token has been revoked",
+				})
+			}
+
+			// Store user info in context. Numeric claims are decoded as float64,
+			// handlers expect uint IDs.
+			if userID, ok := (*claims)["user_id"].(float64); ok {
+				c.Set("user_id", uint(userID))
+			}
 			c.Set("username", (*claims)["username"])
 			c.Set("user_type", (*claims)["type"])
-			c.Set("shop_id", (*claims)["shop_id"])
+			if shopID, ok := (*claims)["shop_id"].(float64); ok {
+				c.Set("shop_id", uint(shopID))
+			}
+			c.Set("role", (*claims)["role"])
+			var permissions []string
+			if perms, ok := (*claims)["perms"].([]any); ok {
+				for _, p := range perms {
+					if name, ok := p.(string); ok {
+						permissions = append(permissions, name)
+					}
+				}
+			}
+			c.Set("permissions", permissions)
 
 			return next(c)
 		}
 	}
 }
 
+// tokenFamilyRevoked reports whether the session a token belongs to was revoked or no longer exists
+func tokenFamilyRevoked(familyID string) bool {
+	var family models.TokenFamily
+	if err := database.DB.Select("id", "revoked_at").Where("id = ?", familyID).First(&family).Error; err != nil {
+		return true
+	}
+	return family.RevokedAt != nil
+}
+
 func MainAdminOnly() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -76,26 +108,68 @@ func ShopAdminOnly() echo.MiddlewareFunc {
 	}
 }
 
-func TenantResolver() echo.MiddlewareFunc {
+// RequirePermission allows shop staff holding every one of the given permissions
+func RequirePermission(permissions ...string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			// Extract subdomain from Host header
-			host := c.Request().Host
-			parts := strings.Split(host, ".")
-			
-			if len(parts) >= 2 {
-				subdomain := parts[0]
-				
-				// Skip if it's localhost or IP
-				if subdomain != "localhost" && subdomain != "127" && subdomain != "0" {
-					var tenant models.Tenant
-					if err := database.DB.Where("subdomain = ? AND is_active = ?", subdomain, true).First(&tenant).Error; err == nil {
-						c.Set("tenant_id", tenant.ID)
-						c.Set("tenant", tenant)
-					}
+			for _, permission := range permissions {
+				if !HasPermission(c, permission) {
+					return c.JSON(http.StatusForbidden, models.ErrorResponse{
+						Error:   "Permission denied",
+						Message: "requires " + permission,
+					})
 				}
 			}
+			return next(c)
+		}
+	}
+}
 
+// RequireAnyPermission allows shop staff holding at least one of the given
+// permissions; the handler checks the rest at field level
+func RequireAnyPermission(permissions ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			for _, permission := range permissions {
+				if HasPermission(c, permission) {
+					return next(c)
+				}
+			}
+			return c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Permission denied",
+				Message: "requires one of " + strings.Join(permissions, ", "),
+			})
+		}
+	}
+}
+
+// HasPermission reports whether the authenticated user was granted permission
+func HasPermission(c echo.Context, permission string) bool {
+	permissions, _ := c.Get("permissions").([]string)
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenFromQuery lets clients that can't set headers, such as the browser EventSource,
+// pass the JWT as ?access_token=. The token is removed from the request URI so it
+// doesn't end up in access logs.
+func TokenFromQuery() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			query := req.URL.Query()
+			if token := query.Get("access_token"); token != "" {
+				if req.Header.Get("Authorization") == "" {
+					req.Header.Set("Authorization", "Bearer "+token)
+				}
+				query.Del("access_token")
+				req.URL.RawQuery = query.Encode()
+				req.RequestURI = req.URL.RequestURI()
+			}
 			return next(c)
 		}
 	}
@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"coffee-shop-platform/internal/config"
+	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TenantHeader lets trusted internal callers choose the tenant explicitly
+const TenantHeader = "X-Tenant"
+
+// tenantPathKey holds the subdomain taken from a /t/:subdomain path prefix
+const tenantPathKey = "tenant_path_subdomain"
+
+// TenantPathPrefix strips a /t/:subdomain prefix from the request path before routing,
+// so /t/demo/api/public/menu is served as /api/public/menu for tenant "demo". It only
+// applies on hosts shared by all tenants (see sharedHost), never on a tenant's own
+// domain. Register it with e.Pre, after the config is stored in the context.
+func TenantPathPrefix() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cfg := c.Get("config").(*config.Config)
+			req := c.Request()
+			if rest, ok := strings.CutPrefix(req.URL.Path, "/t/"); ok && sharedHost(requestHost(c), &cfg.Tenant) {
+				subdomain, path, _ := strings.Cut(rest, "/")
+				if subdomain != "" {
+					c.Set(tenantPathKey, subdomain)
+					req.URL.Path = "/" + path
+					req.URL.RawPath = ""
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// TenantResolver resolves the tenant of a public request. In strict mode
+// (TENANT_STRICT) requests that match no tenant get a 404.
+func TenantResolver() echo.MiddlewareFunc {
+	return tenantResolver(true)
+}
+
+// OptionalTenantResolver resolves the tenant like TenantResolver but never rejects the
+// request, for routes that also serve users without a tenant such as the main admin
+func OptionalTenantResolver() echo.MiddlewareFunc {
+	return tenantResolver(false)
+}
+
+func tenantResolver(enforceStrict bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cfg := c.Get("config").(*config.Config)
+
+			if tenant := resolveTenant(c, &cfg.Tenant); tenant != nil {
+				c.Set("tenant_id", tenant.ID)
+				c.Set("tenant", *tenant)
+			} else if enforceStrict && cfg.Tenant.Strict {
+				return c.JSON(http.StatusNotFound, models.ErrorResponse{
+					Error: "Tenant not found",
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// resolveTenant finds the active tenant of a request, in order of precedence: the
+// X-Tenant header from a trusted caller, the /t/:subdomain path prefix, a verified
+// custom domain, then the subdomain of the Host header
+func resolveTenant(c echo.Context, cfg *config.TenantConfig) *models.Tenant {
+	if subdomain := c.Request().Header.Get(TenantHeader); subdomain != "" && fromTrustedProxy(c, cfg) {
+		return tenantBySubdomain(subdomain)
+	}
+
+	if subdomain, ok := c.Get(tenantPathKey).(string); ok {
+		return tenantBySubdomain(subdomain)
+	}
+
+	host := requestHost(c)
+
+	// Skip localhost and IPs
+	if host == "localhost" || net.ParseIP(host) != nil {
+		return nil
+	}
+
+	if tenant := tenantByDomain(host); tenant != nil {
+		return tenant
+	}
+
+	var subdomain string
+	if cfg.BaseDomain != "" {
+		subdomain, _ = strings.CutSuffix(host, "."+cfg.BaseDomain)
+		if subdomain == host || strings.Contains(subdomain, ".") {
+			return nil
+		}
+	} else {
+		parts := strings.Split(host, ".")
+		if len(parts) < 2 {
+			return nil
+		}
+		subdomain = parts[0]
+	}
+
+	return tenantBySubdomain(subdomain)
+}
+
+// requestHost returns the lowercased Host of a request without port or trailing dot
+func requestHost(c echo.Context) string {
+	host := strings.ToLower(c.Request().Host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+// sharedHost reports whether host serves all tenants, so a /t/:subdomain path may
+// choose one: localhost and IPs, the base domain itself and TENANT_SHARED_HOSTS, but
+// never a tenant's custom domain
+func sharedHost(host string, cfg *config.TenantConfig) bool {
+	if host == "localhost" || net.ParseIP(host) != nil {
+		return true
+	}
+
+	shared := cfg.BaseDomain != "" && host == cfg.BaseDomain
+	for _, sharedHost := range cfg.SharedHosts {
+		shared = shared || strings.EqualFold(host, sharedHost)
+	}
+	return shared && tenantByDomain(host) == nil
+}
+
+// fromTrustedProxy reports whether the direct peer of the request is in one of the
+// trusted CIDRs. Forwarding headers are ignored as clients can set them.
+func fromTrustedProxy(c echo.Context, cfg *config.TenantConfig) bool {
+	host, _, err := net.SplitHostPort(c.Request().RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, cidr := range cfg.TrustedProxies {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			if trusted := net.ParseIP(cidr); trusted != nil && trusted.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func tenantBySubdomain(subdomain string) *models.Tenant {
+	var tenant models.Tenant
+	if err := database.DB.Where("subdomain = ? AND is_active = ?", strings.ToLower(subdomain), true).First(&tenant).Error; err != nil {
+		return nil
+	}
+	return &tenant
+}
+
+// tenantByDomain returns the active tenant of a verified custom domain
+func tenantByDomain(domain string) *models.Tenant {
+	var tenantDomain models.TenantDomain
+	if err := database.DB.Preload("Tenant", "is_active = ?", true).
+		Where("domain = ? AND verified_at IS NOT NULL", domain).
+		First(&tenantDomain).Error; err != nil || tenantDomain.Tenant.ID == 0 {
+		return nil
+	}
+	return &tenantDomain.Tenant
+}
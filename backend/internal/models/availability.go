@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// AvailabilityWindow is a weekly time range in which a menu item, or every item of a
+// category, can be ordered, such as a breakfast menu. Items and categories without
+// windows are available all day. Times are in the shop's time zone; an EndsAt at or
+// before StartsAt runs past midnight.
+type AvailabilityWindow struct {
+	ID           uint         `json:"id" gorm:"primaryKey"`
+	CoffeeShopID uint         `json:"-" gorm:"not null;index"`
+	MenuItemID   *uint        `json:"menu_item_id,omitempty" gorm:"index"`
+	CategoryID   *uint        `json:"category_id,omitempty" gorm:"index"`
+	Weekday      time.Weekday `json:"weekday" gorm:"not null"`
+	StartsAt     string       `json:"starts_at" gorm:"size:5;not null"`
+	EndsAt       string       `json:"ends_at" gorm:"size:5;not null"`
+}
+
+// AvailabilityWindowRequest is a time range on one or more days of the week (0 = Sunday)
+type AvailabilityWindowRequest struct {
+	Weekdays []time.Weekday `json:"weekdays" validate:"required,min=1"`
+	StartsAt string         `json:"starts_at" validate:"required"`
+	EndsAt   string         `json:"ends_at" validate:"required"`
+}
+
+// AvailabilityUpdateRequest replaces the availability windows of a menu item or
+// category; an empty list makes it available all day
+type AvailabilityUpdateRequest struct {
+	Windows []AvailabilityWindowRequest `json:"windows" validate:"dive"`
+}
+
+// AvailabilityResponse is a schedule with the availability it gives right now
+type AvailabilityResponse struct {
+	Windows      []AvailabilityWindow `json:"windows"`
+	AvailableNow bool                 `json:"available_now"`
+	NextChangeAt *time.Time           `json:"next_change_at"`
+}
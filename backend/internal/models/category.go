@@ -6,23 +6,86 @@ import (
 	"gorm.io/gorm"
 )
 
-// Category represents menu categories managed by main admin
+// Category represents a menu category. Categories without a CoffeeShopID are
+// platform-wide templates managed by the main admin; the others are owned by a shop.
 type Category struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	Name        string         `json:"name" gorm:"not null"`
-	DisplayName string         `json:"display_name" gorm:"not null"`
-	Emoji       string         `json:"emoji"`
-	Color       string         `json:"color"`
-	OrderIndex  int            `json:"order_index" gorm:"default:0"`
-	IsActive    bool           `json:"is_active" gorm:"default:true"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	CoffeeShopID *uint          `json:"coffee_shop_id" gorm:"index"`
+	Name         string         `json:"name" gorm:"not null"`
+	DisplayName  string         `json:"display_name" gorm:"not null"`
+	Emoji        string         `json:"emoji"`
+	Color        string         `json:"color"`
+	OrderIndex   int            `json:"order_index" gorm:"default:0"`
+	IsActive     bool           `json:"is_active" gorm:"default:true"`
+	Translations Translations   `json:"translations,omitempty" gorm:"type:jsonb"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+
+	// Overridden is set on effective categories when a shop override was applied
+	Overridden bool `json:"overridden,omitempty" gorm:"-"`
+	// displayNameOverridden is set when the override replaced the template's display name
+	displayNameOverridden bool
 
 	// Relations
 	MenuItems []MenuItem `json:"menu_items,omitempty" gorm:"foreignKey:CategoryID"`
 }
 
+// IsTemplate reports whether the category is a platform-wide template
+func (c *Category) IsTemplate() bool {
+	return c.CoffeeShopID == nil
+}
+
+// BaseLanguage returns the language DisplayName is written in for a shop with the
+// given default language: the template's unless the shop overrode it
+func (c *Category) BaseLanguage(shopLanguage string) string {
+	if c.IsTemplate() && !c.displayNameOverridden {
+		return TemplateLanguage
+	}
+	return shopLanguage
+}
+
+// CategoryOverride customizes a template category for one shop
+type CategoryOverride struct {
+	ID           uint    `json:"id" gorm:"primaryKey"`
+	CoffeeShopID uint    `json:"coffee_shop_id" gorm:"not null;uniqueIndex:idx_category_overrides_shop_category"`
+	CategoryID   uint    `json:"category_id" gorm:"not null;uniqueIndex:idx_category_overrides_shop_category"`
+	DisplayName  *string `json:"display_name"`
+	Emoji        *string `json:"emoji"`
+	Color        *string `json:"color"`
+	OrderIndex   *int    `json:"order_index"`
+	IsActive     *bool   `json:"is_active"`
+	// Translations are merged over the template's; translations of the template's
+	// display name are dropped when DisplayName is set
+	Translations Translations `json:"translations,omitempty" gorm:"type:jsonb"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// Apply returns the category with the override's fields applied
+func (o *CategoryOverride) Apply(category Category) Category {
+	if o.DisplayName != nil {
+		category.DisplayName = *o.DisplayName
+		category.Translations = category.Translations.Without(FieldDisplayName)
+		category.displayNameOverridden = true
+	}
+	category.Translations = category.Translations.Merge(o.Translations)
+	if o.Emoji != nil {
+		category.Emoji = *o.Emoji
+	}
+	if o.Color != nil {
+		category.Color = *o.Color
+	}
+	if o.OrderIndex != nil {
+		category.OrderIndex = *o.OrderIndex
+	}
+	if o.IsActive != nil {
+		category.IsActive = *o.IsActive
+	}
+	category.Overridden = true
+	return category
+}
+
 // CategoryCreateRequest represents the request to create a category
 type CategoryCreateRequest struct {
 	Name        string `json:"name" validate:"required,min=2,max=50"`
@@ -30,6 +93,8 @@ type CategoryCreateRequest struct {
 	Emoji       string `json:"emoji" validate:"omitempty,max=10"`
 	Color       string `json:"color" validate:"omitempty,max=50"`
 	OrderIndex  int    `json:"order_index" validate:"min=0"`
+	// Translations of the display name by language
+	Translations Translations `json:"translations,omitempty"`
 }
 
 // CategoryUpdateRequest represents the request to update a category
@@ -40,4 +105,18 @@ type CategoryUpdateRequest struct {
 	Color       *string `json:"color,omitempty" validate:"omitempty,max=50"`
 	OrderIndex  *int    `json:"order_index,omitempty" validate:"omitempty,min=0"`
 	IsActive    *bool   `json:"is_active,omitempty"`
+	// Translations replaces all translations of the display name when provided
+	Translations Translations `json:"translations,omitempty"`
+}
+
+// CategoryOverrideRequest represents a shop's override of a template category.
+// Fields left out fall back to the template.
+type CategoryOverrideRequest struct {
+	DisplayName *string `json:"display_name,omitempty" validate:"omitempty,min=2,max=100"`
+	Emoji       *string `json:"emoji,omitempty" validate:"omitempty,max=10"`
+	Color       *string `json:"color,omitempty" validate:"omitempty,max=50"`
+	OrderIndex  *int    `json:"order_index,omitempty" validate:"omitempty,min=0"`
+	IsActive    *bool   `json:"is_active,omitempty"`
+	// Translations of the display name, used instead of the template's
+	Translations Translations `json:"translations,omitempty"`
 }
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Allergens are the 14 allergens menus must declare under EU FIC, in the order
+// they are listed
+var Allergens = []string{
+	"gluten",
+	"crustaceans",
+	"eggs",
+	"fish",
+	"peanuts",
+	"soy",
+	"milk",
+	"nuts",
+	"celery",
+	"mustard",
+	"sesame",
+	"sulphites",
+	"lupin",
+	"molluscs",
+}
+
+// DietaryTags are the tags menu items can be filtered by
+var DietaryTags = []string{
+	"vegan",
+	"vegetarian",
+	"gluten-free",
+	"lactose-free",
+	"sugar-free",
+	"decaf",
+}
+
+// DietaryInfo tells customers what is in a menu item. Allergens and DietaryTags only
+// hold names from Allergens and DietaryTags. AllergensDeclared tells an item without
+// allergens apart from one whose allergens are unknown.
+type DietaryInfo struct {
+	Ingredients       StringList `json:"ingredients,omitempty" gorm:"type:jsonb"`
+	Allergens         StringList `json:"allergens,omitempty" gorm:"type:jsonb"`
+	AllergensDeclared bool       `json:"allergens_declared" gorm:"not null;default:false"`
+	DietaryTags       StringList `json:"dietary_tags,omitempty" gorm:"type:jsonb"`
+	Nutrition         Nutrition  `json:"nutrition" gorm:"embedded;embeddedPrefix:nutrition_"`
+}
+
+// Nutrition holds the optional nutrition facts of one serving
+type Nutrition struct {
+	Calories   *int `json:"calories,omitempty"`
+	CaffeineMg *int `json:"caffeine_mg,omitempty"`
+}
+
+// Normalize checks d and returns it with ingredients trimmed, and allergens and tags
+// lowercased, deduplicated and in list order. Sending an allergen list, even an
+// empty one, declares the allergens.
+func (d DietaryInfo) Normalize() (DietaryInfo, error) {
+	if d.Allergens != nil {
+		d.AllergensDeclared = true
+	}
+
+	var err error
+	if d.Allergens, err = normalizeNames(d.Allergens, Allergens, "allergen"); err != nil {
+		return d, err
+	}
+	if d.DietaryTags, err = normalizeNames(d.DietaryTags, DietaryTags, "dietary tag"); err != nil {
+		return d, err
+	}
+
+	var ingredients StringList
+	for _, ingredient := range d.Ingredients {
+		if ingredient = strings.TrimSpace(ingredient); ingredient != "" {
+			ingredients = append(ingredients, ingredient)
+		}
+	}
+	d.Ingredients = ingredients
+
+	if d.Nutrition.Calories != nil && *d.Nutrition.Calories < 0 {
+		return d, errors.New("calories can't be negative")
+	}
+	if d.Nutrition.CaffeineMg != nil && *d.Nutrition.CaffeineMg < 0 {
+		return d, errors.New("caffeine_mg can't be negative")
+	}
+	return d, nil
+}
+
+// ParseAllergens reads a comma-separated list of allergens, e.g. "milk,nuts"
+func ParseAllergens(s string) (StringList, error) {
+	return normalizeNames(strings.Split(s, ","), Allergens, "allergen")
+}
+
+// ParseDietaryTags reads a comma-separated list of dietary tags, e.g. "vegan,decaf"
+func ParseDietaryTags(s string) (StringList, error) {
+	return normalizeNames(strings.Split(s, ","), DietaryTags, "dietary tag")
+}
+
+// normalizeNames returns the names of known that are in names, in the order of known,
+// or nil when there are none. Unknown names are an error.
+func normalizeNames(names []string, known []string, kind string) (StringList, error) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !containsField(known, name) {
+			return nil, fmt.Errorf("unknown %s %q", kind, name)
+		}
+		wanted[name] = true
+	}
+
+	var result StringList
+	for _, name := range known {
+		if wanted[name] {
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
+// HasAny reports whether l contains any of names
+func (l StringList) HasAny(names []string) bool {
+	for _, name := range names {
+		if containsField(l, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAll reports whether l contains all of names
+func (l StringList) HasAll(names []string) bool {
+	for _, name := range names {
+		if !containsField(l, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// StringList is a list of strings stored as a JSON array
+type StringList []string
+
+// Value stores the list as JSON, or NULL when it is empty
+func (l StringList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan reads a list stored as JSON
+func (l *StringList) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	case nil:
+		*l = nil
+		return nil
+	}
+	return errors.New("unsupported string list value")
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Reasons of stock adjustments. Order reasons are set by the server.
+const (
+	StockReasonRestock        = "restock"
+	StockReasonWaste          = "waste"
+	StockReasonCorrection     = "correction"
+	StockReasonStocktake      = "stocktake"
+	StockReasonOrder          = "order"
+	StockReasonOrderCancelled = "order_cancelled"
+)
+
+// ManualStockReasons are the reasons a shop admin may give for an adjustment
+var ManualStockReasons = []string{
+	StockReasonRestock,
+	StockReasonWaste,
+	StockReasonCorrection,
+	StockReasonStocktake,
+}
+
+// IsManualStockReason reports whether r is one of ManualStockReasons
+func IsManualStockReason(r string) bool {
+	for _, reason := range ManualStockReasons {
+		if reason == r {
+			return true
+		}
+	}
+	return false
+}
+
+// Ingredient is a stocked ingredient of a shop, counted in Unit (g, ml, pcs...)
+type Ingredient struct {
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	CoffeeShopID      uint           `json:"coffee_shop_id" gorm:"not null;index"`
+	Name              string         `json:"name" gorm:"not null"`
+	Unit              string         `json:"unit" gorm:"not null"`
+	Stock             float64        `json:"stock" gorm:"not null;default:0"`
+	LowStockThreshold *float64       `json:"low_stock_threshold"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// IsLowStock reports whether the stock is at or below the low-stock threshold
+func (i *Ingredient) IsLowStock() bool {
+	return i.LowStockThreshold != nil && i.Stock <= *i.LowStockThreshold
+}
+
+// RecipeLine is the quantity of an ingredient one serving of a menu item consumes
+type RecipeLine struct {
+	ID           uint    `json:"id" gorm:"primaryKey"`
+	MenuItemID   uint    `json:"menu_item_id" gorm:"not null;uniqueIndex:idx_recipe_lines_item_ingredient"`
+	IngredientID uint    `json:"ingredient_id" gorm:"not null;uniqueIndex:idx_recipe_lines_item_ingredient"`
+	Quantity     float64 `json:"quantity" gorm:"not null"`
+
+	// Relations
+	Ingredient Ingredient `json:"ingredient,omitempty" gorm:"foreignKey:IngredientID"`
+}
+
+// StockAdjustment logs a change to the stock of an ingredient or a menu item's count.
+// Exactly one of IngredientID and MenuItemID is set; OrderID is set for changes made
+// by placing or cancelling an order.
+type StockAdjustment struct {
+	ID           uint    `json:"id" gorm:"primaryKey"`
+	CoffeeShopID uint    `json:"coffee_shop_id" gorm:"not null;index"`
+	IngredientID *uint   `json:"ingredient_id,omitempty" gorm:"index"`
+	MenuItemID   *uint   `json:"menu_item_id,omitempty" gorm:"index"`
+	OrderID      *uint   `json:"order_id,omitempty" gorm:"index"`
+	Delta        float64 `json:"delta" gorm:"not null"`
+	StockAfter   float64 `json:"stock_after" gorm:"not null"`
+	Reason       string  `json:"reason" gorm:"not null"`
+	Note         string  `json:"note"`
+	Actor        `gorm:"embedded"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
+}
+
+// IngredientCreateRequest represents the request to add an ingredient
+type IngredientCreateRequest struct {
+	Name              string   `json:"name" validate:"required,max=100"`
+	Unit              string   `json:"unit" validate:"required,max=20"`
+	Stock             float64  `json:"stock" validate:"min=0"`
+	LowStockThreshold *float64 `json:"low_stock_threshold,omitempty" validate:"omitempty,min=0"`
+}
+
+// IngredientUpdateRequest represents the request to update an ingredient. Stock is
+// changed through adjustments only.
+type IngredientUpdateRequest struct {
+	Name              *string  `json:"name,omitempty" validate:"omitempty,max=100"`
+	Unit              *string  `json:"unit,omitempty" validate:"omitempty,max=20"`
+	LowStockThreshold *float64 `json:"low_stock_threshold,omitempty" validate:"omitempty,min=0"`
+	// ClearThreshold removes the low-stock threshold
+	ClearThreshold bool `json:"clear_threshold,omitempty"`
+}
+
+// RecipeLineRequest is one ingredient of a menu item's recipe
+type RecipeLineRequest struct {
+	IngredientID uint    `json:"ingredient_id" validate:"required"`
+	Quantity     float64 `json:"quantity" validate:"gt=0"`
+}
+
+// RecipeUpdateRequest replaces a menu item's recipe
+type RecipeUpdateRequest struct {
+	Lines []RecipeLineRequest `json:"lines"`
+}
+
+// MenuItemStockRequest turns a menu item's countdown on or off. A nil StockQuantity
+// stops counting the item.
+type MenuItemStockRequest struct {
+	StockQuantity     *int `json:"stock_quantity" validate:"omitempty,min=0"`
+	LowStockThreshold *int `json:"low_stock_threshold" validate:"omitempty,min=0"`
+}
+
+// StockAdjustmentRequest changes the stock of an ingredient or a counted menu item,
+// either by Delta or to an absolute SetTo (for stocktakes)
+type StockAdjustmentRequest struct {
+	IngredientID *uint    `json:"ingredient_id,omitempty"`
+	MenuItemID   *uint    `json:"menu_item_id,omitempty"`
+	Delta        *float64 `json:"delta,omitempty"`
+	SetTo        *float64 `json:"set_to,omitempty" validate:"omitempty,min=0"`
+	Reason       string   `json:"reason" validate:"required"`
+	Note         string   `json:"note" validate:"omitempty,max=500"`
+}
+
+// LowStockResponse lists the ingredients and counted menu items at or below their thresholds
+type LowStockResponse struct {
+	Ingredients []Ingredient `json:"ingredients"`
+	MenuItems   []MenuItem   `json:"menu_items"`
+}
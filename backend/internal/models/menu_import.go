@@ -0,0 +1,53 @@
+package models
+
+// Columns of menu exports and imports. Imports need name, category and price_tiers;
+// the other columns may be left out.
+const (
+	MenuColumnID           = "id"
+	MenuColumnName         = "name"
+	MenuColumnCategory     = "category"
+	MenuColumnPriceTiers   = "price_tiers"
+	MenuColumnImageURL     = "image_url"
+	MenuColumnOrderIndex   = "order_index"
+	MenuColumnIsAvailable  = "is_available"
+	MenuColumnAvailability = "availability"
+)
+
+// MenuColumns are the columns of a menu export in order
+var MenuColumns = []string{
+	MenuColumnID,
+	MenuColumnName,
+	MenuColumnCategory,
+	MenuColumnPriceTiers,
+	MenuColumnImageURL,
+	MenuColumnOrderIndex,
+	MenuColumnIsAvailable,
+	MenuColumnAvailability,
+}
+
+// MenuImportChange is a field an imported row changes, formatted as in the file
+type MenuImportChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// MenuImportRow is one row of an imported file. Line is the row's line in the file,
+// counting the header as line 1.
+type MenuImportRow struct {
+	Line       int                `json:"line"`
+	MenuItemID *uint              `json:"menu_item_id,omitempty"`
+	Name       string             `json:"name"`
+	Changes    []MenuImportChange `json:"changes,omitempty"`
+	Errors     []string           `json:"errors,omitempty"`
+}
+
+// MenuImportReport lists the rows an import creates, updates and rejects. Menu items
+// that are not in the file are left as they are.
+type MenuImportReport struct {
+	DryRun    bool            `json:"dry_run"`
+	Create    []MenuImportRow `json:"create"`
+	Update    []MenuImportRow `json:"update"`
+	Reject    []MenuImportRow `json:"reject"`
+	Unchanged int             `json:"unchanged"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MenuOptionGroup represents a group of modifiers on a menu item (size, milk, syrups...)
+type MenuOptionGroup struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	MenuItemID uint           `json:"menu_item_id" gorm:"not null;index"`
+	Name       string         `json:"name" gorm:"not null"`
+	MinSelect  int            `json:"min_select" gorm:"default:0"`
+	MaxSelect  int            `json:"max_select" gorm:"default:1"`
+	OrderIndex int            `json:"order_index" gorm:"default:0"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+
+	// Relations
+	Options []MenuOption `json:"options" gorm:"foreignKey:OptionGroupID"`
+}
+
+// MenuOption represents a single selectable option with its price delta
+type MenuOption struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	OptionGroupID uint           `json:"option_group_id" gorm:"not null;index"`
+	Name          string         `json:"name" gorm:"not null"`
+	PriceDelta    int            `json:"price_delta" gorm:"default:0"`
+	IsDefault     bool           `json:"is_default" gorm:"default:false"`
+	IsAvailable   bool           `json:"is_available" gorm:"default:true"`
+	OrderIndex    int            `json:"order_index" gorm:"default:0"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// MenuOptionGroupCreateRequest represents the request to create an option group
+type MenuOptionGroupCreateRequest struct {
+	Name       string                    `json:"name" validate:"required,min=1,max=100"`
+	MinSelect  int                       `json:"min_select" validate:"min=0"`
+	MaxSelect  int                       `json:"max_select" validate:"min=1"`
+	OrderIndex int                       `json:"order_index" validate:"min=0"`
+	Options    []MenuOptionCreateRequest `json:"options,omitempty" validate:"dive"`
+}
+
+// MenuOptionGroupUpdateRequest represents the request to update an option group
+type MenuOptionGroupUpdateRequest struct {
+	Name       *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	MinSelect  *int    `json:"min_select,omitempty" validate:"omitempty,min=0"`
+	MaxSelect  *int    `json:"max_select,omitempty" validate:"omitempty,min=1"`
+	OrderIndex *int    `json:"order_index,omitempty" validate:"omitempty,min=0"`
+}
+
+// MenuOptionCreateRequest represents the request to create an option
+type MenuOptionCreateRequest struct {
+	Name        string `json:"name" validate:"required,min=1,max=100"`
+	PriceDelta  int    `json:"price_delta"`
+	IsDefault   bool   `json:"is_default"`
+	IsAvailable *bool  `json:"is_available,omitempty"`
+	OrderIndex  int    `json:"order_index" validate:"min=0"`
+}
+
+// MenuOptionUpdateRequest represents the request to update an option
+type MenuOptionUpdateRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	PriceDelta  *int    `json:"price_delta,omitempty"`
+	IsDefault   *bool   `json:"is_default,omitempty"`
+	IsAvailable *bool   `json:"is_available,omitempty"`
+	OrderIndex  *int    `json:"order_index,omitempty" validate:"omitempty,min=0"`
+}
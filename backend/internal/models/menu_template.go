@@ -0,0 +1,127 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Ways of applying a menu template to a shop
+const (
+	// MenuTemplateMerge adds the template's items and updates the shop's items of the
+	// same name; other items and category settings are left alone
+	MenuTemplateMerge = "merge"
+	// MenuTemplateReplace makes the shop's menu the template's: items missing from it
+	// are deleted and category settings are taken over
+	MenuTemplateReplace = "replace"
+)
+
+// MenuTemplate is a shop's menu saved by the main admin to set up other shops with.
+// Content is left out of template lists.
+type MenuTemplate struct {
+	ID           uint                 `json:"id" gorm:"primaryKey"`
+	Name         string               `json:"name" gorm:"not null;uniqueIndex"`
+	Description  string               `json:"description"`
+	SourceShopID *uint                `json:"source_shop_id"`
+	ItemCount    int                  `json:"item_count" gorm:"not null"`
+	Content      *MenuTemplateContent `json:"content,omitempty" gorm:"type:jsonb;not null"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+}
+
+// MenuTemplateContent is a menu independent of the shop it was taken from. Items
+// refer to categories by name, so the template applies to shops of any tenant.
+type MenuTemplateContent struct {
+	Categories []MenuTemplateCategory `json:"categories"`
+	Items      []MenuTemplateItem     `json:"items"`
+}
+
+// MenuTemplateCategory is a category as the source shop showed it. Applied to a
+// shop, it is the template category of the same name, or a shop category that is
+// created when missing.
+type MenuTemplateCategory struct {
+	Name         string                      `json:"name"`
+	DisplayName  string                      `json:"display_name"`
+	Emoji        string                      `json:"emoji"`
+	Color        string                      `json:"color"`
+	OrderIndex   int                         `json:"order_index"`
+	IsActive     bool                        `json:"is_active"`
+	Translations Translations                `json:"translations,omitempty"`
+	Availability []AvailabilityWindowRequest `json:"availability,omitempty"`
+}
+
+// MenuTemplateItem is a menu item of a template with its prices, options and
+// availability windows
+type MenuTemplateItem struct {
+	Name         string                         `json:"name"`
+	Description  string                         `json:"description,omitempty"`
+	Translations Translations                   `json:"translations,omitempty"`
+	Category     string                         `json:"category"`
+	ImageURL     string                         `json:"image_url"`
+	OrderIndex   int                            `json:"order_index"`
+	IsAvailable  bool                           `json:"is_available"`
+	PriceTiers   []MenuContentPriceTier         `json:"price_tiers"`
+	OptionGroups []MenuOptionGroupCreateRequest `json:"option_groups,omitempty"`
+	Availability []AvailabilityWindowRequest    `json:"availability,omitempty"`
+	DietaryInfo
+}
+
+// Value stores template content as JSON
+func (c MenuTemplateContent) Value() (driver.Value, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan reads template content stored as JSON
+func (c *MenuTemplateContent) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	case nil:
+		*c = MenuTemplateContent{}
+		return nil
+	}
+	return errors.New("unsupported menu template content value")
+}
+
+// MenuTemplateCreateRequest represents the request to save a shop's menu as a template
+type MenuTemplateCreateRequest struct {
+	Name        string `json:"name" validate:"required,min=2,max=100"`
+	Description string `json:"description" validate:"omitempty,max=500"`
+	ShopID      uint   `json:"shop_id" validate:"required"`
+}
+
+// MenuTemplateUpdateRequest represents the request to rename a template
+type MenuTemplateUpdateRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=500"`
+}
+
+// MenuTemplateApplyRequest represents the request to apply a template to a shop. Mode
+// defaults to merge.
+type MenuTemplateApplyRequest struct {
+	ShopID uint   `json:"shop_id" validate:"required"`
+	Mode   string `json:"mode" validate:"omitempty,oneof=merge replace"`
+}
+
+// MenuCopyRequest represents the request to copy menu items from another shop, merged
+// like a template
+type MenuCopyRequest struct {
+	SourceShopID uint   `json:"source_shop_id" validate:"required"`
+	MenuItemIDs  []uint `json:"menu_item_ids" validate:"required,min=1"`
+}
+
+// MenuTemplateApplyReport counts what applying a template or copying items changed
+type MenuTemplateApplyReport struct {
+	Mode              string `json:"mode"`
+	CategoriesCreated int    `json:"categories_created"`
+	Created           int    `json:"created"`
+	Updated           int    `json:"updated"`
+	Deleted           int    `json:"deleted"`
+}
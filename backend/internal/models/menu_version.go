@@ -0,0 +1,115 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// MenuContent is the publishable part of a shop's menu: its items with their names,
+// categories, prices, images and order, and its category settings. Availability
+// switches and schedules, options and stock are not part of it; they always take
+// effect immediately.
+type MenuContent struct {
+	Items      []MenuContentItem     `json:"items"`
+	Categories []MenuContentCategory `json:"categories"`
+}
+
+// MenuContentItem is the content of a menu item. In drafts, Key identifies the item:
+// its ID for existing items and "new-<n>" for items the draft creates, which have no
+// ID yet. Deleted marks existing items the draft deletes.
+type MenuContentItem struct {
+	ID          uint                   `json:"id,omitempty"`
+	Key         string                 `json:"key,omitempty"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	CategoryID  uint                   `json:"category_id"`
+	ImageURL    string                 `json:"image_url"`
+	OrderIndex  int                    `json:"order_index"`
+	PriceTiers  []MenuContentPriceTier `json:"price_tiers"`
+	DietaryInfo
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// MenuContentPriceTier is a price tier of a MenuContentItem, in display order
+type MenuContentPriceTier struct {
+	Label string `json:"label"`
+	Price int    `json:"price"`
+}
+
+// MenuContentCategory is a category as the shop shows it. Template categories are
+// published as overrides of the template.
+type MenuContentCategory struct {
+	ID          uint   `json:"id"`
+	DisplayName string `json:"display_name"`
+	Emoji       string `json:"emoji"`
+	Color       string `json:"color"`
+	OrderIndex  int    `json:"order_index"`
+	IsActive    bool   `json:"is_active"`
+}
+
+// Value stores menu content as JSON
+func (c MenuContent) Value() (driver.Value, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan reads menu content stored as JSON
+func (c *MenuContent) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	case nil:
+		*c = MenuContent{}
+		return nil
+	}
+	return errors.New("unsupported menu content value")
+}
+
+// MenuDraft holds a shop's staged menu changes until they are published. A shop has
+// at most one draft; Changes only lists the items and categories the draft changes.
+type MenuDraft struct {
+	ID           uint        `json:"id" gorm:"primaryKey"`
+	CoffeeShopID uint        `json:"coffee_shop_id" gorm:"not null;uniqueIndex"`
+	Changes      MenuContent `json:"changes" gorm:"type:jsonb;not null"`
+	Actor        `gorm:"embedded"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// MenuVersion is the full menu content as published. RestoredFromID is set on
+// versions created by rolling back to an earlier version. Content is left out of
+// version lists.
+type MenuVersion struct {
+	ID             uint         `json:"id" gorm:"primaryKey"`
+	CoffeeShopID   uint         `json:"coffee_shop_id" gorm:"not null;uniqueIndex:idx_menu_versions_shop_number"`
+	Number         int          `json:"number" gorm:"not null;uniqueIndex:idx_menu_versions_shop_number"`
+	Content        *MenuContent `json:"content,omitempty" gorm:"type:jsonb;not null"`
+	Note           string       `json:"note"`
+	RestoredFromID *uint        `json:"restored_from_id,omitempty"`
+	Actor          `gorm:"embedded"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// MenuPublishRequest represents the request to publish the draft or roll back to a version
+type MenuPublishRequest struct {
+	Note string `json:"note" validate:"omitempty,max=200"`
+}
+
+// MenuPreviewLinkResponse is a signed link to the public view of a draft
+type MenuPreviewLinkResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// MenuPreviewResponse is the public menu as it would look with a draft published
+type MenuPreviewResponse struct {
+	Categories []Category `json:"categories"`
+	MenuItems  []MenuItem `json:"menu_items"`
+}
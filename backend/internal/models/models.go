@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"coffee-shop-platform/internal/validation"
+
 	"gorm.io/gorm"
 )
 
@@ -20,10 +22,12 @@ type Tenant struct {
 	CoffeeShops []CoffeeShop `json:"coffee_shops,omitempty" gorm:"foreignKey:TenantID"`
 }
 
-// CoffeeShop represents a coffee shop under a tenant
+// CoffeeShop represents a coffee shop under a tenant. A tenant's shops are its
+// branches; Slug identifies a branch in public URLs and is unique within the tenant.
 type CoffeeShop struct {
 	ID           uint           `json:"id" gorm:"primaryKey"`
 	TenantID     uint           `json:"tenant_id" gorm:"not null"`
+	Slug         string         `json:"slug" gorm:"size:60"`
 	Name         string         `json:"name" gorm:"not null"`
 	Location     string         `json:"location"`
 	Phone        string         `json:"phone"`
@@ -31,6 +35,11 @@ type CoffeeShop struct {
 	LogoURL      string         `json:"logo_url"`
 	HeroImageURL string         `json:"hero_image_url"`
 	Description  string         `json:"description"`
+	TimeZone     string         `json:"time_zone" gorm:"not null;default:Asia/Tehran"`
+	// DefaultLanguage is the language the shop's menu is written in and shown in
+	// when a customer's languages have no translation
+	DefaultLanguage string       `json:"default_language" gorm:"size:5;not null;default:fa"`
+	Translations    Translations `json:"translations,omitempty" gorm:"type:jsonb"`
 	IsActive     bool           `json:"is_active" gorm:"default:true"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
@@ -40,12 +49,17 @@ type CoffeeShop struct {
 	Tenant     Tenant      `json:"tenant,omitempty" gorm:"foreignKey:TenantID"`
 	Admins     []ShopAdmin `json:"admins,omitempty" gorm:"foreignKey:CoffeeShopID"`
 	MenuItems  []MenuItem  `json:"menu_items,omitempty" gorm:"foreignKey:CoffeeShopID"`
+
+	// LogoImages and HeroImages hold the resized variants of uploaded logo and hero images
+	LogoImages *ImageSet `json:"logo_images,omitempty" gorm:"-"`
+	HeroImages *ImageSet `json:"hero_images,omitempty" gorm:"-"`
 }
 
-// ShopAdmin represents admin users for coffee shops
+// ShopAdmin represents admin users for coffee shops. Usernames are unique across all shops.
 type ShopAdmin struct {
 	ID           uint           `json:"id" gorm:"primaryKey"`
 	CoffeeShopID uint           `json:"coffee_shop_id" gorm:"not null"`
+	RoleID       *uint          `json:"role_id"`
 	Username     string         `json:"username" gorm:"not null"`
 	PasswordHash string         `json:"-" gorm:"not null"`
 	IsActive     bool           `json:"is_active" gorm:"default:true"`
@@ -55,6 +69,7 @@ type ShopAdmin struct {
 
 	// Relations
 	CoffeeShop CoffeeShop `json:"coffee_shop,omitempty" gorm:"foreignKey:CoffeeShopID"`
+	Role       *Role      `json:"role,omitempty" gorm:"foreignKey:RoleID"`
 }
 
 // MenuItem represents menu items for coffee shops
@@ -66,16 +81,33 @@ type MenuItem struct {
 	Price          int           `json:"price" gorm:"not null"`
 	PricePremium    *int          `json:"price_premium"`
 	HasDualPricing bool           `json:"has_dual_pricing" gorm:"default:false"`
+	Description    string         `json:"description"`
 	ImageURL       string         `json:"image_url"`
 	OrderIndex     int            `json:"order_index" gorm:"default:0"`
 	IsAvailable    bool           `json:"is_available" gorm:"default:true"`
+	Translations   Translations   `json:"translations,omitempty" gorm:"type:jsonb"`
+	DietaryInfo
+	StockQuantity  *int           `json:"stock_quantity"`
+	LowStockThreshold *int        `json:"low_stock_threshold"`
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 
 	// Relations
-	CoffeeShop CoffeeShop `json:"coffee_shop,omitempty" gorm:"foreignKey:CoffeeShopID"`
-	Category   Category   `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+	CoffeeShop   CoffeeShop        `json:"coffee_shop,omitempty" gorm:"foreignKey:CoffeeShopID"`
+	Category     Category             `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+	PriceTiers   []MenuItemPriceTier  `json:"price_tiers" gorm:"foreignKey:MenuItemID"`
+	OptionGroups []MenuOptionGroup    `json:"option_groups,omitempty" gorm:"foreignKey:MenuItemID"`
+	Availability []AvailabilityWindow `json:"availability" gorm:"foreignKey:MenuItemID"`
+	Recipe       []RecipeLine         `json:"recipe,omitempty" gorm:"foreignKey:MenuItemID"`
+
+	// AvailableNow is IsAvailable combined with the item's and its category's
+	// availability windows and stock, set when listing the menu
+	AvailableNow *bool `json:"available_now,omitempty" gorm:"-"`
+	// InStock is false when the item's own count or a recipe ingredient ran out
+	InStock *bool `json:"in_stock,omitempty" gorm:"-"`
+	// Images holds the resized variants of an uploaded ImageURL
+	Images *ImageSet `json:"images,omitempty" gorm:"-"`
 }
 
 // MainAdmin represents the platform's main admin
@@ -93,7 +125,7 @@ type MainAdmin struct {
 
 // TenantCreateRequest represents the request to create a tenant
 type TenantCreateRequest struct {
-	Subdomain string `json:"subdomain" validate:"required,min=3,max=50"`
+	Subdomain string `json:"subdomain" validate:"required,min=3,max=50,subdomain"`
 	Name       string `json:"name" validate:"required,min=2,max=100"`
 }
 
@@ -103,20 +135,27 @@ type TenantUpdateRequest struct {
 	IsActive *bool   `json:"is_active,omitempty"`
 }
 
-// CoffeeShopCreateRequest represents the request to create a coffee shop
+// CoffeeShopCreateRequest represents the request to create a coffee shop. Slug
+// defaults to one derived from Name.
 type CoffeeShopCreateRequest struct {
 	Name         string  `json:"name" validate:"required,min=2,max=100"`
+	Slug         string  `json:"slug" validate:"omitempty,max=60"`
 	Location     string  `json:"location" validate:"omitempty,max=200"`
 	Phone        string  `json:"phone" validate:"omitempty,max=20"`
 	InstagramURL string  `json:"instagram_url" validate:"omitempty,url"`
 	LogoURL      string  `json:"logo_url" validate:"omitempty,url"`
 	HeroImageURL string  `json:"hero_image_url" validate:"omitempty,url"`
 	Description  string  `json:"description" validate:"omitempty,max=500"`
+	DefaultLanguage string `json:"default_language" validate:"omitempty,oneof=fa en ar"`
+
+	// MenuTemplateID fills the new shop's menu from a menu template
+	MenuTemplateID *uint `json:"menu_template_id,omitempty"`
 }
 
 // CoffeeShopUpdateRequest represents the request to update a coffee shop
 type CoffeeShopUpdateRequest struct {
 	Name         *string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Slug         *string `json:"slug,omitempty" validate:"omitempty,max=60"`
 	Location     *string `json:"location,omitempty" validate:"omitempty,max=200"`
 	Phone        *string `json:"phone,omitempty" validate:"omitempty,max=20"`
 	InstagramURL *string `json:"instagram_url,omitempty" validate:"omitempty,url"`
@@ -124,12 +163,17 @@ type CoffeeShopUpdateRequest struct {
 	HeroImageURL *string `json:"hero_image_url,omitempty" validate:"omitempty,url"`
 	Description  *string `json:"description,omitempty" validate:"omitempty,max=500"`
 	IsActive     *bool   `json:"is_active,omitempty"`
+	DefaultLanguage *string `json:"default_language,omitempty" validate:"omitempty,oneof=fa en ar"`
+	// Translations replaces the shop's translated name and description when provided
+	Translations Translations `json:"translations,omitempty"`
 }
 
 // ShopAdminCreateRequest represents the request to create a shop admin
 type ShopAdminCreateRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=50"`
 	Password string `json:"password" validate:"required,min=6,max=100"`
+	// RoleID defaults to the owner role
+	RoleID *uint `json:"role_id,omitempty"`
 }
 
 // ShopAdminUpdateRequest represents the request to update a shop admin
@@ -137,23 +181,32 @@ type ShopAdminUpdateRequest struct {
 	Username *string `json:"username,omitempty" validate:"omitempty,min=3,max=50"`
 	Password *string `json:"password,omitempty" validate:"omitempty,min=6,max=100"`
 	IsActive *bool   `json:"is_active,omitempty"`
+	RoleID   *uint   `json:"role_id,omitempty"`
 }
 
 // MenuItemCreateRequest represents the request to create a menu item
 type MenuItemCreateRequest struct {
 	Name           string `json:"name" validate:"required,min=2,max=100"`
+	Description    string `json:"description" validate:"omitempty,max=500"`
 	CategoryID     uint   `json:"category_id" validate:"required"`
-	Price          int    `json:"price" validate:"required,min=0"`
+	Price          *int   `json:"price" validate:"omitempty,min=0"`
 	PricePremium   *int   `json:"price_premium" validate:"omitempty,min=0"`
 	HasDualPricing bool   `json:"has_dual_pricing"`
 	ImageURL       string `json:"image_url" validate:"omitempty,url"`
 	OrderIndex     int    `json:"order_index" validate:"min=0"`
 	IsAvailable    bool   `json:"is_available"`
+	// PriceTiers replaces Price/PricePremium when provided
+	PriceTiers []MenuItemPriceTierRequest `json:"price_tiers,omitempty" validate:"omitempty,dive"`
+	// Translations of the name and description by language
+	Translations Translations `json:"translations,omitempty"`
+	// Ingredients, allergens, dietary tags and nutrition
+	DietaryInfo
 }
 
 // MenuItemUpdateRequest represents the request to update a menu item
 type MenuItemUpdateRequest struct {
 	Name           *string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Description    *string `json:"description,omitempty" validate:"omitempty,max=500"`
 	CategoryID     *uint   `json:"category_id,omitempty"`
 	Price          *int    `json:"price,omitempty" validate:"omitempty,min=0"`
 	PricePremium   *int    `json:"price_premium,omitempty" validate:"omitempty,min=0"`
@@ -161,6 +214,18 @@ type MenuItemUpdateRequest struct {
 	ImageURL       *string `json:"image_url,omitempty" validate:"omitempty,url"`
 	OrderIndex     *int    `json:"order_index,omitempty" validate:"omitempty,min=0"`
 	IsAvailable    *bool   `json:"is_available,omitempty"`
+	// PriceTiers replaces the full tier list when provided; tiers without an ID are created
+	PriceTiers []MenuItemPriceTierRequest `json:"price_tiers,omitempty" validate:"omitempty,dive"`
+	// Translations replaces all translations of the name and description when provided
+	Translations Translations `json:"translations,omitempty"`
+	// Ingredients, Allergens and DietaryTags replace the item's lists when provided;
+	// an empty list clears them. Providing Allergens declares them, AllergensDeclared
+	// false clears them and marks them unknown. Nutrition replaces all nutrition facts.
+	Ingredients       []string   `json:"ingredients,omitempty"`
+	Allergens         []string   `json:"allergens,omitempty"`
+	AllergensDeclared *bool      `json:"allergens_declared,omitempty"`
+	DietaryTags       []string   `json:"dietary_tags,omitempty"`
+	Nutrition         *Nutrition `json:"nutrition,omitempty"`
 }
 
 // LoginRequest represents the login request
@@ -169,16 +234,30 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
-// LoginResponse represents the login response
+// LoginResponse represents the login and token refresh response
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  any    `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	User         any    `json:"user"`
 }
 
-// ErrorResponse represents an error response
+// ErrorResponse represents an error response. Fields lists the request fields that
+// failed validation.
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
+	Error   string                  `json:"error"`
+	Message string                  `json:"message,omitempty"`
+	Fields  []validation.FieldError `json:"fields,omitempty"`
+}
+
+// ListResponse is a page of a list endpoint. Total counts the items matching the
+// request's filters; NextCursor fetches the next page and is left out on the last.
+type ListResponse struct {
+	Data       any    `json:"data"`
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // SuccessResponse represents a success response
@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// DefaultTimeZone is the time zone of shops that haven't set one
+const DefaultTimeZone = "Asia/Tehran"
+
+// OpeningHours is one weekly opening interval of a shop, in the shop's time zone.
+// A day may have several intervals; a ClosesAt at or before OpensAt closes after midnight.
+type OpeningHours struct {
+	ID           uint         `json:"id" gorm:"primaryKey"`
+	CoffeeShopID uint         `json:"coffee_shop_id" gorm:"not null;index"`
+	Weekday      time.Weekday `json:"weekday" gorm:"not null"`
+	OpensAt      string       `json:"opens_at" gorm:"size:5;not null"`
+	ClosesAt     string       `json:"closes_at" gorm:"size:5;not null"`
+}
+
+// OpeningException replaces a shop's weekly hours on one date, for holidays and
+// special hours. A closed exception has no intervals.
+type OpeningException struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	CoffeeShopID uint      `json:"coffee_shop_id" gorm:"not null;uniqueIndex:idx_opening_exceptions_shop_date"`
+	Date         string    `json:"date" gorm:"size:10;not null;uniqueIndex:idx_opening_exceptions_shop_date"`
+	IsClosed     bool      `json:"is_closed"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// Relations
+	Intervals []OpeningExceptionInterval `json:"intervals" gorm:"foreignKey:OpeningExceptionID"`
+}
+
+// OpeningExceptionInterval is an opening interval on an exception date
+type OpeningExceptionInterval struct {
+	ID                 uint   `json:"-" gorm:"primaryKey"`
+	OpeningExceptionID uint   `json:"-" gorm:"not null;index"`
+	OpensAt            string `json:"opens_at" gorm:"size:5;not null"`
+	ClosesAt           string `json:"closes_at" gorm:"size:5;not null"`
+}
+
+// OpeningIntervalRequest is an HH:MM opening interval
+type OpeningIntervalRequest struct {
+	OpensAt  string `json:"opens_at" validate:"required"`
+	ClosesAt string `json:"closes_at" validate:"required"`
+}
+
+// WeeklyHoursRequest is an opening interval on a day of the week (0 = Sunday)
+type WeeklyHoursRequest struct {
+	Weekday time.Weekday `json:"weekday" validate:"min=0,max=6"`
+	OpeningIntervalRequest
+}
+
+// OpeningHoursUpdateRequest replaces a shop's weekly hours and optionally its time zone
+type OpeningHoursUpdateRequest struct {
+	TimeZone *string              `json:"time_zone,omitempty"`
+	Weekly   []WeeklyHoursRequest `json:"weekly" validate:"dive"`
+}
+
+// OpeningExceptionRequest sets the hours of one date
+type OpeningExceptionRequest struct {
+	IsClosed  bool                     `json:"is_closed"`
+	Note      string                   `json:"note" validate:"omitempty,max=200"`
+	Intervals []OpeningIntervalRequest `json:"intervals" validate:"dive"`
+}
+
+// OpeningHoursResponse is a shop's hours with its current status
+type OpeningHoursResponse struct {
+	TimeZone     string             `json:"time_zone"`
+	Weekly       []OpeningHours     `json:"weekly"`
+	Exceptions   []OpeningException `json:"exceptions"`
+	OpenNow      *bool              `json:"open_now"`
+	NextChangeAt *time.Time         `json:"next_change_at"`
+}
+
+// PublicShopResponse is a shop as shown to customers, with its opening status.
+// OpenNow is null for shops without opening hours.
+type PublicShopResponse struct {
+	CoffeeShop
+	OpeningHours []OpeningHours `json:"opening_hours"`
+	OpenNow      *bool          `json:"open_now"`
+	NextChangeAt *time.Time     `json:"next_change_at"`
+}
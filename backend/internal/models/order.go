@@ -0,0 +1,116 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OrderStatus is the lifecycle state of an order
+type OrderStatus string
+
+const (
+	OrderStatusReceived  OrderStatus = "received"
+	OrderStatusPreparing OrderStatus = "preparing"
+	OrderStatusReady     OrderStatus = "ready"
+	OrderStatusCompleted OrderStatus = "completed"
+	OrderStatusCancelled OrderStatus = "cancelled"
+)
+
+// orderTransitions lists the statuses each status may move to
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusReceived:  {OrderStatusPreparing, OrderStatusCancelled},
+	OrderStatusPreparing: {OrderStatusReady, OrderStatusCancelled},
+	OrderStatusReady:     {OrderStatusCompleted, OrderStatusCancelled},
+}
+
+// IsValid reports whether s is a known order status
+func (s OrderStatus) IsValid() bool {
+	switch s {
+	case OrderStatusReceived, OrderStatusPreparing, OrderStatusReady, OrderStatusCompleted, OrderStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo reports whether an order in status s may move to next
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Order represents a customer order placed from the public menu
+type Order struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	CoffeeShopID    uint           `json:"coffee_shop_id" gorm:"not null;index"`
+	Status          OrderStatus    `json:"status" gorm:"not null;default:received;index"`
+	CustomerName    string         `json:"customer_name"`
+	TableNumber     string         `json:"table_number"`
+	Note            string         `json:"note"`
+	Total           int            `json:"total" gorm:"not null"`
+	StatusChangedAt time.Time      `json:"status_changed_at"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+
+	// Relations
+	CoffeeShop CoffeeShop  `json:"coffee_shop,omitempty" gorm:"foreignKey:CoffeeShopID"`
+	Lines      []OrderLine `json:"lines" gorm:"foreignKey:OrderID"`
+}
+
+// OrderLine is one menu item in an order. Names and prices are snapshotted at order
+// time so later menu edits don't change placed orders.
+type OrderLine struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	OrderID     uint      `json:"order_id" gorm:"not null;index"`
+	MenuItemID  uint      `json:"menu_item_id" gorm:"not null"`
+	PriceTierID *uint     `json:"price_tier_id"`
+	Name        string    `json:"name" gorm:"not null"`
+	TierLabel   string    `json:"tier_label"`
+	UnitPrice   int       `json:"unit_price" gorm:"not null"`
+	Quantity    int       `json:"quantity" gorm:"not null"`
+	LineTotal   int       `json:"line_total" gorm:"not null"`
+	Note        string    `json:"note"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Relations
+	MenuItem MenuItem          `json:"-" gorm:"foreignKey:MenuItemID"`
+	Options  []OrderLineOption `json:"options" gorm:"foreignKey:OrderLineID"`
+}
+
+// OrderLineOption is a snapshot of a modifier chosen for an order line
+type OrderLineOption struct {
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	OrderLineID  uint   `json:"order_line_id" gorm:"not null;index"`
+	MenuOptionID uint   `json:"menu_option_id" gorm:"not null"`
+	GroupName    string `json:"group_name"`
+	Name         string `json:"name" gorm:"not null"`
+	PriceDelta   int    `json:"price_delta"`
+}
+
+// OrderCreateRequest represents a customer's order from the public menu
+type OrderCreateRequest struct {
+	CoffeeShopID *uint                    `json:"coffee_shop_id,omitempty"`
+	CustomerName string                   `json:"customer_name" validate:"omitempty,max=100"`
+	TableNumber  string                   `json:"table_number" validate:"omitempty,max=20"`
+	Note         string                   `json:"note" validate:"omitempty,max=500"`
+	Lines        []OrderLineCreateRequest `json:"lines" validate:"required,min=1,dive"`
+}
+
+// OrderLineCreateRequest represents one line of an order request
+type OrderLineCreateRequest struct {
+	MenuItemID  uint   `json:"menu_item_id" validate:"required"`
+	PriceTierID *uint  `json:"price_tier_id,omitempty"`
+	OptionIDs   []uint `json:"option_ids,omitempty"`
+	Quantity    int    `json:"quantity" validate:"required,min=1,max=99"`
+	Note        string `json:"note" validate:"omitempty,max=200"`
+}
+
+// OrderStatusUpdateRequest represents a status change requested by shop staff
+type OrderStatusUpdateRequest struct {
+	Status OrderStatus `json:"status" validate:"required"`
+}
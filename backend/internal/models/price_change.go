@@ -0,0 +1,119 @@
+package models
+
+import "time"
+
+// Sources of recorded price changes
+const (
+	PriceSourceCreated   = "created"
+	PriceSourceManual    = "manual"
+	PriceSourceScheduled = "scheduled"
+)
+
+// Statuses of scheduled price changes
+const (
+	ScheduledPricePending   = "pending"
+	ScheduledPriceApplied   = "applied"
+	ScheduledPriceCancelled = "cancelled"
+	// ScheduledPriceSkipped is set when the tier was deleted before the change was due
+	ScheduledPriceSkipped = "skipped"
+)
+
+// Bulk price change modes
+const (
+	BulkPricePercent = "percent"
+	BulkPriceFixed   = "fixed"
+)
+
+// Actor identifies the user who made or scheduled a change
+type Actor struct {
+	ChangedByID   *uint  `json:"changed_by_id"`
+	ChangedByType string `json:"changed_by_type"`
+	ChangedByName string `json:"changed_by_name"`
+}
+
+// PriceChange is one entry in the price history of a menu item's tier
+type PriceChange struct {
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	CoffeeShopID uint   `json:"coffee_shop_id" gorm:"not null;index"`
+	MenuItemID   uint   `json:"menu_item_id" gorm:"not null;index"`
+	PriceTierID  uint   `json:"price_tier_id" gorm:"not null;index"`
+	TierLabel    string `json:"tier_label"`
+	// OldPrice is nil for the first price of a tier
+	OldPrice *int   `json:"old_price"`
+	NewPrice int    `json:"new_price" gorm:"not null"`
+	Source   string `json:"source" gorm:"not null"`
+	Actor    `gorm:"embedded"`
+	// ScheduledPriceChangeID links changes applied by the scheduler
+	ScheduledPriceChangeID *uint     `json:"scheduled_price_change_id,omitempty"`
+	CreatedAt              time.Time `json:"created_at" gorm:"index"`
+}
+
+// ScheduledPriceChange sets a tier's price at EffectiveAt
+type ScheduledPriceChange struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	CoffeeShopID uint       `json:"coffee_shop_id" gorm:"not null;index"`
+	MenuItemID   uint       `json:"menu_item_id" gorm:"not null;index"`
+	PriceTierID  uint       `json:"price_tier_id" gorm:"not null"`
+	BatchID      *uint      `json:"batch_id,omitempty" gorm:"index"`
+	NewPrice     int        `json:"new_price" gorm:"not null"`
+	EffectiveAt  time.Time  `json:"effective_at" gorm:"not null;index"`
+	Status       string     `json:"status" gorm:"not null;default:pending;index"`
+	AppliedAt    *time.Time `json:"applied_at"`
+	Actor        `gorm:"embedded"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// Relations
+	MenuItem  MenuItem          `json:"menu_item,omitempty" gorm:"foreignKey:MenuItemID"`
+	PriceTier MenuItemPriceTier `json:"price_tier,omitempty" gorm:"foreignKey:PriceTierID"`
+}
+
+// PriceChangeBatch groups the scheduled changes of one bulk price change
+type PriceChangeBatch struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	CoffeeShopID uint      `json:"coffee_shop_id" gorm:"not null;index"`
+	CategoryID   *uint     `json:"category_id"`
+	Mode         string    `json:"mode" gorm:"not null"`
+	Value        float64   `json:"value" gorm:"not null"`
+	RoundTo      int       `json:"round_to"`
+	EffectiveAt  time.Time `json:"effective_at" gorm:"not null"`
+	Actor        `gorm:"embedded"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// Relations
+	Changes []ScheduledPriceChange `json:"changes,omitempty" gorm:"foreignKey:BatchID"`
+}
+
+// ScheduledPriceChangeRequest schedules a new price for one tier of a menu item
+type ScheduledPriceChangeRequest struct {
+	PriceTierID uint      `json:"price_tier_id" validate:"required"`
+	Price       int       `json:"price" validate:"min=0"`
+	EffectiveAt time.Time `json:"effective_at" validate:"required"`
+}
+
+// BulkPriceChangeRequest raises (or lowers) every price of a category, or of the whole
+// shop when CategoryID is nil, by a percentage or a fixed amount. New prices are
+// rounded to the nearest RoundTo when set.
+type BulkPriceChangeRequest struct {
+	CategoryID  *uint     `json:"category_id,omitempty"`
+	Mode        string    `json:"mode" validate:"required,oneof=percent fixed"`
+	Value       float64   `json:"value" validate:"required"`
+	RoundTo     int       `json:"round_to" validate:"min=0"`
+	EffectiveAt time.Time `json:"effective_at" validate:"required"`
+}
+
+// BulkPriceChangeLine is one tier's price before and after a bulk change
+type BulkPriceChangeLine struct {
+	MenuItemID  uint   `json:"menu_item_id"`
+	Name        string `json:"name"`
+	PriceTierID uint   `json:"price_tier_id"`
+	TierLabel   string `json:"tier_label"`
+	OldPrice    int    `json:"old_price"`
+	NewPrice    int    `json:"new_price"`
+}
+
+// BulkPriceChangePreview lists the prices a bulk change would set
+type BulkPriceChangePreview struct {
+	EffectiveAt time.Time             `json:"effective_at"`
+	Lines       []BulkPriceChangeLine `json:"lines"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Default labels used when legacy Price/PricePremium pairs are turned into tiers
+const (
+	DefaultPriceTierLabel = "Standard"
+	PremiumPriceTierLabel = "Premium"
+)
+
+// MenuItemPriceTier represents a named price of a menu item (bean blend, cup size, dine-in/takeaway...)
+type MenuItemPriceTier struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	MenuItemID uint           `json:"menu_item_id" gorm:"not null;index"`
+	Label      string         `json:"label" gorm:"not null"`
+	Price      int            `json:"price" gorm:"not null"`
+	OrderIndex int            `json:"order_index" gorm:"default:0"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// MenuItemPriceTierRequest represents a price tier in menu item create/update requests.
+// ID is only set on update to keep an existing tier.
+type MenuItemPriceTierRequest struct {
+	ID         uint   `json:"id,omitempty"`
+	Label      string `json:"label" validate:"required,min=1,max=100"`
+	Price      int    `json:"price" validate:"min=0"`
+	OrderIndex int    `json:"order_index" validate:"min=0"`
+}
+
+// LegacyPriceTiers builds tiers from the Price/PricePremium pair
+func (m *MenuItem) LegacyPriceTiers(defaultLabel, premiumLabel string) []MenuItemPriceTier {
+	tiers := []MenuItemPriceTier{{
+		MenuItemID: m.ID,
+		Label:      defaultLabel,
+		Price:      m.Price,
+		OrderIndex: 0,
+	}}
+	if m.HasDualPricing && m.PricePremium != nil {
+		tiers = append(tiers, MenuItemPriceTier{
+			MenuItemID: m.ID,
+			Label:      premiumLabel,
+			Price:      *m.PricePremium,
+			OrderIndex: 1,
+		})
+	}
+	return tiers
+}
+
+// SyncLegacyPrices keeps Price/PricePremium/HasDualPricing in line with the first two
+// tiers so clients reading the old fields keep working. Tiers must be in display order.
+func (m *MenuItem) SyncLegacyPrices() {
+	if len(m.PriceTiers) == 0 {
+		return
+	}
+
+	m.Price = m.PriceTiers[0].Price
+	if len(m.PriceTiers) > 1 {
+		premium := m.PriceTiers[1].Price
+		m.PricePremium = &premium
+		m.HasDualPricing = true
+	} else {
+		m.PricePremium = nil
+		m.HasDualPricing = false
+	}
+}
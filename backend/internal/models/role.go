@@ -0,0 +1,139 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Permissions that can be granted to shop staff roles
+const (
+	PermMenuView         = "menu.view"
+	PermMenuAvailability = "menu.availability"
+	PermMenuEdit         = "menu.edit"
+	PermMenuPrices       = "menu.prices"
+	PermMenuPublish      = "menu.publish"
+	PermCategoriesManage = "categories.manage"
+	PermOrdersView       = "orders.view"
+	PermOrdersManage     = "orders.manage"
+	PermShopSettings     = "shop.settings"
+	PermUsersManage      = "users.manage"
+	PermInventoryManage  = "inventory.manage"
+)
+
+// AllPermissions lists every known permission
+var AllPermissions = []string{
+	PermMenuView,
+	PermMenuAvailability,
+	PermMenuEdit,
+	PermMenuPrices,
+	PermMenuPublish,
+	PermCategoriesManage,
+	PermOrdersView,
+	PermOrdersManage,
+	PermShopSettings,
+	PermUsersManage,
+	PermInventoryManage,
+}
+
+// IsKnownPermission reports whether p is one of AllPermissions
+func IsKnownPermission(p string) bool {
+	for _, known := range AllPermissions {
+		if known == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Built-in role names
+const (
+	RoleOwner   = "owner"
+	RoleManager = "manager"
+	RoleBarista = "barista"
+	RoleViewer  = "viewer"
+)
+
+// SystemRolePermissions are the permissions of the built-in roles
+var SystemRolePermissions = map[string][]string{
+	RoleOwner: AllPermissions,
+	RoleManager: {
+		PermMenuView, PermMenuAvailability, PermMenuEdit, PermMenuPrices, PermMenuPublish,
+		PermCategoriesManage, PermOrdersView, PermOrdersManage, PermInventoryManage,
+	},
+	RoleBarista: {
+		PermMenuView, PermMenuAvailability, PermOrdersView, PermOrdersManage,
+	},
+	RoleViewer: {
+		PermMenuView, PermOrdersView,
+	},
+}
+
+// Role is a named set of permissions for shop staff. Roles without a CoffeeShopID
+// are built-in and available to every shop.
+type Role struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	CoffeeShopID *uint          `json:"coffee_shop_id" gorm:"index"`
+	Name         string         `json:"name" gorm:"not null"`
+	DisplayName  string         `json:"display_name"`
+	IsSystem     bool           `json:"is_system" gorm:"default:false"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+
+	// Relations
+	Permissions []RolePermission `json:"permissions" gorm:"foreignKey:RoleID"`
+}
+
+// RolePermission grants one permission to a role
+type RolePermission struct {
+	ID         uint   `json:"-" gorm:"primaryKey"`
+	RoleID     uint   `json:"-" gorm:"not null;uniqueIndex:idx_role_permissions_role_permission"`
+	Permission string `json:"permission" gorm:"not null;uniqueIndex:idx_role_permissions_role_permission"`
+}
+
+// PermissionNames returns the role's permissions as strings
+func (r *Role) PermissionNames() []string {
+	names := make([]string, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		names = append(names, p.Permission)
+	}
+	return names
+}
+
+// RoleName returns the name of the admin's role. Admins without a role predate
+// roles and are owners.
+func (a *ShopAdmin) RoleName() string {
+	if a.Role == nil || a.Role.ID == 0 {
+		return RoleOwner
+	}
+	return a.Role.Name
+}
+
+// EffectivePermissions returns the admin's permissions; Role.Permissions must be preloaded
+func (a *ShopAdmin) EffectivePermissions() []string {
+	if a.Role == nil || a.Role.ID == 0 {
+		return AllPermissions
+	}
+	return a.Role.PermissionNames()
+}
+
+// RoleCreateRequest represents the request to create a custom shop role
+type RoleCreateRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=50"`
+	DisplayName string   `json:"display_name" validate:"omitempty,max=100"`
+	Permissions []string `json:"permissions" validate:"required,min=1"`
+}
+
+// StaffCreateRequest represents the request from a shop owner to add a staff account
+type StaffCreateRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=50"`
+	Password string `json:"password" validate:"required,min=6,max=100"`
+	RoleID   uint   `json:"role_id" validate:"required"`
+}
+
+// StaffUpdateRequest represents the request to change a staff account's role or status
+type StaffUpdateRequest struct {
+	RoleID   *uint `json:"role_id,omitempty"`
+	IsActive *bool `json:"is_active,omitempty"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// TenantDomainVerifyPrefix is prepended to a custom domain to form the DNS name of
+// its verification TXT record
+const TenantDomainVerifyPrefix = "_menu-verify."
+
+// TenantDomain maps a custom domain, such as menu.mycafe.ir, to a tenant. Requests
+// are only routed by the domain once its DNS TXT record has been verified.
+type TenantDomain struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	TenantID          uint       `json:"tenant_id" gorm:"not null;index"`
+	Domain            string     `json:"domain" gorm:"uniqueIndex;not null"`
+	VerificationToken string     `json:"verification_token" gorm:"not null"`
+	VerifiedAt        *time.Time `json:"verified_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	// Relations
+	Tenant Tenant `json:"-" gorm:"foreignKey:TenantID"`
+}
+
+// VerificationRecord is the DNS name that must hold VerificationToken as a TXT record
+func (d *TenantDomain) VerificationRecord() string {
+	return TenantDomainVerifyPrefix + d.Domain
+}
+
+// TenantDomainCreateRequest represents the request to add a custom domain to a tenant
+type TenantDomainCreateRequest struct {
+	Domain string `json:"domain" validate:"required,fqdn"`
+}
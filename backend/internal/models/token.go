@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// TokenFamily groups the refresh tokens rotated from one login. Revoking the family
+// logs the session out everywhere, including its outstanding access tokens.
+type TokenFamily struct {
+	ID            string     `json:"id" gorm:"primaryKey;size:32"`
+	UserID        uint       `json:"user_id" gorm:"not null;index:idx_token_families_user"`
+	UserType      string     `json:"user_type" gorm:"not null;index:idx_token_families_user"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	RevokedReason string     `json:"revoked_reason"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// RefreshToken is a single-use refresh token. Only its SHA-256 hash is stored.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	FamilyID  string     `json:"family_id" gorm:"size:32;not null;index"`
+	TokenHash string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// Relations
+	Family TokenFamily `json:"-" gorm:"foreignKey:FamilyID"`
+}
+
+// Token revocation reasons
+const (
+	RevokedByLogout = "logout"
+	RevokedByReuse  = "refresh_token_reuse"
+	RevokedByAdmin  = "account_changed"
+)
+
+// RefreshRequest represents the request to rotate a refresh token
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// LogoutRequest represents the logout request. Without a refresh token the session
+// of the bearer access token is logged out.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Languages menu content can be shown in
+const (
+	LanguagePersian = "fa"
+	LanguageEnglish = "en"
+	LanguageArabic  = "ar"
+)
+
+// SupportedLanguages lists the languages menu content can be translated into
+var SupportedLanguages = []string{LanguagePersian, LanguageEnglish, LanguageArabic}
+
+// TemplateLanguage is the language template categories are written in. Shops write
+// their own content in their DefaultLanguage.
+const TemplateLanguage = LanguagePersian
+
+// Translatable fields
+const (
+	FieldName        = "name"
+	FieldDisplayName = "display_name"
+	FieldDescription = "description"
+)
+
+// Translations holds translated texts by language and field, e.g.
+// {"en": {"name": "Latte"}, "ar": {"name": "لاتيه"}}. The untranslated text stays in
+// the entity's own fields.
+type Translations map[string]map[string]string
+
+// IsSupportedLanguage reports whether menu content can be translated into language
+func IsSupportedLanguage(language string) bool {
+	for _, supported := range SupportedLanguages {
+		if supported == language {
+			return true
+		}
+	}
+	return false
+}
+
+// Text returns the translation of field into language, or ""
+func (t Translations) Text(language, field string) string {
+	return t[language][field]
+}
+
+// Normalize checks that t only translates fields into supported languages and returns
+// it without empty texts, or nil when nothing is left
+func (t Translations) Normalize(fields ...string) (Translations, error) {
+	var normalized Translations
+	for language, texts := range t {
+		if !IsSupportedLanguage(language) {
+			return nil, fmt.Errorf("unsupported language %q", language)
+		}
+		for field, text := range texts {
+			if !containsField(fields, field) {
+				return nil, fmt.Errorf("%q can't be translated", field)
+			}
+			text = strings.TrimSpace(text)
+			if text == "" {
+				continue
+			}
+			if normalized == nil {
+				normalized = make(Translations)
+			}
+			if normalized[language] == nil {
+				normalized[language] = make(map[string]string)
+			}
+			normalized[language][field] = text
+		}
+	}
+	return normalized, nil
+}
+
+// Without returns t without the translations of field
+func (t Translations) Without(field string) Translations {
+	var result Translations
+	for language, texts := range t {
+		for f, text := range texts {
+			if f == field {
+				continue
+			}
+			if result == nil {
+				result = make(Translations)
+			}
+			if result[language] == nil {
+				result[language] = make(map[string]string)
+			}
+			result[language][f] = text
+		}
+	}
+	return result
+}
+
+// Merge returns t with the texts of other added, other's winning where both have one
+func (t Translations) Merge(other Translations) Translations {
+	if len(other) == 0 {
+		return t
+	}
+	result := make(Translations, len(t)+len(other))
+	for _, source := range []Translations{t, other} {
+		for language, texts := range source {
+			if result[language] == nil {
+				result[language] = make(map[string]string)
+			}
+			for field, text := range texts {
+				result[language][field] = text
+			}
+		}
+	}
+	return result
+}
+
+// Equal reports whether t and other hold the same texts
+func (t Translations) Equal(other Translations) bool {
+	if len(t) != len(other) {
+		return false
+	}
+	for language, texts := range t {
+		if len(texts) != len(other[language]) {
+			return false
+		}
+		for field, text := range texts {
+			if other[language][field] != text {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Value stores translations as JSON, or NULL when there are none
+func (t Translations) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan reads translations stored as JSON
+func (t *Translations) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	case nil:
+		*t = nil
+		return nil
+	}
+	return errors.New("unsupported translations value")
+}
+
+// MissingTranslation is a text of the shop's menu that has no translation into
+// Language. Type is menu_item, category or shop.
+type MissingTranslation struct {
+	Type     string `json:"type"`
+	ID       uint   `json:"id"`
+	Field    string `json:"field"`
+	Language string `json:"language"`
+	Text     string `json:"text"`
+}
+
+// Types of MissingTranslation
+const (
+	TranslationTypeMenuItem = "menu_item"
+	TranslationTypeCategory = "category"
+	TranslationTypeShop     = "shop"
+)
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Upload is a file a shop admin uploaded to storage. Files no menu item or shop
+// refers to any more are deleted. Width and Height are 0 for images that could not
+// be decoded, which also have no variants.
+type Upload struct {
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	CoffeeShopID uint   `json:"coffee_shop_id" gorm:"not null;index"`
+	Key          string `json:"key" gorm:"not null;uniqueIndex"`
+	URL          string `json:"url" gorm:"not null;index"`
+	ContentType  string `json:"content_type" gorm:"not null"`
+	Size         int64  `json:"size" gorm:"not null"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Actor        `gorm:"embedded"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// Relations
+	Variants []ImageVariant `json:"variants" gorm:"foreignKey:UploadID"`
+}
+
+// ImageVariant is a resized copy of an uploaded image
+type ImageVariant struct {
+	ID          uint      `json:"-" gorm:"primaryKey"`
+	UploadID    uint      `json:"-" gorm:"not null;uniqueIndex:idx_image_variants_upload_name"`
+	Name        string    `json:"name" gorm:"not null;uniqueIndex:idx_image_variants_upload_name"`
+	Key         string    `json:"key" gorm:"not null"`
+	URL         string    `json:"url" gorm:"not null"`
+	ContentType string    `json:"content_type" gorm:"not null"`
+	Size        int64     `json:"size" gorm:"not null"`
+	Width       int       `json:"width" gorm:"not null"`
+	Height      int       `json:"height" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ImageSet is an image's variant URLs by name (thumbnail, card, hero) and a srcset
+// attribute value listing them by width
+type ImageSet struct {
+	Variants map[string]string `json:"variants"`
+	Srcset   string            `json:"srcset"`
+}
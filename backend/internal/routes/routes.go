@@ -3,6 +3,7 @@ package routes
 import (
 	"coffee-shop-platform/internal/handlers"
 	"coffee-shop-platform/internal/middleware"
+	"coffee-shop-platform/internal/models"
 
 	"github.com/labstack/echo/v4"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
@@ -15,6 +16,20 @@ func SetupRoutes(e *echo.Echo) {
 	coffeeShopHandler := handlers.NewCoffeeShopHandler()
 	menuHandler := handlers.NewMenuHandler()
 	categoryHandler := handlers.NewCategoryHandler()
+	menuOptionHandler := handlers.NewMenuOptionHandler()
+	orderHandler := handlers.NewOrderHandler()
+	staffHandler := handlers.NewStaffHandler()
+	openingHoursHandler := handlers.NewOpeningHoursHandler()
+	priceHandler := handlers.NewPriceHandler()
+	inventoryHandler := handlers.NewInventoryHandler()
+	uploadHandler := handlers.NewUploadHandler()
+	eventHandler := handlers.NewEventHandler()
+	menuDraftHandler := handlers.NewMenuDraftHandler()
+	menuTemplateHandler := handlers.NewMenuTemplateHandler()
+	translationHandler := handlers.NewTranslationHandler()
+
+	// Serve /t/:subdomain/... on hosts shared by all tenants
+	e.Pre(middleware.TenantPathPrefix())
 
 	// CORS middleware
 	e.Use(echomiddleware.CORS())
@@ -36,13 +51,29 @@ func SetupRoutes(e *echo.Echo) {
 	// Public routes (no authentication required)
 	public := e.Group("/api/public")
 	public.GET("/menu", menuHandler.GetPublicMenuItems, middleware.TenantResolver())
+	public.GET("/menu/search", menuHandler.SearchPublicMenu, middleware.TenantResolver())
 	public.GET("/shop", menuHandler.GetShopSettings, middleware.TenantResolver())
-	public.GET("/categories", categoryHandler.GetCategories)
+	public.GET("/categories", categoryHandler.GetCategories, middleware.TenantResolver())
+	public.POST("/orders", orderHandler.CreateOrder, middleware.TenantResolver())
+	public.GET("/events", eventHandler.StreamPublicEvents, middleware.TenantResolver())
+
+	// Signed preview links of menu drafts carry their shop in the token
+	public.GET("/menu/preview", menuDraftHandler.GetMenuPreview)
+
+	// Branches of the tenant. The routes above serve the tenant's default branch.
+	public.GET("/shops", coffeeShopHandler.GetPublicShops, middleware.TenantResolver())
+	public.GET("/shops/:slug", menuHandler.GetShopSettings, middleware.TenantResolver())
+	public.GET("/shops/:slug/menu", menuHandler.GetPublicMenuItems, middleware.TenantResolver())
+	public.GET("/shops/:slug/menu/search", menuHandler.SearchPublicMenu, middleware.TenantResolver())
+	public.GET("/shops/:slug/categories", categoryHandler.GetCategories, middleware.TenantResolver())
+	public.POST("/shops/:slug/orders", orderHandler.CreateOrder, middleware.TenantResolver())
 
 	// Authentication routes
 	auth := e.Group("/api/auth")
 	auth.POST("/main-admin/login", authHandler.MainAdminLogin)
 	auth.POST("/shop-admin/login", authHandler.ShopAdminLogin)
+	auth.POST("/refresh", authHandler.Refresh)
+	auth.POST("/logout", authHandler.Logout)
 
 	// Main admin routes (require main admin authentication)
 	mainAdmin := e.Group("/api/admin")
@@ -56,6 +87,12 @@ func SetupRoutes(e *echo.Echo) {
 	mainAdmin.PUT("/tenants/:id", tenantHandler.UpdateTenant)
 	mainAdmin.DELETE("/tenants/:id", tenantHandler.DeleteTenant)
 
+	// Custom domains
+	mainAdmin.GET("/tenants/:id/domains", tenantHandler.GetTenantDomains)
+	mainAdmin.POST("/tenants/:id/domains", tenantHandler.CreateTenantDomain)
+	mainAdmin.POST("/tenants/:id/domains/:domainId/verify", tenantHandler.VerifyTenantDomain)
+	mainAdmin.DELETE("/tenants/:id/domains/:domainId", tenantHandler.DeleteTenantDomain)
+
 	// Coffee shop management
 	mainAdmin.GET("/tenants/:tenantId/shops", coffeeShopHandler.GetCoffeeShops)
 	mainAdmin.POST("/tenants/:tenantId/shops", coffeeShopHandler.CreateCoffeeShop)
@@ -63,33 +100,163 @@ func SetupRoutes(e *echo.Echo) {
 	mainAdmin.PUT("/shops/:id", coffeeShopHandler.UpdateCoffeeShop)
 	mainAdmin.DELETE("/shops/:id", coffeeShopHandler.DeleteCoffeeShop)
 
+	// Menu templates: save a shop's menu and apply it to shops of any tenant
+	mainAdmin.GET("/menu-templates", menuTemplateHandler.GetMenuTemplates)
+	mainAdmin.POST("/menu-templates", menuTemplateHandler.CreateMenuTemplate)
+	mainAdmin.GET("/menu-templates/:id", menuTemplateHandler.GetMenuTemplate)
+	mainAdmin.PUT("/menu-templates/:id", menuTemplateHandler.UpdateMenuTemplate)
+	mainAdmin.DELETE("/menu-templates/:id", menuTemplateHandler.DeleteMenuTemplate)
+	mainAdmin.POST("/menu-templates/:id/apply", menuTemplateHandler.ApplyMenuTemplate)
+	mainAdmin.POST("/shops/:id/menu/copy", menuTemplateHandler.CopyMenuItems)
+
 	// Shop admin management
+	mainAdmin.GET("/shops/:shopId/admins", coffeeShopHandler.GetShopAdmins)
 	mainAdmin.POST("/shops/:shopId/admins", coffeeShopHandler.CreateShopAdmin)
+	mainAdmin.GET("/shops/:shopId/admins/:id", coffeeShopHandler.GetShopAdmin)
+	mainAdmin.PUT("/shops/:shopId/admins/:id", coffeeShopHandler.UpdateShopAdmin)
+	mainAdmin.POST("/shops/:shopId/admins/:id/deactivate", coffeeShopHandler.DeactivateShopAdmin)
+	mainAdmin.DELETE("/shops/:shopId/admins/:id", coffeeShopHandler.DeleteShopAdmin)
 
-	// Category management (main admin only)
-	mainAdmin.GET("/categories", categoryHandler.GetAllCategories)
+	// Template category management (main admin only)
 	mainAdmin.POST("/categories", categoryHandler.CreateCategory)
 	mainAdmin.GET("/categories/:id", categoryHandler.GetCategory)
 	mainAdmin.PUT("/categories/:id", categoryHandler.UpdateCategory)
 	mainAdmin.DELETE("/categories/:id", categoryHandler.DeleteCategory)
 
+	// Shop event stream. Registered outside the shop admin group so the token can be
+	// taken from the query string before authentication (EventSource can't send headers).
+	e.GET("/api/admin/events", eventHandler.StreamShopEvents,
+		middleware.TokenFromQuery(),
+		middleware.OptionalTenantResolver(),
+		middleware.AuthMiddleware(),
+		middleware.ShopAdminOnly(),
+		middleware.RequirePermission(models.PermMenuView),
+	)
+
 	// Shop admin routes (require shop admin authentication and tenant resolution)
 	shopAdmin := e.Group("/api/admin")
-	shopAdmin.Use(middleware.TenantResolver())
+	shopAdmin.Use(middleware.OptionalTenantResolver())
 	shopAdmin.Use(middleware.AuthMiddleware())
 	shopAdmin.Use(middleware.ShopAdminOnly())
 
-	// Menu management
-	shopAdmin.GET("/menu", menuHandler.GetMenuItems)
-	shopAdmin.POST("/menu", menuHandler.CreateMenuItem)
-	shopAdmin.GET("/menu/:id", menuHandler.GetMenuItem)
-	shopAdmin.PUT("/menu/:id", menuHandler.UpdateMenuItem)
-	shopAdmin.DELETE("/menu/:id", menuHandler.DeleteMenuItem)
+	// Shop admin routes check permissions of the staff member's role
+	menuView := middleware.RequirePermission(models.PermMenuView)
+	menuEdit := middleware.RequirePermission(models.PermMenuEdit)
+	menuPrices := middleware.RequirePermission(models.PermMenuPrices)
+	ordersView := middleware.RequirePermission(models.PermOrdersView)
+	ordersManage := middleware.RequirePermission(models.PermOrdersManage)
+	shopSettings := middleware.RequirePermission(models.PermShopSettings)
+	categoriesManage := middleware.RequirePermission(models.PermCategoriesManage)
+	usersManage := middleware.RequirePermission(models.PermUsersManage)
+	inventoryManage := middleware.RequirePermission(models.PermInventoryManage)
+	menuPublish := middleware.RequirePermission(models.PermMenuPublish)
+
+	// Menu management. Item updates are checked per field: prices need menu.prices,
+	// availability needs menu.availability and everything else menu.edit. Changes
+	// drafts could stage go live here, so they also need menu.publish.
+	shopAdmin.GET("/menu", menuHandler.GetMenuItems, menuView)
+	shopAdmin.POST("/menu", menuHandler.CreateMenuItem,
+		middleware.RequirePermission(models.PermMenuEdit, models.PermMenuPrices, models.PermMenuPublish))
+	shopAdmin.GET("/menu/search", menuHandler.SearchMenu, menuView)
+	shopAdmin.GET("/menu/export", menuHandler.ExportMenu, menuView)
+	shopAdmin.POST("/menu/import", menuHandler.ImportMenu,
+		middleware.RequirePermission(models.PermMenuEdit, models.PermMenuPrices, models.PermMenuAvailability, models.PermMenuPublish))
+	shopAdmin.GET("/menu/:id", menuHandler.GetMenuItem, menuView)
+	shopAdmin.PUT("/menu/:id", menuHandler.UpdateMenuItem,
+		middleware.RequireAnyPermission(models.PermMenuEdit, models.PermMenuPrices, models.PermMenuAvailability))
+	shopAdmin.DELETE("/menu/:id", menuHandler.DeleteMenuItem, middleware.RequirePermission(models.PermMenuEdit, models.PermMenuPublish))
+	shopAdmin.GET("/menu/:id/availability", menuHandler.GetMenuItemAvailability, menuView)
+	shopAdmin.PUT("/menu/:id/availability", menuHandler.UpdateMenuItemAvailability, menuEdit)
+
+	// Menu drafts: staged changes, signed previews, publishing and rollback
+	shopAdmin.GET("/menu/draft", menuDraftHandler.GetDraft, menuView)
+	shopAdmin.DELETE("/menu/draft", menuDraftHandler.DiscardDraft, menuEdit)
+	shopAdmin.POST("/menu/draft/items", menuDraftHandler.CreateDraftItem, middleware.RequirePermission(models.PermMenuEdit, models.PermMenuPrices))
+	shopAdmin.PUT("/menu/draft/items/:key", menuDraftHandler.UpdateDraftItem,
+		middleware.RequireAnyPermission(models.PermMenuEdit, models.PermMenuPrices))
+	shopAdmin.DELETE("/menu/draft/items/:key", menuDraftHandler.DeleteDraftItem, menuEdit)
+	shopAdmin.PUT("/menu/draft/categories/:id", menuDraftHandler.UpdateDraftCategory, categoriesManage)
+	shopAdmin.POST("/menu/draft/preview", menuDraftHandler.CreatePreviewLink, menuEdit)
+	shopAdmin.POST("/menu/draft/publish", menuDraftHandler.PublishDraft, menuPublish)
+	shopAdmin.GET("/menu/versions", menuDraftHandler.GetMenuVersions, menuView)
+	shopAdmin.GET("/menu/versions/:id", menuDraftHandler.GetMenuVersion, menuView)
+	shopAdmin.POST("/menu/versions/:id/rollback", menuDraftHandler.RollbackMenuVersion, menuPublish)
+
+	// Menu texts not yet translated
+	shopAdmin.GET("/translations/missing", translationHandler.GetMissingTranslations, menuView)
+
+	// Price history, scheduled price changes and bulk repricing
+	shopAdmin.GET("/menu/:id/prices/history", priceHandler.GetPriceHistory, menuView)
+	shopAdmin.POST("/menu/:id/prices/schedule", priceHandler.SchedulePriceChange, menuPrices)
+	shopAdmin.GET("/prices/scheduled", priceHandler.GetScheduledPriceChanges, menuView)
+	shopAdmin.DELETE("/prices/scheduled/:id", priceHandler.CancelScheduledPriceChange, menuPrices)
+	shopAdmin.POST("/prices/bulk/preview", priceHandler.PreviewBulkPriceChange, menuPrices)
+	shopAdmin.POST("/prices/bulk", priceHandler.CreateBulkPriceChange, menuPrices)
+	shopAdmin.DELETE("/prices/batches/:id", priceHandler.CancelPriceChangeBatch, menuPrices)
+
+	// Inventory: ingredients, recipes, item counts and the stock log
+	shopAdmin.GET("/inventory/ingredients", inventoryHandler.GetIngredients, menuView)
+	shopAdmin.POST("/inventory/ingredients", inventoryHandler.CreateIngredient, inventoryManage)
+	shopAdmin.PUT("/inventory/ingredients/:id", inventoryHandler.UpdateIngredient, inventoryManage)
+	shopAdmin.DELETE("/inventory/ingredients/:id", inventoryHandler.DeleteIngredient, inventoryManage)
+	shopAdmin.GET("/inventory/low-stock", inventoryHandler.GetLowStock, menuView)
+	shopAdmin.GET("/inventory/adjustments", inventoryHandler.GetStockAdjustments, menuView)
+	shopAdmin.POST("/inventory/adjustments", inventoryHandler.CreateStockAdjustment, inventoryManage)
+	shopAdmin.GET("/menu/:id/recipe", inventoryHandler.GetRecipe, menuView)
+	shopAdmin.PUT("/menu/:id/recipe", inventoryHandler.UpdateRecipe, inventoryManage)
+	shopAdmin.PUT("/menu/:id/stock", inventoryHandler.UpdateMenuItemStock, inventoryManage)
+
+	// Image uploads for menu items, logos and hero images
+	uploadImages := middleware.RequireAnyPermission(models.PermMenuEdit, models.PermShopSettings)
+	shopAdmin.GET("/uploads", uploadHandler.GetUploads, uploadImages)
+	shopAdmin.POST("/uploads", uploadHandler.UploadImage, uploadImages)
+	shopAdmin.DELETE("/uploads/:id", uploadHandler.DeleteUpload, uploadImages)
+
+	// Menu item option groups and options
+	shopAdmin.GET("/menu/:id/options", menuOptionHandler.GetOptionGroups, menuView)
+	shopAdmin.POST("/menu/:id/options", menuOptionHandler.CreateOptionGroup, menuEdit)
+	shopAdmin.PUT("/menu/:id/options/:groupId", menuOptionHandler.UpdateOptionGroup, menuEdit)
+	shopAdmin.DELETE("/menu/:id/options/:groupId", menuOptionHandler.DeleteOptionGroup, menuEdit)
+	shopAdmin.POST("/menu/:id/options/:groupId/items", menuOptionHandler.CreateOption, menuEdit)
+	shopAdmin.PUT("/menu/:id/options/:groupId/items/:optionId", menuOptionHandler.UpdateOption, menuEdit)
+	shopAdmin.DELETE("/menu/:id/options/:groupId/items/:optionId", menuOptionHandler.DeleteOption, menuEdit)
+
+	// Orders
+	shopAdmin.GET("/orders", orderHandler.GetOrders, ordersView)
+	shopAdmin.GET("/orders/:id", orderHandler.GetOrder, ordersView)
+	shopAdmin.PUT("/orders/:id/status", orderHandler.UpdateOrderStatus, ordersManage)
 
 	// Shop settings
-	shopAdmin.GET("/settings", menuHandler.GetShopSettings)
-	shopAdmin.PUT("/settings", menuHandler.UpdateShopSettings)
+	shopAdmin.GET("/settings", menuHandler.GetShopSettings, shopSettings)
+	shopAdmin.PUT("/settings", menuHandler.UpdateShopSettings, shopSettings)
+
+	// Opening hours and date exceptions (holidays, special hours)
+	shopAdmin.GET("/hours", openingHoursHandler.GetOpeningHours, shopSettings)
+	shopAdmin.PUT("/hours", openingHoursHandler.UpdateOpeningHours, shopSettings)
+	shopAdmin.PUT("/hours/exceptions/:date", openingHoursHandler.SetOpeningException, shopSettings)
+	shopAdmin.DELETE("/hours/exceptions/:date", openingHoursHandler.DeleteOpeningException, shopSettings)
+
+	// Shop categories and overrides of template categories
+	shopAdmin.GET("/shop/categories", categoryHandler.GetShopCategories, menuView)
+	shopAdmin.POST("/shop/categories", categoryHandler.CreateShopCategory, categoriesManage)
+	shopAdmin.PUT("/shop/categories/:id", categoryHandler.UpdateShopCategory, categoriesManage)
+	shopAdmin.DELETE("/shop/categories/:id", categoryHandler.DeleteShopCategory, categoriesManage)
+	shopAdmin.PUT("/shop/categories/:id/override", categoryHandler.OverrideCategory, categoriesManage)
+	shopAdmin.DELETE("/shop/categories/:id/override", categoryHandler.ResetCategoryOverride, categoriesManage)
+	shopAdmin.GET("/shop/categories/:id/availability", categoryHandler.GetCategoryAvailability, menuView)
+	shopAdmin.PUT("/shop/categories/:id/availability", categoryHandler.UpdateCategoryAvailability, categoriesManage)
+
+	// Staff roles and accounts
+	shopAdmin.GET("/roles", staffHandler.GetRoles, usersManage)
+	shopAdmin.POST("/roles", staffHandler.CreateRole, usersManage)
+	shopAdmin.GET("/staff", staffHandler.GetStaff, usersManage)
+	shopAdmin.POST("/staff", staffHandler.CreateStaff, usersManage)
+	shopAdmin.PUT("/staff/:id", staffHandler.UpdateStaff, usersManage)
 
-	// Categories (read-only for shop admins)
-	shopAdmin.GET("/categories", categoryHandler.GetCategories)
+	// Category list for both admin types. Registered once: the main admin and shop admin
+	// groups share the /api/admin prefix, so a second registration would replace the first.
+	e.GET("/api/admin/categories", categoryHandler.ListCategories,
+		middleware.OptionalTenantResolver(),
+		middleware.AuthMiddleware(),
+	)
 }
@@ -0,0 +1,164 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Horizon is how many days ahead Status looks for the next change
+const Horizon = 35
+
+// Span is a daily time range in minutes since midnight. An End at or before Start
+// wraps past midnight into the next day, so 18:00-02:00 is one evening span.
+type Span struct {
+	Start int
+	End   int
+}
+
+// DayFunc returns the spans of a calendar date
+type DayFunc func(date Date) []Span
+
+// Date is a calendar date without a time zone
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the calendar date of t in its location
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// ParseDate parses a YYYY-MM-DD date
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+	}
+	return DateOf(t), nil
+}
+
+// AddDays returns the date n days later
+func (d Date) AddDays(n int) Date {
+	return DateOf(time.Date(d.Year, d.Month, d.Day+n, 0, 0, 0, 0, time.UTC))
+}
+
+// Weekday returns the day of the week of d
+func (d Date) Weekday() time.Weekday {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, time.UTC).Weekday()
+}
+
+// String formats d as YYYY-MM-DD
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// ParseClock parses an HH:MM time of day into minutes since midnight. 24:00 is
+// accepted as the end of the day.
+func ParseClock(s string) (int, error) {
+	if len(s) != 5 || s[2] != ':' || !isDigits(s[:2]) || !isDigits(s[3:]) {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	h := int(s[0]-'0')*10 + int(s[1]-'0')
+	m := int(s[3]-'0')*10 + int(s[4]-'0')
+	if h > 24 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return h*60 + m, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseSpan parses a pair of HH:MM times
+func ParseSpan(start, end string) (Span, error) {
+	s, err := ParseClock(start)
+	if err != nil {
+		return Span{}, err
+	}
+	e, err := ParseClock(end)
+	if err != nil {
+		return Span{}, err
+	}
+	if s == e || s == 24*60 {
+		return Span{}, fmt.Errorf("invalid time range %s-%s", start, end)
+	}
+	return Span{Start: s, End: e}, nil
+}
+
+// Status is whether a schedule is active at a moment and when that next changes.
+// NextChangeAt is nil when nothing changes within Horizon days.
+type Status struct {
+	Open         bool       `json:"open_now"`
+	NextChangeAt *time.Time `json:"next_change_at"`
+}
+
+type window struct {
+	start, end time.Time
+}
+
+// At computes the status of the schedule at now, evaluating spans in loc
+func At(now time.Time, loc *time.Location, spans DayFunc) Status {
+	today := DateOf(now.In(loc))
+
+	// Start a day early for spans that wrap past midnight into today
+	var windows []window
+	for i := -1; i <= Horizon; i++ {
+		date := today.AddDays(i)
+		for _, span := range spans(date) {
+			windows = append(windows, spanWindow(date, span, loc))
+		}
+	}
+	windows = merge(windows)
+
+	for _, w := range windows {
+		if !now.Before(w.start) && now.Before(w.end) {
+			end := w.end
+			return Status{Open: true, NextChangeAt: &end}
+		}
+		if w.start.After(now) {
+			start := w.start
+			return Status{Open: false, NextChangeAt: &start}
+		}
+	}
+	return Status{}
+}
+
+func spanWindow(date Date, span Span, loc *time.Location) window {
+	start := time.Date(date.Year, date.Month, date.Day, 0, span.Start, 0, 0, loc)
+	endDay := date.Day
+	if span.End <= span.Start {
+		endDay++
+	}
+	end := time.Date(date.Year, date.Month, endDay, 0, span.End, 0, 0, loc)
+	return window{start: start, end: end}
+}
+
+// merge sorts windows and joins overlapping or touching ones, so a shop open
+// 08:00-24:00 and 00:00-02:00 is reported open until 02:00
+func merge(windows []window) []window {
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].start.Before(windows[j].start)
+	})
+
+	var merged []window
+	for _, w := range windows {
+		if n := len(merged); n > 0 && !w.start.After(merged[n-1].end) {
+			if w.end.After(merged[n-1].end) {
+				merged[n-1].end = w.end
+			}
+			continue
+		}
+		merged = append(merged, w)
+	}
+	return merged
+}
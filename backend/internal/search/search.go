@@ -0,0 +1,214 @@
+// Package search ranks short texts such as menu items against a query. Texts are
+// normalized so that Persian typed on Arabic keyboards, with or without diacritics,
+// ZWNJ or Persian digits still matches, and small typos are tolerated.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Field is a text of a searched record. Matches in fields with a higher weight rank
+// the record higher.
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Query is a normalized search query
+type Query struct {
+	phrase  string
+	compact string
+	tokens  []string
+}
+
+// NewQuery normalizes q. A query without letters or digits has no tokens and
+// matches nothing.
+func NewQuery(q string) Query {
+	phrase := Normalize(q)
+	return Query{
+		phrase:  phrase,
+		compact: strings.ReplaceAll(phrase, " ", ""),
+		tokens:  strings.Fields(phrase),
+	}
+}
+
+// Empty reports whether the query has nothing to search for
+func (q Query) Empty() bool {
+	return len(q.tokens) == 0
+}
+
+// Score rates how well fields match the query, 0 when they don't. Every word of the
+// query must match a word of some field, exactly, as a prefix, inside it or with a
+// typo. Fields that contain the whole query score extra.
+func (q Query) Score(fields ...Field) float64 {
+	if q.Empty() {
+		return 0
+	}
+
+	type normalizedField struct {
+		phrase  string
+		compact string
+		tokens  []string
+		weight  float64
+	}
+	normalized := make([]normalizedField, 0, len(fields))
+	for _, field := range fields {
+		phrase := Normalize(field.Text)
+		if phrase == "" {
+			continue
+		}
+		normalized = append(normalized, normalizedField{
+			phrase:  phrase,
+			compact: strings.ReplaceAll(phrase, " ", ""),
+			tokens:  strings.Fields(phrase),
+			weight:  field.Weight,
+		})
+	}
+
+	total := 0.0
+	for _, token := range q.tokens {
+		best := 0.0
+		for _, field := range normalized {
+			score := tokenScore(token, field.tokens)
+			// Words written apart in the query and together in the text, or the other
+			// way round, e.g. "می خواهم" and "میخواهم"
+			if score < 0.7 && len(q.tokens) > 1 && strings.Contains(field.compact, q.compact) {
+				score = 0.7
+			}
+			if score*field.weight > best {
+				best = score * field.weight
+			}
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+
+	bonus := 0.0
+	for _, field := range normalized {
+		if strings.Contains(field.phrase, q.phrase) && field.weight/2 > bonus {
+			bonus = field.weight / 2
+		}
+	}
+	return total + bonus
+}
+
+// tokenScore rates the best match of a query word among the words of a text
+func tokenScore(token string, words []string) float64 {
+	best := 0.0
+	length := len([]rune(token))
+	for i, word := range words {
+		score := 0.0
+		switch {
+		case word == token:
+			score = 1
+		case i+1 < len(words) && word+words[i+1] == token:
+			score = 0.9
+		case length >= 2 && strings.HasPrefix(word, token):
+			score = 0.8
+		case length >= 3 && strings.Contains(word, token):
+			score = 0.6
+		default:
+			if d := distance(token, word); d <= allowedTypos(length) {
+				score = 0.5 - 0.15*float64(d-1)
+			}
+		}
+		if score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// allowedTypos is how many edits a query word of length runes may be away from a
+// match: none for short words, whose typos match too much
+func allowedTypos(length int) int {
+	switch {
+	case length <= 3:
+		return 0
+	case length <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// distance is the number of insertions, deletions, substitutions and transpositions
+// of adjacent letters that turn a into b
+func distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	if diff := len(s) - len(t); diff > 2 || diff < -2 {
+		return 3
+	}
+
+	rows := make([][]int, len(s)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(t)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d := min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d = min(d, rows[i-2][j-2]+1)
+			}
+			rows[i][j] = d
+		}
+	}
+	return rows[len(s)][len(t)]
+}
+
+// letters maps Arabic letters and letter forms to the Persian letters people type
+var letters = map[rune]rune{
+	'ي': 'ی', // Arabic yeh
+	'ى': 'ی', // alef maksura
+	'ئ': 'ی',
+	'ك': 'ک', // Arabic kaf
+	'ة': 'ه',
+	'ۀ': 'ه',
+	'أ': 'ا',
+	'إ': 'ا',
+	'آ': 'ا',
+	'ٱ': 'ا',
+	'ؤ': 'و',
+}
+
+// Normalize lowercases s, maps Arabic letters to Persian ones and Persian and Arabic
+// digits to Latin ones, drops diacritics and tatweel, and turns ZWNJ, punctuation and
+// runs of spaces into single spaces
+func Normalize(s string) string {
+	var b strings.Builder
+	space := true
+	for _, r := range strings.ToLower(s) {
+		if mapped, ok := letters[r]; ok {
+			r = mapped
+		}
+		switch {
+		case r >= '۰' && r <= '۹':
+			r = '0' + (r - '۰')
+		case r >= '٠' && r <= '٩':
+			r = '0' + (r - '٠')
+		}
+
+		switch {
+		case r == 'ـ' || unicode.Is(unicode.Mn, r):
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			space = false
+		case !space:
+			b.WriteRune(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
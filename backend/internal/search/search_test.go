@@ -0,0 +1,136 @@
+package search
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"arabic yeh", "چاي", "چای"},
+		{"arabic kaf", "كيك", "کیک"},
+		{"zwnj", "نان‌برنجی", "نان برنجی"},
+		{"diacritics", "قَهوِه", "قهوه"},
+		{"tatweel", "قهـــوه", "قهوه"},
+		{"persian digits", "۱۲۳", "123"},
+		{"arabic digits", "١٢٣", "123"},
+		{"case and punctuation", "  Iced-LATTE!! ", "iced latte"},
+		{"only punctuation", "?!", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.in); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		text  string
+		match bool
+	}{
+		{"arabic yeh in query", "چاي", "چای ماسالا", true},
+		{"arabic yeh in text", "چای", "چاي ماسالا", true},
+		{"arabic kaf in query", "كيك", "کیک شکلاتی", true},
+		{"arabic kaf in text", "کیک", "كيك شكلاتي", true},
+		{"zwnj in text, joined query", "میخواهم", "می‌خواهم", true},
+		{"zwnj in text, split query", "می خواهم", "می‌خواهم", true},
+		{"joined text, split query", "می خواهم", "میخواهم", true},
+		{"diacritics", "قهوه", "قَهوِه", true},
+		{"tatweel", "قهوه", "قهـــوه", true},
+		{"persian digits in query", "۱۲۳", "Combo 123", true},
+		{"arabic digits in query", "١٢٣", "Combo 123", true},
+		{"latin digits in query", "123", "کومبو ۱۲۳", true},
+		{"other digits", "124", "Combo 123", false},
+		{"every word must match", "latte tea", "Iced latte", false},
+		{"empty query", "!!", "Latte", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := NewQuery(tt.query).Score(Field{Text: tt.text, Weight: 1})
+			if (score > 0) != tt.match {
+				t.Errorf("Score(%q, %q) = %v, want match %v", tt.query, tt.text, score, tt.match)
+			}
+		})
+	}
+}
+
+func TestScoreTypos(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		text  string
+		match bool
+	}{
+		{"3 letters, exact", "tea", "tea", true},
+		{"3 letters, one typo", "tex", "tea", false},
+		{"4 letters, one typo", "chia", "chai", true},
+		{"5 letters, one typo", "lattr", "latte", true},
+		{"5 letters, transposition", "latet", "latte", true},
+		{"5 letters, two typos", "lxtxe", "latte", false},
+		{"7 letters, one typo", "espreso", "espresso", true},
+		{"7 letters, two typos", "expreso", "espresso", false},
+		{"8 letters, two typos", "esprezzo", "espresso", true},
+		{"8 letters, three typos", "ezprezzo", "espresso", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := NewQuery(tt.query).Score(Field{Text: tt.text, Weight: 1})
+			if (score > 0) != tt.match {
+				t.Errorf("Score(%q, %q) = %v, want match %v", tt.query, tt.text, score, tt.match)
+			}
+		})
+	}
+}
+
+func TestAllowedTypos(t *testing.T) {
+	tests := []struct {
+		length int
+		want   int
+	}{
+		{1, 0},
+		{3, 0},
+		{4, 1},
+		{7, 1},
+		{8, 2},
+		{20, 2},
+	}
+	for _, tt := range tests {
+		if got := allowedTypos(tt.length); got != tt.want {
+			t.Errorf("allowedTypos(%d) = %d, want %d", tt.length, got, tt.want)
+		}
+	}
+}
+
+func TestScoreRanksNameOverDescription(t *testing.T) {
+	const nameWeight, descriptionWeight = 3, 1
+	query := NewQuery("latte")
+
+	inName := query.Score(
+		Field{Text: "Iced Latte", Weight: nameWeight},
+		Field{Text: "Espresso with cold milk", Weight: descriptionWeight},
+	)
+	inDescription := query.Score(
+		Field{Text: "Mocha", Weight: nameWeight},
+		Field{Text: "Like a latte with chocolate", Weight: descriptionWeight},
+	)
+	typoInName := query.Score(
+		Field{Text: "Iced Lattr", Weight: nameWeight},
+		Field{Text: "Espresso with cold milk", Weight: descriptionWeight},
+	)
+
+	if inDescription <= 0 {
+		t.Fatalf("description match scored %v, want a match", inDescription)
+	}
+	if inName <= inDescription {
+		t.Errorf("name match scored %v, not above description match %v", inName, inDescription)
+	}
+	if inName <= typoInName {
+		t.Errorf("exact name match scored %v, not above name typo %v", inName, typoInName)
+	}
+}
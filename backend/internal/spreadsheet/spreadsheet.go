@@ -0,0 +1,127 @@
+// Package spreadsheet reads and writes single tables of text cells as CSV or XLSX
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+)
+
+// Supported formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var (
+	// ErrUnknownFormat is returned for formats other than FormatCSV and FormatXLSX
+	ErrUnknownFormat = errors.New("spreadsheet: unknown format")
+	// ErrTooLarge is returned for tables with more rows or columns than allowed
+	ErrTooLarge = errors.New("spreadsheet: too many rows or columns")
+)
+
+// Limits caps the size of a table that is read. Empty cells and rows past the limits
+// are ignored, since spreadsheet programs often save formatted but empty cells.
+type Limits struct {
+	Rows    int
+	Columns int
+}
+
+// utf8BOM makes Excel read CSV files as UTF-8 instead of the system code page
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// zipMagic starts every XLSX file
+var zipMagic = []byte("PK\x03\x04")
+
+// ContentType returns the MIME type of a format
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// DetectFormat tells XLSX files from CSV by their content
+func DetectFormat(data []byte) string {
+	if bytes.HasPrefix(data, zipMagic) {
+		return FormatXLSX
+	}
+	return FormatCSV
+}
+
+// Write writes rows in format. sheet names the worksheet of XLSX files.
+func Write(w io.Writer, format, sheet string, rows [][]string) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, rows)
+	case FormatXLSX:
+		return writeXLSX(w, sheet, rows)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// Read returns the rows of a CSV file or of the first worksheet of an XLSX file.
+// Row i of the result is line i+1 of the file; rows may have different lengths.
+// Tables with cells past limits are rejected with ErrTooLarge.
+func Read(data []byte, format string, limits Limits) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(data, limits)
+	case FormatXLSX:
+		return readXLSX(data, limits)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+func writeCSV(w io.Writer, rows [][]string) error {
+	if _, err := w.Write(utf8BOM); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func readCSV(data []byte, limits Limits) ([][]string, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, utf8BOM)))
+	r.FieldsPerRecord = -1
+	// Keep line numbers aligned with the file: blank lines are returned as empty rows
+	var rows [][]string
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		record = trimRow(record)
+		if len(record) > limits.Columns {
+			return nil, ErrTooLarge
+		}
+		line, _ := r.FieldPos(0)
+		if line > limits.Rows {
+			if len(record) > 0 {
+				return nil, ErrTooLarge
+			}
+			continue
+		}
+		for len(rows) < line-1 {
+			rows = append(rows, nil)
+		}
+		rows = append(rows, record)
+	}
+}
+
+// trimRow drops the empty cells at the end of a row
+func trimRow(cells []string) []string {
+	for len(cells) > 0 && cells[len(cells)-1] == "" {
+		cells = cells[:len(cells)-1]
+	}
+	return cells
+}
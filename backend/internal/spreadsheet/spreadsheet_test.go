@@ -0,0 +1,140 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testLimits = Limits{Rows: 10, Columns: 4}
+
+func TestWriteRead(t *testing.T) {
+	rows := [][]string{
+		{"id", "name", "price", "note"},
+		{"1", "Latte", "45000", "with <milk> & \"foam\""},
+		{"", "چای ماسالا", "-12", "  spaced  "},
+		{},
+		{"007", "1.5", "true"},
+	}
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, format, "Menu", rows); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if got := DetectFormat(buf.Bytes()); got != format {
+				t.Errorf("DetectFormat() = %q, want %q", got, format)
+			}
+			got, err := Read(buf.Bytes(), format, testLimits)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if len(got) != len(rows) {
+				t.Fatalf("Read() returned %d rows, want %d: %q", len(got), len(rows), got)
+			}
+			for i := range rows {
+				if len(got[i]) == 0 && len(rows[i]) == 0 {
+					continue
+				}
+				if !reflect.DeepEqual(got[i], rows[i]) {
+					t.Errorf("row %d = %q, want %q", i+1, got[i], rows[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReadLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    [][]string
+		wantErr error
+	}{
+		{"at the limits", [][]string{{"a", "b", "c", "d"}, {"1"}, {}, {}, {}, {}, {}, {}, {}, {"10"}}, nil},
+		{"empty cells past the last column", [][]string{{"a", "b", "c", "d", "", ""}}, nil},
+		{"value past the last column", [][]string{{"a", "b", "c", "d", "e"}}, ErrTooLarge},
+		{"value past the last row", [][]string{{"a"}, {}, {}, {}, {}, {}, {}, {}, {}, {}, {"11"}}, ErrTooLarge},
+	}
+	for _, tt := range tests {
+		for _, format := range []string{FormatCSV, FormatXLSX} {
+			t.Run(tt.name+"/"+format, func(t *testing.T) {
+				var buf bytes.Buffer
+				if err := Write(&buf, format, "Menu", tt.rows); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := Read(buf.Bytes(), format, testLimits); !errors.Is(err, tt.wantErr) {
+					t.Errorf("Read() error = %v, want %v", err, tt.wantErr)
+				}
+			})
+		}
+	}
+}
+
+// xlsxWithSheet returns a workbook like writeXLSX writes, with sheetData as the rows
+func xlsxWithSheet(t *testing.T, sheetData string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"[Content_Types].xml":        xlsxContentTypes,
+		"_rels/.rels":                xlsxRootRels,
+		"xl/workbook.xml":            strings.Replace(xlsxWorkbook, "%s", "Menu", 1),
+		"xl/_rels/workbook.xml.rels": xlsxWorkbookRels,
+		"xl/worksheets/sheet1.xml":   `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadXLSXFarCells(t *testing.T) {
+	tests := []struct {
+		name    string
+		sheet   string
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name:  "formatted empty cells far right and below",
+			sheet: `<row r="1"><c r="A1"><v>1</v></c><c r="XFD1" s="1"/></row><row r="1048576"><c r="A1048576" s="1"/></row>`,
+			want:  [][]string{{"1"}},
+		},
+		{
+			name:    "value far right",
+			sheet:   `<row r="1"><c r="A1"><v>1</v></c><c r="XFD1"><v>2</v></c></row>`,
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:    "value far below",
+			sheet:   `<row r="1048576"><c r="A1048576"><v>1</v></c></row>`,
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:    "columns out of order",
+			sheet:   `<row r="1"><c r="B1"><v>1</v></c><c r="A1"><v>2</v></c></row>`,
+			wantErr: ErrInvalidXLSX,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(xlsxWithSheet(t, tt.sheet), FormatXLSX, testLimits)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Read() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Read() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxPartSize limits how much of each part of an XLSX file is decompressed
+const maxPartSize = 32 << 20
+
+// ErrInvalidXLSX is returned for files that are not readable XLSX workbooks
+var ErrInvalidXLSX = errors.New("spreadsheet: invalid XLSX file")
+
+// integerCell matches text that is written as a number cell
+var integerCell = regexp.MustCompile(`^-?(0|[1-9][0-9]{0,14})$`)
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+)
+
+// writeXLSX writes a workbook with one worksheet. Integers are written as number
+// cells and everything else as inline strings, so no shared strings or styles part
+// is needed.
+func writeXLSX(w io.Writer, sheet string, rows [][]string) error {
+	var sheetXML bytes.Buffer
+	sheetXML.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	sheetXML.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(&sheetXML, `<row r="%d">`, i+1)
+		for j, value := range row {
+			ref := columnName(j) + strconv.Itoa(i+1)
+			if integerCell.MatchString(value) {
+				fmt.Fprintf(&sheetXML, `<c r="%s"><v>%s</v></c>`, ref, value)
+				continue
+			}
+			fmt.Fprintf(&sheetXML, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			if err := xml.EscapeText(&sheetXML, []byte(value)); err != nil {
+				return err
+			}
+			sheetXML.WriteString(`</t></is></c>`)
+		}
+		sheetXML.WriteString(`</row>`)
+	}
+	sheetXML.WriteString(`</sheetData></worksheet>`)
+
+	var sheetName bytes.Buffer
+	if err := xml.EscapeText(&sheetName, []byte(sheet)); err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	parts := []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", []byte(xlsxContentTypes)},
+		{"_rels/.rels", []byte(xlsxRootRels)},
+		{"xl/workbook.xml", []byte(fmt.Sprintf(xlsxWorkbook, sheetName.String()))},
+		{"xl/_rels/workbook.xml.rels", []byte(xlsxWorkbookRels)},
+		{"xl/worksheets/sheet1.xml", sheetXML.Bytes()},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(part.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// columnName turns a zero-based column index into its letters: 0 → A, 27 → AB
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// columnIndex turns the letters of a cell reference such as "AB12" into a
+// zero-based column index
+func columnIndex(ref string) (int, bool) {
+	index := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+		letters++
+	}
+	if letters == 0 || letters > 3 {
+		return 0, false
+	}
+	return index - 1, true
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxWorkbookXML struct {
+	Sheets []struct {
+		RelationshipID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxText is a shared or inline string, either plain or split into rich text runs
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var s strings.Builder
+	for _, run := range t.Runs {
+		s.WriteString(run.T)
+	}
+	return s.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string   `xml:"r,attr"`
+			T      string   `xml:"t,attr"`
+			V      string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX returns the cells of the first worksheet as text
+func readXLSX(data []byte, limits Limits) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrInvalidXLSX
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var workbook xlsxWorkbookXML
+	if err := decodePart(files, "xl/workbook.xml", &workbook); err != nil || len(workbook.Sheets) == 0 {
+		return nil, ErrInvalidXLSX
+	}
+	var rels xlsxRelationships
+	if err := decodePart(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, ErrInvalidXLSX
+	}
+	sheetPath := ""
+	for _, rel := range rels.Relationships {
+		if rel.ID == workbook.Sheets[0].RelationshipID {
+			sheetPath = rel.Target
+		}
+	}
+	if sheetPath == "" {
+		return nil, ErrInvalidXLSX
+	}
+	if strings.HasPrefix(sheetPath, "/") {
+		sheetPath = strings.TrimPrefix(sheetPath, "/")
+	} else {
+		sheetPath = path.Join("xl", sheetPath)
+	}
+
+	// Workbooks written by Excel keep their text in the shared strings part
+	var shared xlsxSharedStrings
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodePart(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, ErrInvalidXLSX
+		}
+	}
+
+	var sheet xlsxWorksheet
+	if err := decodePart(files, sheetPath, &sheet); err != nil {
+		return nil, ErrInvalidXLSX
+	}
+
+	var rows [][]string
+	for _, row := range sheet.Rows {
+		line := row.R
+		if line == 0 {
+			line = len(rows) + 1
+		}
+		if line < len(rows)+1 || line > 1<<20 {
+			return nil, ErrInvalidXLSX
+		}
+
+		// Cells are read up to the column limit; past it only empty ones are allowed
+		var cells []string
+		next := 0
+		for _, cell := range row.Cells {
+			col := next
+			if cell.R != "" {
+				var ok bool
+				if col, ok = columnIndex(cell.R); !ok || col < next {
+					return nil, ErrInvalidXLSX
+				}
+			}
+			next = col + 1
+
+			value := cell.V
+			switch cell.T {
+			case "s":
+				i, err := strconv.Atoi(cell.V)
+				if err != nil || i < 0 || i >= len(shared.Items) {
+					return nil, ErrInvalidXLSX
+				}
+				value = shared.Items[i].String()
+			case "inlineStr":
+				value = cell.Inline.String()
+			case "b":
+				value = strconv.FormatBool(cell.V == "1")
+			}
+
+			if col >= limits.Columns {
+				if value != "" {
+					return nil, ErrTooLarge
+				}
+				continue
+			}
+			for len(cells) < col {
+				cells = append(cells, "")
+			}
+			cells = append(cells, value)
+		}
+
+		cells = trimRow(cells)
+		if line > limits.Rows {
+			if len(cells) > 0 {
+				return nil, ErrTooLarge
+			}
+			continue
+		}
+		for len(rows) < line-1 {
+			rows = append(rows, nil)
+		}
+		rows = append(rows, cells)
+	}
+	return rows, nil
+}
+
+// decodePart unmarshals an XML part of a workbook
+func decodePart(files map[string]*zip.File, name string, v any) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("missing part %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxPartSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxPartSize {
+		return fmt.Errorf("part %s is too large", name)
+	}
+	return xml.Unmarshal(data, v)
+}
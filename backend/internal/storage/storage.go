@@ -0,0 +1,57 @@
+// Package storage stores uploaded files on local disk or in an S3-compatible bucket
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"coffee-shop-platform/internal/config"
+)
+
+// ErrNotFound is returned when a key does not exist
+var ErrNotFound = errors.New("storage: file not found")
+
+// Storage puts, fetches and deletes files by key. Keys are slash-separated paths
+// such as shops/1/3f9c....jpg.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	// URL returns the public URL of a key
+	URL(key string) string
+}
+
+// Files is the configured storage, set by Init
+var Files Storage
+
+// Init configures Files from the storage config
+func Init(cfg *config.StorageConfig) error {
+	switch cfg.Driver {
+	case "", "local":
+		Files = NewLocal(cfg.LocalDir, cfg.PublicURL)
+	case "s3":
+		s3, err := NewS3(cfg.S3)
+		if err != nil {
+			return err
+		}
+		Files = s3
+	default:
+		return fmt.Errorf("unknown storage driver %q, expected local or s3", cfg.Driver)
+	}
+	return nil
+}
+
+// validKey rejects keys that could escape the storage root
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("storage: invalid key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("storage: invalid key %q", key)
+		}
+	}
+	return nil
+}
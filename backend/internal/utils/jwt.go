@@ -9,13 +9,34 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func GenerateJWT(userID uint, username, userType string, shopID *uint, cfg *config.Config) (string, error) {
+// TokenSubject is the identity and authorization carried by an access token
+type TokenSubject struct {
+	UserID      uint
+	Username    string
+	Type        string
+	ShopID      *uint
+	Role        string
+	Permissions []string
+}
+
+// GenerateJWT issues a short-lived access token. familyID ties it to the refresh
+// token family it was issued from, so revoking the family revokes the token.
+func GenerateJWT(subject TokenSubject, familyID string, cfg *config.Config) (string, error) {
+	jti, err := GenerateTokenID()
+	if err != nil {
+		return "", err
+	}
+
 	claims := &jwt.MapClaims{
-		"user_id":  userID,
-		"username": username,
-		"type":     userType,
-		"shop_id":  shopID,
-		"exp":      time.Now().Add(time.Duration(cfg.JWT.ExpireHours) * time.Hour).Unix(),
+		"user_id":  subject.UserID,
+		"username": subject.Username,
+		"type":     subject.Type,
+		"shop_id":  subject.ShopID,
+		"role":     subject.Role,
+		"perms":    subject.Permissions,
+		"fid":      familyID,
+		"jti":      jti,
+		"exp":      time.Now().Add(AccessTokenTTL(cfg)).Unix(),
 		"iat":      time.Now().Unix(),
 	}
 
@@ -26,7 +47,7 @@ func GenerateJWT(userID uint, username, userType string, shopID *uint, cfg *conf
 func ParseJWT(tokenString string, secret string) (*jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		return []byte(secret), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
 		return nil, err
@@ -38,3 +59,48 @@ func ParseJWT(tokenString string, secret string) (*jwt.MapClaims, error) {
 
 	return nil, errors.New("invalid token")
 }
+
+// menuPreviewTokenType marks tokens that only grant a look at a menu draft
+const menuPreviewTokenType = "menu_preview"
+
+// GenerateMenuPreviewToken signs a link to a shop's menu draft. The token has no
+// token family, so it is never accepted as an access token.
+func GenerateMenuPreviewToken(shopID, draftID uint, expiresAt time.Time, cfg *config.Config) (string, error) {
+	claims := &jwt.MapClaims{
+		"type":     menuPreviewTokenType,
+		"shop_id":  shopID,
+		"draft_id": draftID,
+		"exp":      expiresAt.Unix(),
+		"iat":      time.Now().Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(cfg.JWT.Secret))
+}
+
+// ParseMenuPreviewToken returns the shop and draft of a valid menu preview token
+func ParseMenuPreviewToken(tokenString string, secret string) (shopID, draftID uint, err error) {
+	claims, err := ParseJWT(tokenString, secret)
+	if err != nil {
+		return 0, 0, err
+	}
+	if tokenType, _ := (*claims)["type"].(string); tokenType != menuPreviewTokenType {
+		return 0, 0, errors.New("not a menu preview token")
+	}
+	shop, ok1 := (*claims)["shop_id"].(float64)
+	draft, ok2 := (*claims)["draft_id"].(float64)
+	if !ok1 || !ok2 {
+		return 0, 0, errors.New("invalid token")
+	}
+	return uint(shop), uint(draft), nil
+}
+
+// AccessTokenTTL is how long access tokens are valid
+func AccessTokenTTL(cfg *config.Config) time.Duration {
+	return time.Duration(cfg.JWT.AccessExpireMinutes) * time.Minute
+}
+
+// RefreshTokenTTL is how long refresh tokens are valid
+func RefreshTokenTTL(cfg *config.Config) time.Duration {
+	return time.Duration(cfg.JWT.RefreshExpireDays) * 24 * time.Hour
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// Slugify turns a name into a lowercase URL slug of ASCII letters, digits and
+// hyphens. Names without any such characters, e.g. Persian names, give "".
+func Slugify(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		default:
+			hyphen = true
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > 60 {
+		slug = strings.TrimRight(slug[:60], "-")
+	}
+	return slug
+}
+
+// IsValidSlug reports whether s is a well-formed slug
+func IsValidSlug(s string) bool {
+	return len(s) <= 60 && slugPattern.MatchString(s)
+}
@@ -0,0 +1,321 @@
+// Package validation checks request structs against their `validate` struct tags and
+// binds requests with validation for Echo.
+//
+// Supported rules: required, omitempty, min, max, gt, oneof, url, fqdn, subdomain and
+// dive. Pointers are only validated when set. Nested structs are always validated;
+// the elements of slices with dive. Fields are named by their JSON names, e.g.
+// "price_tiers[0].label". Tags with other rules are reported as a TagError.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+)
+
+// FieldError is a request field that broke a validation rule
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Errors are the fields of a request that failed validation
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldError := range e {
+		messages[i] = fieldError.Field + " " + fieldError.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// TagError is a validate tag that can't be checked, such as an unknown rule or a
+// non-numeric min. It is a mistake in the request type, not in the request.
+type TagError struct {
+	Field string
+	Rule  string
+	Err   error
+}
+
+func (e *TagError) Error() string {
+	return fmt.Sprintf("validation: %v %q on %s", e.Err, e.Rule, e.Field)
+}
+
+func (e *TagError) Unwrap() error {
+	return e.Err
+}
+
+// Validator validates structs by their tags. It implements echo.Validator.
+type Validator struct{}
+
+// New returns a Validator
+func New() *Validator {
+	return &Validator{}
+}
+
+// Validate checks i, a struct or a pointer to one, and returns Errors listing every
+// field that fails, or nil. A tag it can't check returns a TagError.
+func (v *Validator) Validate(i any) error {
+	value := reflect.ValueOf(i)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs Errors
+	if err := validateStruct(value, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Binder binds requests with Echo's default binder and validates the result, so
+// every c.Bind also enforces the request's validate tags
+type Binder struct {
+	echo.DefaultBinder
+	validator *Validator
+}
+
+// NewBinder returns a Binder validating with v
+func NewBinder(v *Validator) *Binder {
+	return &Binder{validator: v}
+}
+
+// Bind binds the request into i and validates it
+func (b *Binder) Bind(i any, c echo.Context) error {
+	if err := b.DefaultBinder.Bind(i, c); err != nil {
+		return err
+	}
+	return b.validator.Validate(i)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func validateStruct(value reflect.Value, prefix string, errs *Errors) error {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		// Embedded structs without a JSON name are inlined, as by encoding/json
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			if err := validateStruct(value.Field(i), prefix, errs); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		if err := validateField(value.Field(i), prefix+name, field.Tag.Get("validate"), errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateField(value reflect.Value, path, tag string, errs *Errors) error {
+	rules := splitRules(tag)
+
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			if hasRule(rules, "required") {
+				*errs = append(*errs, newFieldError(path, "required", "", value))
+			}
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "omitempty":
+			if value.IsZero() {
+				return nil
+			}
+			continue
+		case "dive":
+			if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+				elementTag := strings.Join(rules[i+1:], ",")
+				for j := 0; j < value.Len(); j++ {
+					if err := validateField(value.Index(j), fmt.Sprintf("%s[%d]", path, j), elementTag, errs); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+
+		ok, err := check(name, param, value)
+		if err != nil {
+			return &TagError{Field: path, Rule: rule, Err: err}
+		}
+		if !ok {
+			*errs = append(*errs, newFieldError(path, name, param, value))
+			// Later rules of a field that already failed would only repeat it
+			return nil
+		}
+	}
+
+	if value.Kind() == reflect.Struct && value.Type() != timeType {
+		return validateStruct(value, path+".", errs)
+	}
+	return nil
+}
+
+// splitRules splits a validate tag into its rules. The values of oneof are separated
+// by spaces, so they stay together.
+func splitRules(tag string) []string {
+	if tag == "" {
+		return nil
+	}
+	return strings.Split(tag, ",")
+}
+
+func hasRule(rules []string, name string) bool {
+	for _, rule := range rules {
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors of a TagError
+var (
+	ErrUnknownRule  = errors.New("unknown rule")
+	ErrInvalidParam = errors.New("invalid parameter of rule")
+)
+
+// check reports whether value passes a rule
+func check(rule, param string, value reflect.Value) (bool, error) {
+	switch rule {
+	case "required":
+		return !value.IsZero(), nil
+	case "min":
+		return compare(value, param, func(n, limit float64) bool { return n >= limit })
+	case "max":
+		return compare(value, param, func(n, limit float64) bool { return n <= limit })
+	case "gt":
+		return compare(value, param, func(n, limit float64) bool { return n > limit })
+	case "oneof":
+		s := fmt.Sprint(value.Interface())
+		for _, option := range strings.Fields(param) {
+			if s == option {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "url":
+		return value.Kind() == reflect.String && IsURL(value.String()), nil
+	case "fqdn":
+		return value.Kind() == reflect.String && fqdnPattern.MatchString(value.String()), nil
+	case "subdomain":
+		return value.Kind() == reflect.String && IsSubdomain(value.String()), nil
+	}
+	return false, ErrUnknownRule
+}
+
+// compare applies a numeric comparison to a number, or to the length of a string in
+// characters or of a slice or map
+func compare(value reflect.Value, param string, ok func(n, limit float64) bool) (bool, error) {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false, ErrInvalidParam
+	}
+
+	var n float64
+	switch value.Kind() {
+	case reflect.String:
+		n = float64(utf8.RuneCountInString(value.String()))
+	case reflect.Slice, reflect.Map, reflect.Array:
+		n = float64(value.Len())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = value.Float()
+	default:
+		return true, nil
+	}
+	return ok(n, limit), nil
+}
+
+var (
+	fqdnPattern      = regexp.MustCompile(`^(?i)([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}\.?$`)
+	subdomainPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+)
+
+// IsURL reports whether s is an absolute http(s) URL or a path on this server, such
+// as the URL of an image in local storage
+func IsURL(s string) bool {
+	if strings.HasPrefix(s, "/") && !strings.HasPrefix(s, "//") {
+		return true
+	}
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// IsSubdomain reports whether s can be a tenant's subdomain: lowercase letters,
+// digits and hyphens, not starting or ending with a hyphen
+func IsSubdomain(s string) bool {
+	return subdomainPattern.MatchString(s)
+}
+
+// newFieldError describes a failed rule in words
+func newFieldError(path, rule, param string, value reflect.Value) FieldError {
+	var message string
+	switch rule {
+	case "required":
+		message = "is required"
+	case "min", "max", "gt":
+		bound := map[string]string{"min": "at least", "max": "at most", "gt": "more than"}[rule]
+		switch value.Kind() {
+		case reflect.String:
+			message = fmt.Sprintf("must be %s %s characters long", bound, param)
+		case reflect.Slice, reflect.Map, reflect.Array:
+			message = fmt.Sprintf("must have %s %s items", bound, param)
+		default:
+			message = fmt.Sprintf("must be %s %s", bound, param)
+		}
+	case "oneof":
+		message = "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "url":
+		message = "must be an http(s) URL or a path starting with /"
+	case "fqdn":
+		message = "must be a domain name"
+	case "subdomain":
+		message = "may only contain lowercase letters, digits and hyphens, and can't start or end with a hyphen"
+	default:
+		message = "is invalid"
+	}
+	return FieldError{Field: path, Rule: rule, Param: param, Message: message}
+}
@@ -0,0 +1,129 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testTier struct {
+	Label string `json:"label" validate:"required,max=5"`
+	Price int    `json:"price" validate:"min=0"`
+}
+
+// Details is exported so it can be embedded like the request types embed theirs
+type Details struct {
+	Note string `json:"note" validate:"omitempty,min=3"`
+}
+
+type testRequest struct {
+	Name     string     `json:"name" validate:"required,min=2"`
+	Size     string     `json:"size,omitempty" validate:"omitempty,oneof=small large"`
+	Image    string     `json:"image_url" validate:"omitempty,url"`
+	Premium  *int       `json:"price_premium" validate:"omitempty,min=0"`
+	Category *uint      `json:"category_id" validate:"required"`
+	Tiers    []testTier `json:"price_tiers" validate:"omitempty,dive"`
+	Tags     []string   `json:"tags" validate:"max=2,dive,min=2"`
+	Extra    Details    `json:"extra"`
+	Ignored  string     `json:"-" validate:"required"`
+	Details
+}
+
+func intPtr(n int) *int { return &n }
+
+func uintPtr(n uint) *uint { return &n }
+
+// validRequest returns a request that passes every rule
+func validRequest() testRequest {
+	return testRequest{Name: "Latte", Category: uintPtr(1)}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(r *testRequest)
+		want   []string
+	}{
+		{"valid", func(r *testRequest) {}, nil},
+		{"required string", func(r *testRequest) { r.Name = "" }, []string{"name:required"}},
+		{"min counts characters", func(r *testRequest) { r.Name = "چا" }, nil},
+		{"min", func(r *testRequest) { r.Name = "L" }, []string{"name:min"}},
+		{"omitempty skips zero", func(r *testRequest) { r.Size = "" }, nil},
+		{"oneof", func(r *testRequest) { r.Size = "medium" }, []string{"size:oneof"}},
+		{"url", func(r *testRequest) { r.Image = "ftp://example.com/a.jpg" }, []string{"image_url:url"}},
+		{"local path url", func(r *testRequest) { r.Image = "/uploads/a.jpg" }, nil},
+		{"unset pointer", func(r *testRequest) { r.Premium = nil }, nil},
+		{"set pointer to zero", func(r *testRequest) { r.Premium = intPtr(0) }, nil},
+		{"set pointer", func(r *testRequest) { r.Premium = intPtr(-1) }, []string{"price_premium:min"}},
+		{"required pointer", func(r *testRequest) { r.Category = nil }, []string{"category_id:required"}},
+		{"required pointer to zero", func(r *testRequest) { r.Category = uintPtr(0) }, []string{"category_id:required"}},
+		{"dive into structs", func(r *testRequest) {
+			r.Tiers = []testTier{{Label: "Small"}, {Label: "", Price: -1}, {Label: "Grande"}}
+		}, []string{"price_tiers[1].label:required", "price_tiers[1].price:min", "price_tiers[2].label:max"}},
+		{"rules before dive apply to the slice", func(r *testRequest) { r.Tags = []string{"ab", "cd", "ef"} }, []string{"tags:max"}},
+		{"rules after dive apply to elements", func(r *testRequest) { r.Tags = []string{"ab", "c"} }, []string{"tags[1]:min"}},
+		{"nested struct", func(r *testRequest) { r.Extra.Note = "ab" }, []string{"extra.note:min"}},
+		{"embedded struct is inlined", func(r *testRequest) { r.Details.Note = "ab" }, []string{"note:min"}},
+		{"json - is skipped", func(r *testRequest) { r.Ignored = "" }, nil},
+		{"every failing field", func(r *testRequest) {
+			r.Name = ""
+			r.Category = nil
+		}, []string{"name:required", "category_id:required"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validRequest()
+			tt.modify(&req)
+			err := New().Validate(&req)
+
+			var got []string
+			var errs Errors
+			if errors.As(err, &errs) {
+				for _, fieldError := range errs {
+					got = append(got, fieldError.Field+":"+fieldError.Rule)
+				}
+			} else if err != nil {
+				t.Fatalf("Validate() error = %v, want field errors", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() failed %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTagErrors(t *testing.T) {
+	type unknownRule struct {
+		Name string `json:"name" validate:"required,email"`
+	}
+	type invalidParam struct {
+		Items []string `json:"items" validate:"dive,min=two"`
+	}
+	tests := []struct {
+		name    string
+		req     any
+		wantErr error
+		field   string
+	}{
+		{"unknown rule", &unknownRule{Name: "a"}, ErrUnknownRule, "name"},
+		{"invalid parameter", &invalidParam{Items: []string{"a"}}, ErrInvalidParam, "items[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Validate(tt.req)
+			var tagErr *TagError
+			if !errors.As(err, &tagErr) || !errors.Is(err, tt.wantErr) || tagErr.Field != tt.field {
+				t.Errorf("Validate() error = %v, want %v on %s", err, tt.wantErr, tt.field)
+			}
+		})
+	}
+}
+
+func TestValidateNonStruct(t *testing.T) {
+	var nilRequest *testRequest
+	for _, v := range []any{nil, nilRequest, 3, "text"} {
+		if err := New().Validate(v); err != nil {
+			t.Errorf("Validate(%#v) = %v, want nil", v, err)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"coffee-shop-platform/internal/config"
 	"coffee-shop-platform/internal/database"
+	"coffee-shop-platform/internal/handlers"
 	"coffee-shop-platform/internal/models"
 	"coffee-shop-platform/internal/utils"
 )
@@ -178,6 +179,7 @@ func seedSampleTenant() error {
 	// Create sample coffee shop
 	coffeeShop := models.CoffeeShop{
 		TenantID:     tenant.ID,
+		Slug:         "downtown",
 		Name:         "Demo Coffee Shop",
 		Location:     "Tehran, Iran",
 		Phone:        "+98-21-12345678",
@@ -198,8 +200,14 @@ func seedSampleTenant() error {
 		return err
 	}
 
+	var ownerRole models.Role
+	if err := database.DB.Where("name = ? AND is_system = ?", models.RoleOwner, true).First(&ownerRole).Error; err != nil {
+		return err
+	}
+
 	admin := models.ShopAdmin{
 		CoffeeShopID: coffeeShop.ID,
+		RoleID:       &ownerRole.ID,
 		Username:     "shopadmin",
 		PasswordHash: passwordHash,
 		IsActive:     true,
@@ -225,15 +233,24 @@ func seedSampleTenant() error {
 	menuItems := getSampleMenuItems(coffeeShop.ID, categoryMap)
 
 	for _, item := range menuItems {
+		// Dual-priced items are blend vs 100% arabica
+		item.PriceTiers = item.LegacyPriceTiers("ترکیبی", "100% عربیکا")
 		if err := database.DB.Create(&item).Error; err != nil {
 			return err
 		}
 	}
 
+	// Offer the sample menu to new shops
+	menuTemplate, err := handlers.SaveMenuTemplate(database.DB, coffeeShop.ID, "Sample menu", "Menu of the demo coffee shop")
+	if err != nil {
+		return err
+	}
+
 	log.Printf("Created sample tenant: %s (subdomain: %s)", tenant.Name, tenant.Subdomain)
 	log.Printf("Created sample coffee shop: %s", coffeeShop.Name)
 	log.Printf("Created shop admin: %s", admin.Username)
 	log.Printf("Created %d menu items", len(menuItems))
+	log.Printf("Created menu template: %s", menuTemplate.Name)
 
 	return nil
 }
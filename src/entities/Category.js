@@ -24,10 +24,11 @@ export class Category {
     }
   }
 
+  // Categories fill pickers, so the largest page is loaded; shops don't come near it
   static async getAllCategories() {
     try {
-      const response = await apiService.getAllCategories();
-      return response.map(cat => new Category(cat));
+      const response = await apiService.getAllCategories({ limit: 200 });
+      return response.data.map(cat => new Category(cat));
     } catch (error) {
       console.error('Failed to fetch all categories:', error);
       throw error;
@@ -36,8 +37,8 @@ export class Category {
 
   static async getCategories() {
     try {
-      const response = await apiService.getCategories();
-      return response.map(cat => new Category(cat));
+      const response = await apiService.getCategories({ limit: 200 });
+      return response.data.map(cat => new Category(cat));
     } catch (error) {
       console.error('Failed to fetch categories:', error);
       throw error;
@@ -17,10 +17,16 @@ export class MenuItem {
   }
 
   // Static methods for API operations
-  static async list() {
+  // Loads one page of menu items: { items, total, nextCursor }. Pass nextCursor back
+  // to load the following page.
+  static async list(cursor = '') {
     try {
-      const response = await apiService.getMenuItems();
-      return response.map(item => new MenuItem(item));
+      const response = await apiService.getMenuItems({ cursor });
+      return {
+        items: response.data.map(item => new MenuItem(item)),
+        total: response.total,
+        nextCursor: response.next_cursor || '',
+      };
     } catch (error) {
       console.error('Failed to fetch menu items:', error);
       throw error;
@@ -24,6 +24,8 @@ import apiService from "../services/api";
 export default function AdminDashboardPage() {
   const navigate = useNavigate();
   const [menuItems, setMenuItems] = useState([]);
+  const [menuTotal, setMenuTotal] = useState(0);
+  const [menuCursor, setMenuCursor] = useState("");
   const [categories, setCategories] = useState([]);
   const [cafeSettings, setCafeSettings] = useState({});
   const [loading, setLoading] = useState(true);
@@ -66,17 +68,19 @@ export default function AdminDashboardPage() {
     try {
       console.log("=== AdminDashboard.loadData() START ===");
       
-      const [menuData, categoriesData, settingsData] = await Promise.all([
+      const [menuPage, categoriesData, settingsData] = await Promise.all([
         MenuItem.list(),
         Category.getCategories(),
         CafeSettings.getSettings()
       ]);
 
-      console.log("Menu items loaded:", menuData.length);
+      console.log("Menu items loaded:", menuPage.items.length, "of", menuPage.total);
       console.log("Categories loaded:", categoriesData.length);
       console.log("Settings loaded:", settingsData);
 
-      setMenuItems(menuData);
+      setMenuItems(menuPage.items);
+      setMenuTotal(menuPage.total);
+      setMenuCursor(menuPage.nextCursor);
       setCategories(categoriesData);
       setCafeSettings(settingsData);
       
@@ -89,6 +93,23 @@ export default function AdminDashboardPage() {
     }
   };
 
+  const loadMoreMenuItems = async () => {
+    setLoading(true);
+    setError("");
+
+    try {
+      const menuPage = await MenuItem.list(menuCursor);
+      setMenuItems(prev => [...prev, ...menuPage.items]);
+      setMenuTotal(menuPage.total);
+      setMenuCursor(menuPage.nextCursor);
+    } catch (error) {
+      console.error("Failed to load more menu items:", error);
+      setError(error.message || "خطا در بارگذاری اطلاعات");
+    } finally {
+      setLoading(false);
+    }
+  };
+
   const handleInputChange = (e) => {
     const { name, value, type, checked } = e.target;
     setFormData(prev => ({
@@ -118,6 +139,7 @@ export default function AdminDashboardPage() {
       } else {
         const newItem = await MenuItem.create(data);
         setMenuItems(prev => [...prev, newItem]);
+        setMenuTotal(prev => prev + 1);
       }
 
       resetForm();
@@ -150,6 +172,7 @@ export default function AdminDashboardPage() {
     try {
       await item.delete();
       setMenuItems(prev => prev.filter(i => i.id !== item.id));
+      setMenuTotal(prev => prev - 1);
     } catch (error) {
       console.error("Failed to delete menu item:", error);
       setError(error.message || "خطا در حذف آیتم");
@@ -488,6 +511,20 @@ export default function AdminDashboardPage() {
                 </Card>
               ))}
             </div>
+
+            {/* Menu items are loaded a page at a time */}
+            {menuCursor && (
+              <div className="text-center">
+                <Button
+                  onClick={loadMoreMenuItems}
+                  disabled={loading}
+                  variant="outline"
+                  className="text-amber-600 border-amber-300 hover:bg-amber-50"
+                >
+                  {loading ? "در حال بارگذاری..." : `نمایش آیتم‌های بیشتر (${menuItems.length} از ${menuTotal})`}
+                </Button>
+              </div>
+            )}
           </div>
         )}
 
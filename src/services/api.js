@@ -40,7 +40,11 @@ class ApiService {
       
       if (!response.ok) {
         const errorData = await response.json().catch(() => ({}));
-        throw new Error(errorData.error || `HTTP error! status: ${response.status}`);
+        // Validation errors name the fields that failed in their message
+        const message = errorData.fields ? errorData.message : errorData.error;
+        const error = new Error(message || `HTTP error! status: ${response.status}`);
+        error.fields = errorData.fields;
+        throw error;
       }
 
       return await response.json();
@@ -50,6 +54,15 @@ class ApiService {
     }
   }
 
+  // Fetches one page of a list endpoint: { data, total, next_cursor }. Pass the
+  // next_cursor of a page as cursor to fetch the page after it.
+  async requestPage(endpoint, { limit = 50, cursor = '' } = {}) {
+    const separator = endpoint.includes('?') ? '&' : '?';
+    return this.request(
+      `${endpoint}${separator}limit=${limit}${cursor ? `&cursor=${encodeURIComponent(cursor)}` : ''}`
+    );
+  }
+
   // Auth endpoints
   async loginMainAdmin(credentials) {
     return this.request('/api/auth/main-admin/login', {
@@ -79,8 +92,8 @@ class ApiService {
   }
 
   // Main admin endpoints
-  async getTenants() {
-    return this.request('/api/admin/tenants');
+  async getTenants(page) {
+    return this.requestPage('/api/admin/tenants', page);
   }
 
   async createTenant(data) {
@@ -107,8 +120,8 @@ class ApiService {
     });
   }
 
-  async getCoffeeShops(tenantId) {
-    return this.request(`/api/admin/tenants/${tenantId}/shops`);
+  async getCoffeeShops(tenantId, page) {
+    return this.requestPage(`/api/admin/tenants/${tenantId}/shops`, page);
   }
 
   async createCoffeeShop(tenantId, data) {
@@ -143,8 +156,8 @@ class ApiService {
   }
 
   // Category management (main admin only)
-  async getAllCategories() {
-    return this.request('/api/admin/categories');
+  async getAllCategories(page) {
+    return this.requestPage('/api/admin/categories', page);
   }
 
   async createCategory(data) {
@@ -172,12 +185,12 @@ class ApiService {
   }
 
   // Shop admin endpoints
-  async getCategories() {
-    return this.request('/api/admin/categories');
+  async getCategories(page) {
+    return this.requestPage('/api/admin/categories', page);
   }
 
-  async getMenuItems() {
-    return this.request('/api/admin/menu');
+  async getMenuItems(page) {
+    return this.requestPage('/api/admin/menu', page);
   }
 
   async createMenuItem(data) {